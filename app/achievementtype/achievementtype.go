@@ -0,0 +1,239 @@
+package achievementtype
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FieldType: Jenis nilai yang diharapkan pada satu field details
+type FieldType string
+
+const (
+	FieldString     FieldType = "string"
+	FieldInteger    FieldType = "integer"
+	FieldNumber     FieldType = "number"
+	FieldDate       FieldType = "date"
+	FieldEnum       FieldType = "enum"
+	FieldStringList FieldType = "string_list"
+)
+
+// Format tanggal yang diterima pada field bertipe date
+const DateLayout = "2006-01-02"
+
+// Tingkat kompetisi dipakai bersama oleh statistik (details.competitionLevel) dan workflow
+var CompetitionLevels = []string{"international", "national", "regional", "local"}
+
+// Field: Satu field pada details beserta aturannya
+type Field struct {
+	Name     string    `json:"name"`
+	Label    string    `json:"label"`
+	Type     FieldType `json:"type"`
+	Required bool      `json:"required"`
+	Enum     []string  `json:"enum,omitempty"`
+	Min      *float64  `json:"min,omitempty"`
+}
+
+// Type: Definisi satu tipe prestasi, skema details-nya, dan syarat bukti saat submit
+type Type struct {
+	Code     string         `json:"code"`
+	Name     string         `json:"name"`
+	Fields   []Field        `json:"fields"`
+	Evidence []EvidenceRule `json:"evidence"`
+}
+
+// FieldError: Kesalahan validasi pada satu field, dikirim apa adanya ke frontend
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func minValue(v float64) *float64 { return &v }
+
+// DefaultTypes: Tipe prestasi bawaan beserta aturan bukti dari DefaultEvidence
+func DefaultTypes() []Type {
+	types := []Type{
+		{
+			Code: "competition", Name: "Kompetisi",
+			Fields: []Field{
+				{Name: "competitionName", Label: "Nama Kompetisi", Type: FieldString, Required: true},
+				{Name: "competitionLevel", Label: "Tingkat", Type: FieldEnum, Required: true, Enum: CompetitionLevels},
+				{Name: "rank", Label: "Peringkat", Type: FieldEnum, Required: true, Enum: []string{"first", "second", "third", "honorable_mention", "finalist", "participant"}},
+				{Name: "eventDate", Label: "Tanggal Pelaksanaan", Type: FieldDate, Required: true},
+				{Name: "organizer", Label: "Penyelenggara", Type: FieldString},
+				{Name: "location", Label: "Lokasi", Type: FieldString},
+				{Name: "teamSize", Label: "Jumlah Anggota Tim", Type: FieldInteger, Min: minValue(1)},
+			},
+		},
+		{
+			Code: "publication", Name: "Publikasi",
+			Fields: []Field{
+				{Name: "publicationType", Label: "Jenis Publikasi", Type: FieldEnum, Required: true, Enum: []string{"journal", "conference", "book"}},
+				{Name: "publicationTitle", Label: "Judul Publikasi", Type: FieldString, Required: true},
+				{Name: "authors", Label: "Penulis", Type: FieldStringList, Required: true},
+				{Name: "publishedDate", Label: "Tanggal Terbit", Type: FieldDate, Required: true},
+				{Name: "publisher", Label: "Penerbit", Type: FieldString},
+				{Name: "issn", Label: "ISSN/ISBN", Type: FieldString},
+				{Name: "doi", Label: "DOI", Type: FieldString},
+			},
+		},
+		{
+			Code: "organization", Name: "Organisasi",
+			Fields: []Field{
+				{Name: "organizationName", Label: "Nama Organisasi", Type: FieldString, Required: true},
+				{Name: "position", Label: "Jabatan", Type: FieldString, Required: true},
+				{Name: "periodStart", Label: "Mulai Menjabat", Type: FieldDate, Required: true},
+				{Name: "periodEnd", Label: "Selesai Menjabat", Type: FieldDate},
+			},
+		},
+		{
+			Code: "certification", Name: "Sertifikasi",
+			Fields: []Field{
+				{Name: "certificationName", Label: "Nama Sertifikasi", Type: FieldString, Required: true},
+				{Name: "issuedBy", Label: "Lembaga Penerbit", Type: FieldString, Required: true},
+				{Name: "issuedDate", Label: "Tanggal Terbit", Type: FieldDate, Required: true},
+				{Name: "certificationNumber", Label: "Nomor Sertifikat", Type: FieldString},
+				{Name: "validUntil", Label: "Berlaku Hingga", Type: FieldDate},
+			},
+		},
+		{
+			Code: "academic", Name: "Akademik",
+			Fields: []Field{
+				{Name: "programName", Label: "Nama Program/Kegiatan", Type: FieldString, Required: true},
+				{Name: "institution", Label: "Institusi", Type: FieldString, Required: true},
+				{Name: "startDate", Label: "Tanggal Mulai", Type: FieldDate, Required: true},
+				{Name: "endDate", Label: "Tanggal Selesai", Type: FieldDate},
+				{Name: "score", Label: "Nilai/Skor", Type: FieldNumber, Min: minValue(0)},
+			},
+		},
+		{
+			Code: "other", Name: "Lainnya",
+			Fields: []Field{
+				{Name: "eventDate", Label: "Tanggal", Type: FieldDate, Required: true},
+				{Name: "organizer", Label: "Penyelenggara", Type: FieldString},
+			},
+		},
+	}
+	evidence := DefaultEvidence()
+	for i := range types {
+		types[i].Evidence = evidence[types[i].Code]
+	}
+	return types
+}
+
+// Registry: Daftar tipe prestasi yang dikenal sistem
+type Registry struct {
+	types map[string]Type
+}
+
+func NewRegistry(types []Type) *Registry {
+	r := &Registry{types: make(map[string]Type, len(types))}
+	for _, t := range types {
+		r.types[t.Code] = t
+	}
+	return r
+}
+
+// List: Semua tipe, terurut berdasarkan kode
+func (r *Registry) List() []Type {
+	list := make([]Type, 0, len(r.types))
+	for _, t := range r.types {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+func (r *Registry) Get(code string) (Type, bool) {
+	t, ok := r.types[code]
+	return t, ok
+}
+
+// Validate: Cek details terhadap skema tipe. Field di luar skema dibiarkan (data tambahan).
+// Mengembalikan slice kosong jika valid.
+func (r *Registry) Validate(code string, details map[string]interface{}) []FieldError {
+	t, ok := r.types[code]
+	if !ok {
+		codes := make([]string, 0, len(r.types))
+		for _, known := range r.List() {
+			codes = append(codes, known.Code)
+		}
+		return []FieldError{{Field: "achievementType", Message: fmt.Sprintf("Tipe prestasi tidak dikenal. Pilihan: %s", strings.Join(codes, ", "))}}
+	}
+
+	errs := []FieldError{}
+	for _, f := range t.Fields {
+		path := "details." + f.Name
+		value, present := details[f.Name]
+		if !present || value == nil || value == "" {
+			if f.Required {
+				errs = append(errs, FieldError{Field: path, Message: fmt.Sprintf("%s wajib diisi", f.Label)})
+			}
+			continue
+		}
+		if msg := f.check(value); msg != "" {
+			errs = append(errs, FieldError{Field: path, Message: msg})
+		}
+	}
+	return errs
+}
+
+// check memvalidasi satu nilai (hasil decode JSON) terhadap tipe field
+func (f Field) check(value interface{}) string {
+	switch f.Type {
+	case FieldString:
+		if _, ok := value.(string); !ok {
+			return fmt.Sprintf("%s harus berupa teks", f.Label)
+		}
+
+	case FieldEnum:
+		s, ok := value.(string)
+		if !ok || !contains(f.Enum, s) {
+			return fmt.Sprintf("%s harus salah satu dari: %s", f.Label, strings.Join(f.Enum, ", "))
+		}
+
+	case FieldDate:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Sprintf("%s harus berupa tanggal (YYYY-MM-DD)", f.Label)
+		}
+		if _, err := time.Parse(DateLayout, s); err != nil {
+			if _, err := time.Parse(time.RFC3339, s); err != nil {
+				return fmt.Sprintf("%s harus berupa tanggal (YYYY-MM-DD)", f.Label)
+			}
+		}
+
+	case FieldNumber, FieldInteger:
+		n, ok := value.(float64)
+		if !ok {
+			return fmt.Sprintf("%s harus berupa angka", f.Label)
+		}
+		if f.Type == FieldInteger && n != math.Trunc(n) {
+			return fmt.Sprintf("%s harus berupa bilangan bulat", f.Label)
+		}
+		if f.Min != nil && n < *f.Min {
+			return fmt.Sprintf("%s minimal %v", f.Label, *f.Min)
+		}
+
+	case FieldStringList:
+		list, ok := value.([]interface{})
+		if !ok || len(list) == 0 {
+			return fmt.Sprintf("%s harus berupa daftar teks (minimal satu)", f.Label)
+		}
+		for _, item := range list {
+			if s, ok := item.(string); !ok || strings.TrimSpace(s) == "" {
+				return fmt.Sprintf("%s harus berupa daftar teks (minimal satu)", f.Label)
+			}
+		}
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s { return true }
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package achievementtype
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+)
+
+// EvidenceKind: Sumber bukti yang diperiksa sebuah aturan
+type EvidenceKind string
+
+const (
+	EvidenceAttachment EvidenceKind = "attachment" // Lampiran (opsional difilter kategori & tipe file)
+	EvidenceDetail     EvidenceKind = "detail"     // Nilai pada details (opsional harus cocok pola)
+)
+
+// Kelompok tipe file yang sering dipakai aturan bukti
+var (
+	DocumentOrImage = []string{"application/pdf", "image/*"}
+	ImageOnly       = []string{"image/*"}
+)
+
+// EvidenceRule: Satu syarat bukti pendukung yang wajib terpenuhi saat submit
+type EvidenceRule struct {
+	Code      string       `json:"code"`
+	Label     string       `json:"label"`
+	Kind      EvidenceKind `json:"kind"`
+	Category  string       `json:"category,omitempty"`   // attachment: kategori lampiran (kosong = kategori apa pun)
+	MimeTypes []string     `json:"mime_types,omitempty"` // attachment: tipe file yang diterima, "image/*" = semua gambar
+	MinCount  int          `json:"min_count,omitempty"`  // attachment: jumlah minimal (default 1)
+	Field     string       `json:"field,omitempty"`      // detail: nama field di details
+	Pattern   string       `json:"pattern,omitempty"`    // detail: regex yang harus cocok
+}
+
+// UnmetRequirement: Syarat bukti yang belum terpenuhi, dikirim ke frontend saat submit ditolak
+type UnmetRequirement struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Pola DOI: "10.xxxx/..." boleh diawali https://doi.org/
+const doiPattern = `^(https?://(dx\.)?doi\.org/)?10\.\d{4,9}/\S+$`
+
+// DefaultEvidence: Aturan bukti bawaan per kode tipe prestasi
+func DefaultEvidence() map[string][]EvidenceRule {
+	anyFile := EvidenceRule{Code: "supporting_file", Label: "Minimal satu bukti berupa PDF atau gambar", Kind: EvidenceAttachment, MimeTypes: DocumentOrImage}
+	certificate := EvidenceRule{Code: "certificate", Label: "Sertifikat/piagam (PDF atau gambar)", Kind: EvidenceAttachment, Category: model_mongo.AttachmentCertificate, MimeTypes: DocumentOrImage}
+	return map[string][]EvidenceRule{
+		"competition": {
+			certificate,
+			{Code: "photo", Label: "Foto kegiatan/penyerahan", Kind: EvidenceAttachment, Category: model_mongo.AttachmentPhoto, MimeTypes: ImageOnly},
+		},
+		"publication": {
+			{Code: "doi", Label: "Tautan DOI publikasi (details.doi)", Kind: EvidenceDetail, Field: "doi", Pattern: doiPattern},
+		},
+		"organization":  {anyFile},
+		"certification": {certificate},
+		"academic":      {anyFile},
+		"other":         {anyFile},
+	}
+}
+
+// CheckEvidence mengembalikan daftar syarat bukti tipe code yang belum terpenuhi (kosong = lengkap).
+// Tipe yang tidak dikenal atau tanpa aturan dianggap lengkap; validasi tipe dilakukan di Validate.
+func (r *Registry) CheckEvidence(code string, attachments []model_mongo.Attachment, details map[string]interface{}) []UnmetRequirement {
+	unmet := []UnmetRequirement{}
+	t, ok := r.types[code]
+	if !ok {
+		return unmet
+	}
+	for _, rule := range t.Evidence {
+		if msg := rule.check(attachments, details); msg != "" {
+			unmet = append(unmet, UnmetRequirement{Code: rule.Code, Message: msg})
+		}
+	}
+	return unmet
+}
+
+func (rule EvidenceRule) check(attachments []model_mongo.Attachment, details map[string]interface{}) string {
+	switch rule.Kind {
+	case EvidenceAttachment:
+		min := rule.MinCount
+		if min < 1 { min = 1 }
+		count := 0
+		for _, att := range attachments {
+			// Hanya lampiran yang benar-benar diunggah (punya file di storage) yang dihitung
+			if att.StorageKey == "" { continue }
+			if rule.Category != "" && att.Category != rule.Category { continue }
+			if len(rule.MimeTypes) > 0 && !matchesMime(rule.MimeTypes, att) { continue }
+			count++
+		}
+		if count < min {
+			return fmt.Sprintf("%s: dibutuhkan %d, ditemukan %d", rule.Label, min, count)
+		}
+
+	case EvidenceDetail:
+		value, _ := details[rule.Field].(string)
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return fmt.Sprintf("%s wajib diisi", rule.Label)
+		}
+		if rule.Pattern != "" && !regexp.MustCompile(rule.Pattern).MatchString(value) {
+			return fmt.Sprintf("%s tidak valid", rule.Label)
+		}
+	}
+	return ""
+}
+
+// extensionMime dipakai bila browser mengirim tipe generik (application/octet-stream) atau kosong
+var extensionMime = map[string]string{
+	".pdf": "application/pdf", ".jpg": "image/jpeg", ".jpeg": "image/jpeg", ".png": "image/png", ".webp": "image/webp",
+}
+
+func matchesMime(patterns []string, att model_mongo.Attachment) bool {
+	mime := strings.ToLower(strings.TrimSpace(strings.Split(att.FileType, ";")[0]))
+	if mime == "" || mime == "application/octet-stream" {
+		mime = extensionMime[strings.ToLower(path.Ext(att.FileName))]
+	}
+	for _, p := range patterns {
+		if p == mime || (strings.HasSuffix(p, "/*") && strings.HasPrefix(mime, strings.TrimSuffix(p, "*"))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/safrizal-hk/uas-gofiber/app/outbox"
+)
+
+// OutboxWorker menerapkan ulang event outbox yang belum berhasil (gagal saat request atau proses
+// berhenti sebelum sempat menerapkannya) sampai PostgreSQL dan MongoDB konvergen.
+type OutboxWorker struct {
+	Dispatcher *outbox.Dispatcher
+}
+
+func NewOutboxWorker(dispatcher *outbox.Dispatcher) *OutboxWorker {
+	return &OutboxWorker{Dispatcher: dispatcher}
+}
+
+// Start menjalankan dispatch segera lalu setiap interval sampai ctx dibatalkan. Dipanggil sebagai goroutine.
+func (w *OutboxWorker) Start(ctx context.Context, interval time.Duration) {
+	RunEvery(ctx, interval, func(ctx context.Context) error {
+		result, err := w.RunOnce(ctx)
+		if err != nil {
+			return fmt.Errorf("dispatch outbox gagal: %w", err)
+		}
+		if result.Processed > 0 || result.Failed > 0 {
+			log.Printf("dispatch outbox: %d event diterapkan, %d gagal (dicoba ulang)", result.Processed, result.Failed)
+		}
+		return nil
+	})
+}
+
+// RunOnce menerapkan semua event outbox yang jatuh tempo
+func (w *OutboxWorker) RunOnce(ctx context.Context) (outbox.Result, error) {
+	return w.Dispatcher.DispatchDue(ctx)
+}
@@ -0,0 +1,25 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunEvery menjalankan fn segera lalu setiap interval sampai ctx dibatalkan. Error dari fn hanya
+// dicatat di log; putaran berikutnya tetap berjalan. Dipanggil sebagai goroutine oleh Start tiap job.
+func RunEvery(ctx context.Context, interval time.Duration, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := fn(ctx); err != nil {
+			log.Print(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
+)
+
+// SLAEscalator mencari prestasi Submitted yang tertahan di tahap verifikasi aktif lebih lama dari
+// verification_sla_days (dihitung dari stage_entered_at) lalu mengeskalasinya ke verifikator
+// cadangan (sla_fallback_verifier_id, kosong = semua Admin). Setiap tahap hanya dieskalasi sekali;
+// pindah tahap atau submit ulang memulai hitungan SLA dari awal.
+type SLAEscalator struct {
+	EscalationRepo repo_postgre.EscalationRepository
+	SettingRepo    repo_postgre.SettingRepository
+	Now            func() time.Time
+}
+
+// EscalationResult: Ringkasan satu kali pengecekan SLA
+type EscalationResult struct {
+	SLADays   int `json:"sla_days"`
+	Overdue   int `json:"overdue"`
+	Escalated int `json:"escalated"`
+	Failed    int `json:"failed"`
+}
+
+func NewSLAEscalator(escalationRepo repo_postgre.EscalationRepository, settingRepo repo_postgre.SettingRepository) *SLAEscalator {
+	return &SLAEscalator{
+		EscalationRepo: escalationRepo,
+		SettingRepo:    settingRepo,
+		Now:            time.Now,
+	}
+}
+
+// Start menjalankan pengecekan segera lalu setiap interval sampai ctx dibatalkan. Dipanggil sebagai goroutine.
+func (e *SLAEscalator) Start(ctx context.Context, interval time.Duration) {
+	RunEvery(ctx, interval, func(ctx context.Context) error {
+		result, err := e.RunOnce(ctx)
+		if err != nil { return fmt.Errorf("eskalasi SLA gagal: %w", err) }
+		if result.Escalated > 0 || result.Failed > 0 {
+			log.Printf("eskalasi SLA: %d prestasi dieskalasi, %d gagal (SLA %d hari)", result.Escalated, result.Failed, result.SLADays)
+		}
+		return nil
+	})
+}
+
+// RunOnce mengeskalasi semua prestasi overdue yang belum dieskalasi pada tahap verifikasi saat ini
+func (e *SLAEscalator) RunOnce(ctx context.Context) (EscalationResult, error) {
+	days, err := e.SettingRepo.GetIntSetting(model_postgre.SettingVerificationSLADays, model_postgre.DefaultVerificationSLADays)
+	if err != nil { return EscalationResult{}, err }
+	if days < 1 { days = model_postgre.DefaultVerificationSLADays }
+	result := EscalationResult{SLADays: days}
+
+	fallback, err := e.fallbackVerifier()
+	if err != nil { return result, err }
+
+	cutoff := e.Now().AddDate(0, 0, -days)
+	overdue, err := e.EscalationRepo.GetOverdueReferences(cutoff, "")
+	if err != nil { return result, err }
+	result.Overdue = len(overdue)
+
+	for _, item := range overdue {
+		if ctx.Err() != nil { return result, ctx.Err() }
+		if item.EscalatedAt != nil && !item.EscalatedAt.Before(item.StageEnteredAt) { continue }
+
+		_, err := e.EscalationRepo.Escalate(item.ID, fallback, days, cutoff)
+		if errors.Is(err, sql.ErrNoRows) { continue } // Sudah diproses/dieskalasi sejak daftar diambil
+		if err != nil {
+			log.Printf("eskalasi prestasi %s gagal: %v", item.ID, err)
+			result.Failed++
+			continue
+		}
+		result.Escalated++
+	}
+	return result, nil
+}
+
+func (e *SLAEscalator) fallbackVerifier() (string, error) {
+	setting, err := e.SettingRepo.GetSetting(model_postgre.SettingSLAFallbackVerifierID)
+	if errors.Is(err, sql.ErrNoRows) { return "", nil }
+	if err != nil { return "", err }
+	return strings.TrimSpace(setting.Value), nil
+}
@@ -0,0 +1,127 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	repo_mongo "github.com/safrizal-hk/uas-gofiber/app/repository/mongo"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/storage"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TrashPurger menghapus permanen prestasi yang sudah melewati masa retensi trash:
+// file lampiran (prestasi & komentar) di Storage, dokumen, revisi & komentar di Mongo, lalu referensi di PostgreSQL.
+// Urutan ini membuat purge aman diulang bila gagal di tengah jalan (referensi PG dihapus terakhir).
+type TrashPurger struct {
+	PgRepo       repo_postgre.AchievementPGRepository
+	MongoRepo    repo_mongo.AchievementMongoRepository
+	RevisionRepo repo_mongo.AchievementRevisionRepository
+	CommentRepo  repo_mongo.AchievementCommentRepository
+	SettingRepo  repo_postgre.SettingRepository
+	Storage      storage.Storage
+	Now          func() time.Time
+}
+
+// PurgeResult: Ringkasan satu kali proses purge
+type PurgeResult struct {
+	RetentionDays int `json:"retention_days"`
+	Purged        int `json:"purged"`
+	Failed        int `json:"failed"`
+	FilesRemoved  int `json:"files_removed"`
+}
+
+func NewTrashPurger(pgRepo repo_postgre.AchievementPGRepository, mongoRepo repo_mongo.AchievementMongoRepository, revisionRepo repo_mongo.AchievementRevisionRepository, commentRepo repo_mongo.AchievementCommentRepository, settingRepo repo_postgre.SettingRepository, store storage.Storage) *TrashPurger {
+	return &TrashPurger{
+		PgRepo:       pgRepo,
+		MongoRepo:    mongoRepo,
+		RevisionRepo: revisionRepo,
+		CommentRepo:  commentRepo,
+		SettingRepo:  settingRepo,
+		Storage:      store,
+		Now:          time.Now,
+	}
+}
+
+// Start menjalankan purge segera lalu setiap interval sampai ctx dibatalkan. Dipanggil sebagai goroutine.
+func (p *TrashPurger) Start(ctx context.Context, interval time.Duration) {
+	RunEvery(ctx, interval, func(ctx context.Context) error {
+		result, err := p.RunOnce(ctx)
+		if err != nil { return fmt.Errorf("purge trash gagal: %w", err) }
+		if result.Purged > 0 || result.Failed > 0 {
+			log.Printf("purge trash: %d prestasi dihapus permanen, %d gagal, %d file dihapus", result.Purged, result.Failed, result.FilesRemoved)
+		}
+		return nil
+	})
+}
+
+// RunOnce memproses semua prestasi di trash yang dihapus lebih lama dari retensi
+func (p *TrashPurger) RunOnce(ctx context.Context) (PurgeResult, error) {
+	days, err := p.SettingRepo.GetIntSetting(model_postgre.SettingTrashRetentionDays, model_postgre.DefaultTrashRetentionDays)
+	if err != nil { return PurgeResult{}, err }
+	if days < 1 { days = model_postgre.DefaultTrashRetentionDays }
+	result := PurgeResult{RetentionDays: days}
+
+	cutoff := p.Now().AddDate(0, 0, -days)
+	refs, err := p.PgRepo.GetPurgeableReferences(cutoff)
+	if err != nil { return result, err }
+
+	for _, ref := range refs {
+		removed, err := p.purgeOne(ctx, ref)
+		result.FilesRemoved += removed
+		if err != nil {
+			log.Printf("purge prestasi %s gagal: %v", ref.ID, err)
+			result.Failed++
+			continue
+		}
+		result.Purged++
+	}
+	return result, nil
+}
+
+func (p *TrashPurger) purgeOne(ctx context.Context, ref model_postgre.AchievementReference) (int, error) {
+	removed := 0
+	mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err == nil {
+		docs, err := p.MongoRepo.GetDeletedDetailsByIDs(ctx, []primitive.ObjectID{mongoID})
+		if err != nil { return removed, err }
+		for _, doc := range docs {
+			n, err := p.removeFiles(ctx, doc.Attachments)
+			removed += n
+			if err != nil { return removed, err }
+		}
+		comments, err := p.CommentRepo.ListByAchievement(ctx, mongoID)
+		if err != nil { return removed, err }
+		for _, comment := range comments {
+			n, err := p.removeFiles(ctx, comment.Attachments)
+			removed += n
+			if err != nil { return removed, err }
+		}
+		if err := p.MongoRepo.DeleteByID(ctx, mongoID); err != nil { return removed, err }
+		if err := p.RevisionRepo.DeleteByAchievement(ctx, mongoID); err != nil { return removed, err }
+		if err := p.CommentRepo.DeleteByAchievement(ctx, mongoID); err != nil { return removed, err }
+	}
+	// ID Mongo rusak: tidak ada dokumen yang bisa dihapus, referensi tetap dibersihkan
+	_, err = p.PgRepo.PurgeReferences(ref.MongoAchievementID)
+	return removed, err
+}
+
+// removeFiles menghapus file lampiran dari storage. File yang sudah tidak ada diabaikan.
+func (p *TrashPurger) removeFiles(ctx context.Context, attachments []model_mongo.Attachment) (int, error) {
+	removed := 0
+	for _, att := range attachments {
+		key := att.ObjectKey()
+		if key == "" { continue }
+
+		err := p.Storage.Delete(ctx, key)
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) { continue }
+		if err != nil { return removed, err }
+		removed++
+	}
+	return removed, nil
+}
@@ -5,15 +5,15 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// AchievementInput: Payload dari Frontend
+// AchievementInput: Payload dari Frontend. Lampiran tidak diterima di sini; hanya lewat endpoint upload.
 type AchievementInput struct {
 	AchievementType string                 `json:"achievementType" validate:"required"`
 	Title           string                 `json:"title" validate:"required"`
 	Description     string                 `json:"description"`
 	Details         map[string]interface{} `json:"details"`
-	Attachments     []Attachment           `json:"attachments"`
 	Tags            []string               `json:"tags"`
-	Points          int                    `json:"points"`
+	TeamMembers     []TeamMember           `json:"teamMembers"` // Opsional: prestasi tim (hanya dipakai saat pembuatan)
+	Points          int                    `json:"-"` // Dihitung server dari aturan poin, tidak diterima dari client
 }
 
 // AchievementMongo: Dokumen di Database MongoDB
@@ -26,7 +26,9 @@ type AchievementMongo struct {
 	Details         map[string]interface{} `bson:"details" json:"details"`
 	Attachments     []Attachment           `bson:"attachments" json:"attachments"`
 	Tags            []string               `bson:"tags" json:"tags"`
+	TeamMembers     []TeamMember           `bson:"teamMembers,omitempty" json:"team_members,omitempty"`
 	Points          int                    `bson:"points" json:"points"`
+	PointsFrozenAt  *time.Time             `bson:"pointsFrozenAt,omitempty" json:"points_frozen_at,omitempty"` // Diisi saat verifikasi final; poin tidak dihitung ulang lagi
 	
 	CreatedAt       time.Time              `bson:"createdAt" json:"created_at"`
 	UpdatedAt       time.Time              `bson:"updatedAt" json:"updated_at"`
@@ -34,8 +36,53 @@ type AchievementMongo struct {
 }
 
 type Attachment struct {
+	ID         string    `bson:"id,omitempty" json:"id,omitempty"` // Hex ObjectID; kosong untuk lampiran lama
 	FileName   string    `bson:"fileName" json:"file_name"`
 	FileUrl    string    `bson:"fileUrl" json:"file_url"`
 	FileType   string    `bson:"fileType" json:"file_type"`
+	Category   string    `bson:"category,omitempty" json:"category,omitempty"`
+	StorageKey string    `bson:"storageKey,omitempty" json:"-"` // Key objek di storage; kosong untuk lampiran lama
 	UploadedAt time.Time `bson:"uploadedAt" json:"uploaded_at"`
-}
\ No newline at end of file
+}
+
+// ObjectKey: Key objek di storage. Hanya StorageKey yang ditulis server yang dipercaya, bukan FileUrl;
+// lampiran lama diberi StorageKey sekali oleh migrasi MongoDB.
+func (a Attachment) ObjectKey() string {
+	return a.StorageKey
+}
+
+// HasID: Setiap lampiran punya ID (lampiran lama diberi ID oleh migrasi MongoDB)
+func (a Attachment) HasID(id string) bool {
+	return a.ID != "" && a.ID == id
+}
+
+// Kategori lampiran, dipakai aturan bukti per tipe prestasi
+const (
+	AttachmentCertificate = "certificate"
+	AttachmentPhoto       = "photo"
+	AttachmentDocument    = "document"
+	AttachmentOther       = "other"
+)
+
+var AttachmentCategories = []string{AttachmentCertificate, AttachmentPhoto, AttachmentDocument, AttachmentOther}
+
+// TeamMember: Anggota prestasi tim. Role: captain | member
+type TeamMember struct {
+	StudentID string `bson:"studentId" json:"student_id"`
+	Role      string `bson:"role" json:"role"`
+}
+
+// Batas pencarian teks prestasi
+const (
+	MaxSearchQueryLength = 200
+	DefaultSearchLimit   = 20
+	MaxSearchLimit       = 100
+)
+
+// AchievementSyncState: Proyeksi minimal dokumen (termasuk yang di-soft delete) untuk rekonsiliasi
+type AchievementSyncState struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	StudentID string             `bson:"studentId"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	DeletedAt *time.Time         `bson:"deletedAt,omitempty"`
+}
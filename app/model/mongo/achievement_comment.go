@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Batas satu komentar
+const (
+	MaxCommentLength      = 2000
+	MaxCommentAttachments = 5
+)
+
+// AchievementComment: Satu pesan pada thread diskusi prestasi antara mahasiswa dan verifikator.
+// Balasan menunjuk komentar induk (ParentID); thread hanya satu tingkat.
+type AchievementComment struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	AchievementID primitive.ObjectID  `bson:"achievementId" json:"achievement_id"`
+	ParentID      *primitive.ObjectID `bson:"parentId,omitempty" json:"parent_id,omitempty"`
+	AuthorID      string              `bson:"authorId" json:"author_id"` // User ID penulis
+	AuthorName    string              `bson:"authorName" json:"author_name"`
+	AuthorRole    string              `bson:"authorRole" json:"author_role"`
+	Message       string              `bson:"message" json:"message"`
+	Attachments   []Attachment        `bson:"attachments,omitempty" json:"attachments,omitempty"`
+	CreatedAt     time.Time           `bson:"createdAt" json:"created_at"`
+}
+
+// CommentThread: Komentar utama beserta balasannya (urut waktu)
+type CommentThread struct {
+	AchievementComment `bson:",inline"`
+	Replies            []AchievementComment `json:"replies"`
+}
+
+// CommentInput: Payload komentar (JSON atau multipart form; file lampiran pada key "files")
+type CommentInput struct {
+	Message  string `json:"message" form:"message"`
+	ParentID string `json:"parent_id" form:"parent_id"`
+}
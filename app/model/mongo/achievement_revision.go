@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AchievementRevision: Snapshot konten prestasi yang tidak pernah diubah setelah disimpan.
+// Version dimulai dari 1 (konten saat dibuat) dan bertambah setiap update.
+type AchievementRevision struct {
+	ID              primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	AchievementID   primitive.ObjectID     `bson:"achievementId" json:"achievement_id"`
+	Version         int                    `bson:"version" json:"version"`
+	AchievementType string                 `bson:"achievementType" json:"achievement_type"`
+	Title           string                 `bson:"title" json:"title"`
+	Description     string                 `bson:"description" json:"description"`
+	Details         map[string]interface{} `bson:"details" json:"details"`
+	Tags            []string               `bson:"tags" json:"tags"`
+	Points          int                    `bson:"points" json:"points"`
+	EditedBy        string                 `bson:"editedBy" json:"edited_by"` // User ID pengubah
+	CreatedAt       time.Time              `bson:"createdAt" json:"created_at"`
+}
+
+// RevisionChange: Perbedaan satu field antara dua revisi. Field details ditulis per key (details.rank).
+type RevisionChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
@@ -0,0 +1,84 @@
+package model
+
+import "time"
+
+// AchievementView: Read model koleksi achievement_views. Satu dokumen per referensi PostgreSQL
+// (anggota tim memiliki view sendiri) berisi status dari PostgreSQL dan konten dari dokumen prestasi.
+// Diperbarui oleh proyektor setiap kali event outbox diterapkan dan bisa dibangun ulang penuh.
+type AchievementView struct {
+	ID                       string     `bson:"_id" json:"id"` // ID referensi PostgreSQL
+	MongoAchievementID       string     `bson:"mongoAchievementId" json:"mongo_achievement_id"`
+	StudentID                string     `bson:"studentId" json:"student_id"`
+	Status                   string     `bson:"status" json:"status"`
+	WorkflowCode             *string    `bson:"workflowCode,omitempty" json:"workflow_code"`
+	CurrentStage             *string    `bson:"currentStage,omitempty" json:"current_stage"`
+	SubmittedAt              *time.Time `bson:"submittedAt,omitempty" json:"submitted_at"`
+	VerifiedAt               *time.Time `bson:"verifiedAt,omitempty" json:"verified_at"`
+	VerifiedBy               *string    `bson:"verifiedBy,omitempty" json:"verified_by"`
+	RejectionNote            *string    `bson:"rejectionNote,omitempty" json:"rejection_note"`
+	RevisionCount            int        `bson:"revisionCount" json:"revision_count"`
+	RevisionNote             *string    `bson:"revisionNote,omitempty" json:"revision_note"`
+	TeamRole                 *string    `bson:"teamRole,omitempty" json:"team_role"`
+	CoMember                 bool       `bson:"coMember" json:"co_member"`
+	ParticipationConfirmedAt *time.Time `bson:"participationConfirmedAt,omitempty" json:"participation_confirmed_at"`
+	DeletedAt                *time.Time `bson:"deletedAt,omitempty" json:"deleted_at,omitempty"`
+	CreatedAt                time.Time  `bson:"createdAt" json:"created_at"`
+	UpdatedAt                time.Time  `bson:"updatedAt" json:"updated_at"`
+
+	// Dari dokumen prestasi. DetailMissing = dokumen tidak ditemukan (lihat rekonsiliasi).
+	Title            string                 `bson:"title" json:"title"`
+	AchievementType  string                 `bson:"achievementType" json:"achievementType"`
+	Description      string                 `bson:"description" json:"description"`
+	CompetitionLevel string                 `bson:"competitionLevel,omitempty" json:"competition_level,omitempty"`
+	Points           int                    `bson:"points" json:"points"`
+	Tags             []string               `bson:"tags" json:"tags"`
+	Details          map[string]interface{} `bson:"details" json:"details"`
+	Attachments      []Attachment           `bson:"attachments" json:"attachments"`
+	DetailMissing    bool                   `bson:"detailMissing" json:"detail_missing"`
+
+	ProjectedAt time.Time `bson:"projectedAt" json:"-"`
+}
+
+// AchievementViewHit: View hasil pencarian teks beserta skor relevansinya
+type AchievementViewHit struct {
+	AchievementView `bson:",inline"`
+	Score           float64 `bson:"score" json:"score"`
+}
+
+// Batas paginasi daftar prestasi
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// Kolom (nama parameter API) yang boleh dipakai untuk sort/rentang tanggal daftar prestasi
+var ListSortFields = []string{"created_at", "updated_at", "submitted_at", "verified_at"}
+
+// CursorSortFields: Kolom yang selalu terisi sehingga aman dipakai untuk paginasi cursor (keyset)
+var CursorSortFields = []string{"created_at", "updated_at"}
+
+// AchievementViewFilter: Filter & paginasi daftar/pencarian prestasi pada achievement_views.
+// Bagian scope diisi service dari policy, sisanya dari query string.
+type AchievementViewFilter struct {
+	All               bool     // Admin: tanpa batas mahasiswa
+	StudentIDs        []string // Mahasiswa yang datanya boleh dilihat penuh
+	PendingStudentIDs []string // Bimbingan delegator: hanya yang menunggu verifikasi
+	IncludeDrafts     bool     // Draft hanya terlihat oleh pemilik & Admin
+
+	Statuses        []string
+	StudentID       string
+	DateField       string // Salah satu ListSortFields
+	DateFrom        *time.Time
+	DateTo          *time.Time // Eksklusif
+	AchievementType string
+	Tags            []string // Harus memiliki semua tag
+	MinPoints       *int
+	MaxPoints       *int
+
+	Sort        string // Salah satu ListSortFields
+	Desc        bool
+	Limit       int
+	Offset      int
+	CursorValue *time.Time // Nilai kolom sort baris terakhir halaman sebelumnya
+	CursorID    string
+}
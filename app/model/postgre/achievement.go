@@ -5,13 +5,33 @@ import "time"
 type AchievementStatus string 
 
 const (
-	StatusDraft     AchievementStatus = "draft"
-	StatusSubmitted AchievementStatus = "submitted"
-	StatusVerified  AchievementStatus = "verified"
-	StatusRejected  AchievementStatus = "rejected"
-	StatusDeleted   AchievementStatus = "deleted"
+	StatusDraft             AchievementStatus = "draft"
+	StatusSubmitted         AchievementStatus = "submitted"
+	StatusRevisionRequested AchievementStatus = "revision_requested"
+	StatusVerified          AchievementStatus = "verified"
+	StatusRejected          AchievementStatus = "rejected"
+	StatusDeleted           AchievementStatus = "deleted"
 )
 
+// IsEditable: Konten prestasi hanya boleh diubah oleh mahasiswa pada status ini
+func (s AchievementStatus) IsEditable() bool {
+	return s == StatusDraft || s == StatusRejected || s == StatusRevisionRequested
+}
+
+// IsOverridable: Status tujuan/asal yang boleh dipakai override Admin. Deleted punya alur sendiri (trash/restore).
+func (s AchievementStatus) IsOverridable() bool {
+	switch s {
+	case StatusDraft, StatusSubmitted, StatusRevisionRequested, StatusVerified, StatusRejected:
+		return true
+	}
+	return false
+}
+
+// IsSubmittable: Status yang boleh (di)ajukan ke verifikasi. Rejected bersifat final.
+func (s AchievementStatus) IsSubmittable() bool {
+	return s == StatusDraft || s == StatusRevisionRequested
+}
+
 type AchievementReference struct {
 	ID                 string            `json:"id"`
 	StudentID          string            `json:"student_id"`
@@ -21,6 +41,104 @@ type AchievementReference struct {
 	VerifiedAt         *time.Time        `json:"verified_at"`
 	VerifiedBy         *string           `json:"verified_by"` 
 	RejectionNote      *string           `json:"rejection_note"`
+	RevisionCount      int               `json:"revision_count"`
+	RevisionNote       *string           `json:"revision_note"`
+	WorkflowCode       *string           `json:"workflow_code"`
+	CurrentStage       *string           `json:"current_stage"`
+	TeamRole           *string           `json:"team_role"`
+	CoMember           bool              `json:"co_member"`
+	ParticipationConfirmedAt *time.Time  `json:"participation_confirmed_at"`
+	DeletedAt          *time.Time        `json:"deleted_at,omitempty"`
+	EscalatedAt        *time.Time        `json:"escalated_at,omitempty"`
+	EscalatedTo        *string           `json:"escalated_to,omitempty"`
+	EscalatedStage     *string           `json:"escalated_stage,omitempty"`
+	StageEnteredAt     *time.Time        `json:"stage_entered_at,omitempty"`
 	CreatedAt          time.Time         `json:"created_at"`
 	UpdatedAt          time.Time         `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+// Peran anggota pada prestasi tim
+const (
+	TeamRoleCaptain = "captain"
+	TeamRoleMember  = "member"
+)
+
+// IsPendingConfirmation: Anggota tim tertaut yang belum mengonfirmasi partisipasi
+func (r AchievementReference) IsPendingConfirmation() bool {
+	return r.CoMember && r.ParticipationConfirmedAt == nil
+}
+
+// IsEscalated: Tahap verifikasi yang sedang berjalan sudah melewati SLA dan dieskalasi.
+// Eskalasi dari siklus submit sebelumnya (sebelum submit ulang) atau dari tahap verifikasi
+// yang sudah dilewati tidak dihitung.
+func (r AchievementReference) IsEscalated() bool {
+	if r.Status != StatusSubmitted || r.EscalatedAt == nil { return false }
+	enteredAt := r.StageEnteredAt
+	if enteredAt == nil { enteredAt = r.SubmittedAt }
+	if enteredAt == nil || r.EscalatedAt.Before(*enteredAt) { return false }
+	return stageCode(r.EscalatedStage) == stageCode(r.CurrentStage)
+}
+
+func stageCode(stage *string) string {
+	if stage == nil { return "" }
+	return *stage
+}
+
+// IsEscalatedTo: User boleh memproses tahap aktif karena eskalasi. Tanpa verifikator
+// cadangan (EscalatedTo kosong) eskalasi ditujukan ke semua Admin.
+func (r AchievementReference) IsEscalatedTo(profile UserProfile) bool {
+	if !r.IsEscalated() { return false }
+	if r.EscalatedTo == nil { return profile.Role == "Admin" }
+	return *r.EscalatedTo == profile.ID
+}
+
+// AchievementStatusHistory: Satu baris log perubahan status prestasi
+type AchievementStatusHistory struct {
+	ID               string             `json:"id"`
+	AchievementRefID string             `json:"achievement_ref_id"`
+	FromStatus       *AchievementStatus `json:"from_status"`
+	ToStatus         AchievementStatus  `json:"to_status"`
+	ActorID          *string            `json:"actor_id"`
+	ActorName        *string            `json:"actor_name,omitempty"`
+	Note             *string            `json:"note"`
+	IsOverride       bool               `json:"is_override"`
+	OnBehalfOf       *string            `json:"on_behalf_of,omitempty"`
+	OnBehalfOfName   *string            `json:"on_behalf_of_name,omitempty"`
+	CreatedAt        time.Time          `json:"created_at"`
+}
+
+// Panjang minimal alasan override agar justifikasi benar-benar diisi
+const MinOverrideReasonLength = 10
+
+// StatusOverrideRequest: Payload override status oleh Admin. Confirm wajib true bila prestasi
+// sedang Verified (perubahan memengaruhi statistik & laporan).
+type StatusOverrideRequest struct {
+	Status  AchievementStatus `json:"status"`
+	Reason  string            `json:"reason"`
+	Confirm bool              `json:"confirm"`
+}
+
+// Alasan kegagalan per item pada aksi massal (bulk verify/reject)
+const (
+	BulkReasonNotFound      = "not_found"
+	BulkReasonInvalidStatus = "invalid_status"
+	BulkReasonNotAdvisee    = "not_advisee"
+	BulkReasonWrongStage    = "wrong_stage"
+	BulkReasonError         = "error"
+)
+
+// BulkActionRequest: Payload untuk /achievements/bulk/verify dan /achievements/bulk/reject
+type BulkActionRequest struct {
+	IDs  []string `json:"ids"`
+	Note string   `json:"note"`
+}
+
+// BulkActionResult: Hasil pemrosesan satu ID pada aksi massal
+type BulkActionResult struct {
+	ID           string            `json:"id"`
+	Success      bool              `json:"success"`
+	NewStatus    AchievementStatus `json:"new_status,omitempty"`
+	CurrentStage *string           `json:"current_stage,omitempty"`
+	Reason       string            `json:"reason,omitempty"`
+	Message      string            `json:"message,omitempty"`
+}
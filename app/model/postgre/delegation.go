@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// Batas panjang satu delegasi verifikasi
+const MaxDelegationDays = 180
+
+// VerificationDelegation: Pelimpahan verifikasi dari Dosen Wali (delegator) ke dosen lain
+// (delegate) selama jendela waktu [StartsAt, EndsAt)
+type VerificationDelegation struct {
+	ID            string     `json:"id"`
+	DelegatorID   string     `json:"delegator_id"`
+	DelegatorName string     `json:"delegator_name"`
+	DelegateID    string     `json:"delegate_id"`
+	DelegateName  string     `json:"delegate_name"`
+	StartsAt      time.Time  `json:"starts_at"`
+	EndsAt        time.Time  `json:"ends_at"`
+	Reason        *string    `json:"reason"`
+	CreatedBy     *string    `json:"created_by"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// IsActive: Delegasi berlaku pada waktu at (belum dicabut dan di dalam jendela waktu)
+func (d VerificationDelegation) IsActive(at time.Time) bool {
+	return d.RevokedAt == nil && !at.Before(d.StartsAt) && at.Before(d.EndsAt)
+}
+
+// DelegationRequest: Payload pembuatan delegasi. delegator_id hanya dipakai Admin;
+// Dosen Wali selalu mendelegasikan bimbingannya sendiri.
+type DelegationRequest struct {
+	DelegatorID string    `json:"delegator_id"`
+	DelegateID  string    `json:"delegate_id"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Reason      string    `json:"reason"`
+}
+
+// DelegatedAdvisee: Mahasiswa yang dapat diverifikasi delegate atas nama dosen wali aslinya
+type DelegatedAdvisee struct {
+	StudentID       string `json:"student_id"`
+	DelegatorUserID string `json:"delegator_user_id"`
+	DelegationID    string `json:"delegation_id"`
+}
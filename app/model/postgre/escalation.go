@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// AchievementEscalation: Catatan satu eskalasi prestasi yang melewati SLA verifikasi
+type AchievementEscalation struct {
+	ID               string    `json:"id"`
+	AchievementRefID string    `json:"achievement_ref_id"`
+	AdvisorID        *string   `json:"advisor_id"`
+	EscalatedTo      *string   `json:"escalated_to"`
+	SubmittedAt      time.Time `json:"submitted_at"`
+	StageEnteredAt   time.Time `json:"stage_entered_at"`
+	SLADays          int       `json:"sla_days"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// OverdueAchievement: Prestasi Submitted yang menunggu verifikasi lebih lama dari SLA
+type OverdueAchievement struct {
+	ID                 string     `json:"id"`
+	StudentID          string     `json:"student_id"`
+	StudentName        string     `json:"student_name"`
+	MongoAchievementID string     `json:"mongo_achievement_id"`
+	CurrentStage       *string    `json:"current_stage"`
+	SubmittedAt        time.Time  `json:"submitted_at"`
+	StageEnteredAt     time.Time  `json:"stage_entered_at"`
+	OverdueDays        int        `json:"overdue_days"`
+	AdvisorID          *string    `json:"advisor_id"`
+	AdvisorName        *string    `json:"advisor_name"`
+	EscalatedAt        *time.Time `json:"escalated_at"`
+	EscalatedTo        *string    `json:"escalated_to"`
+}
+
+// AdvisorOverdue: Daftar prestasi overdue milik mahasiswa bimbingan satu Dosen Wali.
+// AdvisorID kosong untuk mahasiswa yang belum memiliki dosen wali.
+type AdvisorOverdue struct {
+	AdvisorID   *string              `json:"advisor_id"`
+	AdvisorName *string              `json:"advisor_name"`
+	Total       int                  `json:"total"`
+	Items       []OverdueAchievement `json:"items"`
+}
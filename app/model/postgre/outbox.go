@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Operasi outbox yang diterapkan ke MongoDB (aggregate = dokumen prestasi di Mongo). Setiap event
+// yang berhasil diterapkan juga memperbarui read model achievement_views dokumen tersebut.
+const (
+	OutboxCreateAchievement = "create_achievement" // Payload: dokumen lengkap (Extended JSON)
+	OutboxSoftDelete        = "soft_delete"
+	OutboxRestore           = "restore"
+	OutboxRefreshView       = "refresh_view" // Hanya memproyeksikan ulang read model achievement_views
+)
+
+// OutboxEvent: Niat penulisan ke MongoDB yang dicatat dalam transaksi PostgreSQL yang sama
+// dengan perubahan referensi, lalu diterapkan dispatcher sampai berhasil
+type OutboxEvent struct {
+	ID            int64      `json:"id"`
+	AggregateID   string     `json:"aggregate_id"`
+	Operation     string     `json:"operation"`
+	Payload       string     `json:"payload,omitempty"`
+	Attempts      int        `json:"attempts"`
+	LastError     *string    `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	ProcessedAt   *time.Time `json:"processed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
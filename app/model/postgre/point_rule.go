@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// PointRule: Satu baris aturan poin. Level/Rank nil berarti berlaku untuk semua nilai.
+// TeamPercent adalah persentase poin yang diterima tiap anggota bila prestasi diraih tim.
+type PointRule struct {
+	ID              string    `json:"id"`
+	AchievementType string    `json:"achievement_type"`
+	Level           *string   `json:"level"`
+	Rank            *string   `json:"rank"`
+	Points          int       `json:"points"`
+	TeamPercent     int       `json:"team_percent"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// PointRuleInput: Payload create/update aturan poin oleh Admin
+type PointRuleInput struct {
+	AchievementType string  `json:"achievement_type" validate:"required"`
+	Level           *string `json:"level"`
+	Rank            *string `json:"rank"`
+	Points          int     `json:"points"`
+	TeamPercent     *int    `json:"team_percent"`
+}
+
+// PointDelta: Perubahan poin satu prestasi hasil recalculate
+type PointDelta struct {
+	AchievementID string `json:"achievement_id"`
+	MongoID       string `json:"mongo_id"`
+	Title         string `json:"title"`
+	Status        string `json:"status"`
+	OldPoints     int    `json:"old_points"`
+	NewPoints     int    `json:"new_points"`
+	Delta         int    `json:"delta"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+// Jenis ketidaksesuaian antara achievement_references (PostgreSQL) dan dokumen prestasi (MongoDB)
+const (
+	DriftInvalidMongoID  = "invalid_mongo_id" // mongo_achievement_id kosong/bukan ObjectID
+	DriftMissingDocument = "missing_document" // Referensi menunjuk dokumen yang tidak ada
+	DriftOrphanDocument  = "orphan_document"  // Dokumen tanpa referensi
+	DriftDeletedMismatch = "deleted_mismatch" // Status deleted di PostgreSQL tidak sesuai deletedAt di Mongo
+)
+
+// Perbaikan yang diterapkan rekonsiliasi. PostgreSQL adalah sumber kebenaran status.
+const (
+	RepairTrashReference     = "trash_reference" // Referensi dipindah ke trash (dipurge sesuai retensi)
+	RepairDeleteDocument     = "delete_document" // Dokumen yatim dihapus permanen
+	RepairSoftDeleteDocument = "soft_delete_document"
+	RepairRestoreDocument    = "restore_document"
+)
+
+// DriftIssue: Satu temuan rekonsiliasi beserta perbaikan yang (akan) diterapkan
+type DriftIssue struct {
+	Type        string `json:"type"`
+	Action      string `json:"action"`
+	ReferenceID string `json:"reference_id,omitempty"`
+	MongoID     string `json:"mongo_id,omitempty"`
+	StudentID   string `json:"student_id,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Detail      string `json:"detail"`
+	Repaired    bool   `json:"repaired"`
+	Error       string `json:"error,omitempty"`
+}
+
+// ReconcileReport: Hasil satu kali rekonsiliasi. Skipped = data yang sedang berubah (event outbox
+// tertunda atau diubah dalam masa tenggang) sehingga belum dinilai.
+type ReconcileReport struct {
+	DryRun            bool           `json:"dry_run"`
+	StartedAt         time.Time      `json:"started_at"`
+	ScannedReferences int            `json:"scanned_references"`
+	ScannedDocuments  int            `json:"scanned_documents"`
+	Skipped           int            `json:"skipped"`
+	Summary           map[string]int `json:"summary"`
+	Repaired          int            `json:"repaired"`
+	Failed            int            `json:"failed"`
+	Issues            []DriftIssue   `json:"issues"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Key pengaturan sistem yang dikenal aplikasi
+const (
+	SettingTrashRetentionDays    = "trash_retention_days"     // Lama prestasi disimpan di trash sebelum dihapus permanen
+	SettingVerificationSLADays   = "verification_sla_days"    // Batas hari prestasi Submitted menunggu verifikasi sebelum dieskalasi
+	SettingSLAFallbackVerifierID = "sla_fallback_verifier_id" // User ID verifikator cadangan penerima eskalasi (kosong = semua Admin)
+)
+
+// Nilai bawaan bila pengaturan belum ada/tidak valid di database
+const (
+	DefaultTrashRetentionDays  = 30
+	DefaultVerificationSLADays = 14
+)
+
+// SystemSetting: Satu baris pengaturan key-value yang dapat diubah Admin
+type SystemSetting struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedBy *string   `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SettingUpdateRequest: Payload update pengaturan
+type SettingUpdateRequest struct {
+	Value string `json:"value"`
+}
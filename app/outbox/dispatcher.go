@@ -0,0 +1,163 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/readmodel"
+	repo_mongo "github.com/safrizal-hk/uas-gofiber/app/repository/mongo"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Jeda percobaan ulang: BaseBackoff * 2^attempts, dibatasi MaxBackoff. Event tidak pernah dibuang;
+// dispatcher terus mencoba sampai MongoDB menerima penulisan.
+const (
+	BaseBackoff = 5 * time.Second
+	MaxBackoff  = time.Hour
+)
+
+// Dispatcher menerapkan event outbox ke MongoDB lalu memperbarui read model dokumen tersebut.
+// Setiap operasi idempoten (insert dengan ID yang sudah ditentukan, soft delete/restore berupa
+// $set/$unset, proyeksi ulang view) sehingga aman diulang setelah crash.
+type Dispatcher struct {
+	Repo      repo_postgre.OutboxRepository
+	MongoRepo repo_mongo.AchievementMongoRepository
+	Views     *readmodel.Projector
+	Lease     time.Duration // Lama event dikunci satu dispatcher sebelum boleh diambil ulang
+	BatchSize int
+}
+
+// Result: Ringkasan satu kali DispatchDue
+type Result struct {
+	Processed int `json:"processed"`
+	Failed    int `json:"failed"`
+}
+
+func NewDispatcher(repo repo_postgre.OutboxRepository, mongoRepo repo_mongo.AchievementMongoRepository, views *readmodel.Projector) *Dispatcher {
+	return &Dispatcher{
+		Repo:      repo,
+		MongoRepo: mongoRepo,
+		Views:     views,
+		Lease:     time.Minute,
+		BatchSize: 100,
+	}
+}
+
+// NewCreateEvent menyiapkan event pembuatan dokumen. ID dokumen harus sudah diisi agar insert ulang
+// tidak menghasilkan dokumen ganda. Payload memakai Extended JSON supaya tipe BSON tetap utuh.
+func NewCreateEvent(doc *model_mongo.AchievementMongo) (*model_postgre.OutboxEvent, error) {
+	if doc.ID.IsZero() {
+		return nil, errors.New("ID dokumen prestasi belum ditentukan")
+	}
+	payload, err := bson.MarshalExtJSON(doc, true, false)
+	if err != nil { return nil, err }
+	return &model_postgre.OutboxEvent{
+		AggregateID: doc.ID.Hex(),
+		Operation:   model_postgre.OutboxCreateAchievement,
+		Payload:     string(payload),
+	}, nil
+}
+
+// Dispatch menerapkan semua event jatuh tempo milik satu dokumen secara berurutan (dipakai handler
+// setelah commit). synced = tidak ada lagi event tertunda untuk dokumen tersebut. Kegagalan dicatat
+// untuk dicoba ulang worker, lalu dikembalikan ke pemanggil.
+func (d *Dispatcher) Dispatch(ctx context.Context, aggregateID string) (bool, error) {
+	for {
+		if ctx.Err() != nil { return false, ctx.Err() }
+		events, err := d.Repo.ClaimDue(aggregateID, d.Lease, d.BatchSize)
+		if err != nil { return false, err }
+		if len(events) == 0 { break }
+		for _, event := range events {
+			if err := d.process(ctx, event); err != nil { return false, err }
+		}
+	}
+	pending, err := d.Repo.CountPending(aggregateID)
+	if err != nil { return false, err }
+	return pending == 0, nil
+}
+
+// Refresh mencatat event refresh_view untuk perubahan yang hanya menyentuh MongoDB (edit konten,
+// lampiran, poin) lalu langsung menerapkannya seperti Dispatch
+func (d *Dispatcher) Refresh(ctx context.Context, aggregateID string) (bool, error) {
+	if err := d.Repo.Enqueue(&model_postgre.OutboxEvent{AggregateID: aggregateID, Operation: model_postgre.OutboxRefreshView}); err != nil {
+		return false, err
+	}
+	return d.Dispatch(ctx, aggregateID)
+}
+
+// DispatchDue menerapkan event jatuh tempo semua dokumen sampai tidak ada lagi yang bisa diambil
+func (d *Dispatcher) DispatchDue(ctx context.Context) (Result, error) {
+	result := Result{}
+	for {
+		if ctx.Err() != nil { return result, ctx.Err() }
+		events, err := d.Repo.ClaimDue("", d.Lease, d.BatchSize)
+		if err != nil { return result, err }
+		if len(events) == 0 { return result, nil }
+		for _, event := range events {
+			if err := d.process(ctx, event); err != nil {
+				result.Failed++
+				continue
+			}
+			result.Processed++
+		}
+	}
+}
+
+// process menerapkan satu event beserta proyeksi read model-nya lalu menandainya selesai, atau
+// menjadwalkan percobaan ulang bila salah satunya gagal
+func (d *Dispatcher) process(ctx context.Context, event model_postgre.OutboxEvent) error {
+	err := d.apply(ctx, event)
+	if err == nil && d.Views != nil {
+		err = d.Views.Refresh(ctx, event.AggregateID)
+	}
+	if err != nil {
+		if errMark := d.Repo.MarkFailed(event.ID, err.Error(), Backoff(event.Attempts)); errMark != nil {
+			return fmt.Errorf("%v (gagal mencatat kegagalan outbox: %v)", err, errMark)
+		}
+		return err
+	}
+	return d.Repo.MarkProcessed(event.ID)
+}
+
+func (d *Dispatcher) apply(ctx context.Context, event model_postgre.OutboxEvent) error {
+	// Tidak ada penulisan ke dokumen; cukup proyeksi ulang di process (ID corrupt tetap diproyeksikan)
+	if event.Operation == model_postgre.OutboxRefreshView { return nil }
+
+	id, err := primitive.ObjectIDFromHex(event.AggregateID)
+	if err != nil { return fmt.Errorf("ID Mongo corrupt: %s", event.AggregateID) }
+
+	switch event.Operation {
+	case model_postgre.OutboxCreateAchievement:
+		var doc model_mongo.AchievementMongo
+		if err := bson.UnmarshalExtJSON([]byte(event.Payload), true, &doc); err != nil {
+			return fmt.Errorf("payload outbox tidak valid: %w", err)
+		}
+		doc.ID = id
+		_, err := d.MongoRepo.Create(ctx, &doc)
+		// Dokumen sudah ada = percobaan sebelumnya berhasil tetapi belum sempat ditandai selesai
+		if err != nil && !mongo.IsDuplicateKeyError(err) { return err }
+		return nil
+	case model_postgre.OutboxSoftDelete:
+		return d.MongoRepo.SoftDelete(ctx, id)
+	case model_postgre.OutboxRestore:
+		return d.MongoRepo.Restore(ctx, id)
+	}
+	return fmt.Errorf("operasi outbox tidak dikenal: %s", event.Operation)
+}
+
+// Backoff: Jeda sebelum percobaan berikutnya untuk event yang sudah dicoba attempts kali
+func Backoff(attempts int) time.Duration {
+	delay := BaseBackoff
+	for i := 0; i < attempts; i++ {
+		delay *= 2
+		if delay >= MaxBackoff { return MaxBackoff }
+	}
+	return delay
+}
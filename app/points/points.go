@@ -0,0 +1,74 @@
+package points
+
+import (
+	"strings"
+
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+)
+
+// Result: Hasil perhitungan poin beserta aturan yang dipakai
+type Result struct {
+	Points   int                      `json:"points"`
+	Rule     *model_postgre.PointRule `json:"rule,omitempty"`
+	TeamSize int                      `json:"team_size"`
+}
+
+// Calculate menghitung poin dari tipe, details.competitionLevel, details.rank dan details.teamSize.
+// Aturan paling spesifik menang (level+rank > level saja / rank saja > wildcard). Bila tidak ada
+// aturan yang cocok poin = 0. Untuk tim (teamSize > 1) poin dikali TeamPercent/100.
+func Calculate(rules []model_postgre.PointRule, achievementType string, details map[string]interface{}) Result {
+	level := detailString(details, "competitionLevel")
+	rank := detailString(details, "rank")
+	teamSize := detailInt(details, "teamSize")
+	if teamSize < 1 { teamSize = 1 }
+
+	var best *model_postgre.PointRule
+	bestScore := -1
+	for i := range rules {
+		rule := &rules[i]
+		if !strings.EqualFold(rule.AchievementType, achievementType) { continue }
+		score := 0
+		if rule.Level != nil {
+			if !strings.EqualFold(*rule.Level, level) { continue }
+			score += 2
+		}
+		if rule.Rank != nil {
+			if !strings.EqualFold(*rule.Rank, rank) { continue }
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = rule, score
+		}
+	}
+
+	result := Result{TeamSize: teamSize}
+	if best == nil {
+		return result
+	}
+	result.Rule = best
+	result.Points = best.Points
+	if teamSize > 1 {
+		result.Points = best.Points * best.TeamPercent / 100
+	}
+	return result
+}
+
+func detailString(details map[string]interface{}, key string) string {
+	s, _ := details[key].(string)
+	return s
+}
+
+// detailInt menerima angka hasil decode JSON (float64) maupun BSON (int32/int64)
+func detailInt(details map[string]interface{}, key string) int {
+	switch v := details[key].(type) {
+	case float64:
+		return int(v)
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+package policy
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+)
+
+// ScopeRepository: Lookup minimum yang dibutuhkan policy. Dipenuhi oleh
+// AchievementPGRepository maupun ReportPGRepository.
+type ScopeRepository interface {
+	FindStudentIdByUserID(userID string) (string, error)
+	FindLecturerIdByUserID(userID string) (string, error)
+	GetAdviseeStudentIDs(lecturerID string) ([]string, error)
+}
+
+// DelegationRepository: Sumber delegasi verifikasi aktif. Dipenuhi oleh AchievementPGRepository.
+type DelegationRepository interface {
+	GetDelegatedAdvisees(lecturerID string) ([]model_postgre.DelegatedAdvisee, error)
+}
+
+// Scope: Himpunan mahasiswa yang datanya boleh diakses oleh satu user
+type Scope struct {
+	Role       string
+	All        bool
+	StudentID  string   // Mahasiswa: ID dirinya sendiri
+	LecturerID string   // Dosen Wali: ID dosen
+	StudentIDs []string // Mahasiswa: [StudentID], Dosen Wali: daftar bimbingan (+ bimbingan delegator)
+	OnBehalfOf map[string]string // Dosen Wali: mahasiswa dari delegasi aktif -> user ID dosen wali aslinya
+}
+
+func (s *Scope) AllowsStudent(studentID string) bool {
+	if s.All { return true }
+	for _, id := range s.StudentIDs {
+		if id == studentID { return true }
+	}
+	return false
+}
+
+// DelegatorFor: User ID dosen wali asli bila studentID masuk scope lewat delegasi, kosong bila bimbingan sendiri
+func (s *Scope) DelegatorFor(studentID string) string {
+	return s.OnBehalfOf[studentID]
+}
+
+// Policy: Satu-satunya tempat aturan kepemilikan data prestasi. Dipakai bersama oleh
+// AchievementService, ReportService dan StudentService.
+type Policy struct {
+	Repo        ScopeRepository
+	Delegations DelegationRepository
+}
+
+func New(repo ScopeRepository) *Policy {
+	return &Policy{Repo: repo}
+}
+
+// WithDelegations: Scope Dosen Wali ikut mencakup mahasiswa bimbingan dosen yang sedang
+// mendelegasikan verifikasi kepadanya. Hanya dipakai pada alur prestasi (bukan laporan).
+func (p *Policy) WithDelegations(repo DelegationRepository) *Policy {
+	p.Delegations = repo
+	return p
+}
+
+// ScopeFor: Admin = semua, Mahasiswa = milik sendiri, Dosen Wali = mahasiswa bimbingan
+func (p *Policy) ScopeFor(profile model_postgre.UserProfile) (*Scope, *fiber.Error) {
+	scope := &Scope{Role: profile.Role}
+
+	switch profile.Role {
+	case "Admin":
+		scope.All = true
+
+	case "Mahasiswa":
+		studentID, err := p.Repo.FindStudentIdByUserID(profile.ID)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Kesalahan server saat mencari data mahasiswa.")
+		}
+		if studentID == "" {
+			return nil, fiber.NewError(fiber.StatusForbidden, "Akun tidak terhubung ke data Mahasiswa.")
+		}
+		scope.StudentID = studentID
+		scope.StudentIDs = []string{studentID}
+
+	case "Dosen Wali":
+		lecturerID, err := p.Repo.FindLecturerIdByUserID(profile.ID)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Kesalahan server saat mencari data dosen.")
+		}
+		if lecturerID == "" {
+			return nil, fiber.NewError(fiber.StatusForbidden, "Akun tidak terhubung ke data Dosen Wali.")
+		}
+		adviseeIDs, err := p.Repo.GetAdviseeStudentIDs(lecturerID)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, "Gagal mengambil daftar mahasiswa bimbingan.")
+		}
+		scope.LecturerID = lecturerID
+		scope.StudentIDs = adviseeIDs
+		if errPolicy := p.addDelegations(scope); errPolicy != nil {
+			return nil, errPolicy
+		}
+
+	default:
+		return nil, fiber.NewError(fiber.StatusForbidden, "Role tidak dikenal.")
+	}
+	return scope, nil
+}
+
+// addDelegations menambahkan bimbingan delegator aktif ke scope Dosen Wali. Bimbingan sendiri
+// tidak pernah ditandai sebagai delegasi.
+func (p *Policy) addDelegations(scope *Scope) *fiber.Error {
+	if p.Delegations == nil { return nil }
+	delegated, err := p.Delegations.GetDelegatedAdvisees(scope.LecturerID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "Gagal mengambil delegasi verifikasi.")
+	}
+	for _, d := range delegated {
+		if scope.AllowsStudent(d.StudentID) { continue }
+		if scope.OnBehalfOf == nil { scope.OnBehalfOf = map[string]string{} }
+		scope.OnBehalfOf[d.StudentID] = d.DelegatorUserID
+		scope.StudentIDs = append(scope.StudentIDs, d.StudentID)
+	}
+	return nil
+}
+
+// CanAccessStudent: Cek baca (detail, riwayat, laporan) terhadap data milik studentID
+func (p *Policy) CanAccessStudent(profile model_postgre.UserProfile, studentID string) *fiber.Error {
+	scope, errPolicy := p.ScopeFor(profile)
+	if errPolicy != nil { return errPolicy }
+	if !scope.AllowsStudent(studentID) {
+		return fiber.NewError(fiber.StatusForbidden, "Anda tidak berhak mengakses data prestasi mahasiswa ini.")
+	}
+	return nil
+}
+
+// RequireOwner: Cek tulis oleh mahasiswa pemilik (edit, submit, hapus, lampiran)
+func (p *Policy) RequireOwner(profile model_postgre.UserProfile, studentID string) *fiber.Error {
+	if profile.Role != "Mahasiswa" {
+		return fiber.NewError(fiber.StatusForbidden, "Akses ditolak. Hanya Mahasiswa pemilik prestasi.")
+	}
+	scope, errPolicy := p.ScopeFor(profile)
+	if errPolicy != nil { return errPolicy }
+	if scope.StudentID != studentID {
+		return fiber.NewError(fiber.StatusForbidden, "Bukan milik Anda")
+	}
+	return nil
+}
+
+// Respond: Bentuk respons error standar ({"message", "code"}) untuk hasil cek policy
+func Respond(c *fiber.Ctx, errPolicy *fiber.Error) error {
+	return c.Status(errPolicy.Code).JSON(fiber.Map{"message": errPolicy.Message, "code": strconv.Itoa(errPolicy.Code)})
+}
@@ -0,0 +1,141 @@
+package readmodel
+
+import (
+	"context"
+	"errors"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	repo_mongo "github.com/safrizal-hk/uas-gofiber/app/repository/mongo"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Projector membangun read model achievement_views dari referensi PostgreSQL (sumber status) dan
+// dokumen MongoDB (sumber konten). Proyeksi selalu dihitung ulang dari kedua sumber sehingga
+// aman diulang dan urutan pemanggilan tidak memengaruhi hasil akhir.
+type Projector struct {
+	PgRepo    repo_postgre.AchievementPGRepository
+	MongoRepo repo_mongo.AchievementMongoRepository
+	ViewRepo  repo_mongo.AchievementViewRepository
+}
+
+func NewProjector(pgRepo repo_postgre.AchievementPGRepository, mongoRepo repo_mongo.AchievementMongoRepository, viewRepo repo_mongo.AchievementViewRepository) *Projector {
+	return &Projector{PgRepo: pgRepo, MongoRepo: mongoRepo, ViewRepo: viewRepo}
+}
+
+// Refresh memproyeksikan ulang view semua referensi satu dokumen prestasi. Referensi yang sudah
+// di-purge ikut terhapus dari read model.
+func (p *Projector) Refresh(ctx context.Context, mongoID string) error {
+	refs, err := p.PgRepo.GetReferencesByMongoID(mongoID)
+	if err != nil { return err }
+	if len(refs) == 0 {
+		return p.ViewRepo.ReplaceForAchievement(ctx, mongoID, nil)
+	}
+
+	doc, err := p.loadDocument(ctx, mongoID)
+	if err != nil { return err }
+	views := make([]model_mongo.AchievementView, len(refs))
+	for i, ref := range refs {
+		views[i] = Project(ref, doc)
+	}
+	return p.ViewRepo.ReplaceForAchievement(ctx, mongoID, views)
+}
+
+// Rebuild membangun ulang seluruh read model dari nol. Mengembalikan jumlah view yang ditulis.
+func (p *Projector) Rebuild(ctx context.Context) (int, error) {
+	refs, err := p.PgRepo.GetAllReferencesWithDeleted()
+	if err != nil { return 0, err }
+
+	seen := map[string]bool{}
+	var ids []primitive.ObjectID
+	for _, ref := range refs {
+		oid, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+		if err != nil || seen[ref.MongoAchievementID] { continue }
+		seen[ref.MongoAchievementID] = true
+		ids = append(ids, oid)
+	}
+
+	docs := map[string]*model_mongo.AchievementMongo{}
+	if len(ids) > 0 {
+		active, err := p.MongoRepo.GetDetailsByIDs(ctx, ids)
+		if err != nil { return 0, err }
+		deleted, err := p.MongoRepo.GetDeletedDetailsByIDs(ctx, ids)
+		if err != nil { return 0, err }
+		for _, list := range [][]model_mongo.AchievementMongo{active, deleted} {
+			for i := range list {
+				docs[list[i].ID.Hex()] = &list[i]
+			}
+		}
+	}
+
+	views := make([]model_mongo.AchievementView, len(refs))
+	for i, ref := range refs {
+		views[i] = Project(ref, docs[ref.MongoAchievementID])
+	}
+	if err := p.ViewRepo.ReplaceAll(ctx, views); err != nil { return 0, err }
+	return len(views), nil
+}
+
+// RebuildIfEmpty membangun read model hanya bila koleksinya masih kosong, mis. saat pertama kali
+// dijalankan pada data yang sudah ada. Mengembalikan 0 bila read model sudah terisi.
+func (p *Projector) RebuildIfEmpty(ctx context.Context) (int, error) {
+	empty, err := p.ViewRepo.IsEmpty(ctx)
+	if err != nil || !empty { return 0, err }
+	return p.Rebuild(ctx)
+}
+
+// loadDocument: Dokumen aktif maupun di trash. nil tanpa error = ID corrupt atau dokumen tidak ada.
+func (p *Projector) loadDocument(ctx context.Context, mongoID string) (*model_mongo.AchievementMongo, error) {
+	oid, err := primitive.ObjectIDFromHex(mongoID)
+	if err != nil { return nil, nil }
+
+	doc, err := p.MongoRepo.GetDetailByID(ctx, oid)
+	if err == nil { return doc, nil }
+	if !errors.Is(err, mongo.ErrNoDocuments) { return nil, err }
+
+	deleted, err := p.MongoRepo.GetDeletedDetailsByIDs(ctx, []primitive.ObjectID{oid})
+	if err != nil { return nil, err }
+	if len(deleted) == 0 { return nil, nil }
+	return &deleted[0], nil
+}
+
+// Project menggabungkan satu referensi dengan dokumennya. doc nil = DetailMissing (drift, lihat rekonsiliasi).
+func Project(ref model_postgre.AchievementReference, doc *model_mongo.AchievementMongo) model_mongo.AchievementView {
+	view := model_mongo.AchievementView{
+		ID:                       ref.ID,
+		MongoAchievementID:       ref.MongoAchievementID,
+		StudentID:                ref.StudentID,
+		Status:                   string(ref.Status),
+		WorkflowCode:             ref.WorkflowCode,
+		CurrentStage:             ref.CurrentStage,
+		SubmittedAt:              ref.SubmittedAt,
+		VerifiedAt:               ref.VerifiedAt,
+		VerifiedBy:               ref.VerifiedBy,
+		RejectionNote:            ref.RejectionNote,
+		RevisionCount:            ref.RevisionCount,
+		RevisionNote:             ref.RevisionNote,
+		TeamRole:                 ref.TeamRole,
+		CoMember:                 ref.CoMember,
+		ParticipationConfirmedAt: ref.ParticipationConfirmedAt,
+		DeletedAt:                ref.DeletedAt,
+		CreatedAt:                ref.CreatedAt,
+		UpdatedAt:                ref.UpdatedAt,
+		Tags:                     []string{},
+		Attachments:              []model_mongo.Attachment{},
+	}
+	if doc == nil {
+		view.DetailMissing = true
+		return view
+	}
+	view.Title = doc.Title
+	view.AchievementType = doc.AchievementType
+	view.Description = doc.Description
+	view.Points = doc.Points
+	view.Details = doc.Details
+	if doc.Tags != nil { view.Tags = doc.Tags }
+	if doc.Attachments != nil { view.Attachments = doc.Attachments }
+	if level, ok := doc.Details["competitionLevel"].(string); ok { view.CompetitionLevel = level }
+	return view
+}
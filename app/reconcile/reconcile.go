@@ -0,0 +1,155 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	repo_mongo "github.com/safrizal-hk/uas-gofiber/app/repository/mongo"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reconciler membandingkan achievement_references dengan koleksi achievements lalu (opsional)
+// memperbaiki ketidaksesuaian. PostgreSQL menjadi sumber kebenaran status; dokumen Mongo
+// mengikuti. Dokumen dibaca lebih dulu daripada referensi karena dokumen baru hanya ditulis
+// setelah referensinya commit (outbox), sehingga dokumen baru tidak pernah tampak yatim.
+type Reconciler struct {
+	PgRepo     repo_postgre.AchievementPGRepository
+	MongoRepo  repo_mongo.AchievementMongoRepository
+	OutboxRepo repo_postgre.OutboxRepository
+	Grace      time.Duration // Data yang berubah dalam rentang ini sebelum pemindaian dianggap sedang diproses
+	Now        func() time.Time
+}
+
+func New(pgRepo repo_postgre.AchievementPGRepository, mongoRepo repo_mongo.AchievementMongoRepository, outboxRepo repo_postgre.OutboxRepository) *Reconciler {
+	return &Reconciler{
+		PgRepo:     pgRepo,
+		MongoRepo:  mongoRepo,
+		OutboxRepo: outboxRepo,
+		Grace:      5 * time.Minute,
+		Now:        time.Now,
+	}
+}
+
+// Run memindai kedua database. dryRun = hanya laporan; selain itu setiap temuan langsung diperbaiki.
+// actorID dicatat di riwayat status untuk referensi yang dipindah ke trash (kosong untuk CLI).
+func (r *Reconciler) Run(ctx context.Context, dryRun bool, actorID string) (*model_postgre.ReconcileReport, error) {
+	report := &model_postgre.ReconcileReport{DryRun: dryRun, StartedAt: r.Now(), Summary: map[string]int{}, Issues: []model_postgre.DriftIssue{}}
+	settled := report.StartedAt.Add(-r.Grace)
+
+	docs, err := r.MongoRepo.ListSyncStates(ctx)
+	if err != nil { return nil, fmt.Errorf("gagal membaca dokumen MongoDB: %w", err) }
+	refs, err := r.PgRepo.GetAllReferencesWithDeleted()
+	if err != nil { return nil, fmt.Errorf("gagal membaca referensi PostgreSQL: %w", err) }
+	pendingIDs, err := r.OutboxRepo.PendingAggregateIDs()
+	if err != nil { return nil, fmt.Errorf("gagal membaca outbox: %w", err) }
+	report.ScannedDocuments, report.ScannedReferences = len(docs), len(refs)
+
+	pending := make(map[string]bool, len(pendingIDs))
+	for _, id := range pendingIDs { pending[id] = true }
+	docByID := make(map[string]model_mongo.AchievementSyncState, len(docs))
+	for _, doc := range docs { docByID[doc.ID.Hex()] = doc }
+
+	// Referensi tim berbagi satu dokumen; referensi pembuat (co_member = false) mewakili grupnya
+	groups := map[string][]model_postgre.AchievementReference{}
+	var order []string
+	for _, ref := range refs {
+		if _, ok := groups[ref.MongoAchievementID]; !ok { order = append(order, ref.MongoAchievementID) }
+		groups[ref.MongoAchievementID] = append(groups[ref.MongoAchievementID], ref)
+	}
+
+	for _, mongoID := range order {
+		if ctx.Err() != nil { return report, ctx.Err() }
+		ref := primaryReference(groups[mongoID])
+		issue := model_postgre.DriftIssue{MongoID: mongoID, ReferenceID: ref.ID, StudentID: ref.StudentID, Status: string(ref.Status)}
+		refDeleted := ref.Status == model_postgre.StatusDeleted
+
+		if _, err := primitive.ObjectIDFromHex(mongoID); err != nil {
+			if refDeleted { continue } // Purge trash tetap membersihkan referensi dengan ID rusak
+			issue.Type, issue.Action = model_postgre.DriftInvalidMongoID, model_postgre.RepairTrashReference
+			issue.Detail = "mongo_achievement_id kosong atau bukan ObjectID yang valid"
+			r.record(ctx, report, issue, ref, nil, actorID)
+			continue
+		}
+		if pending[mongoID] || ref.UpdatedAt.After(settled) {
+			report.Skipped++
+			continue
+		}
+		doc, exists := docByID[mongoID]
+
+		switch {
+		case !exists:
+			if refDeleted { continue } // Sudah di trash; purge trash tidak membutuhkan dokumen
+			issue.Type, issue.Action = model_postgre.DriftMissingDocument, model_postgre.RepairTrashReference
+			issue.Detail = "dokumen prestasi tidak ditemukan di MongoDB"
+		case refDeleted && doc.DeletedAt == nil:
+			issue.Type, issue.Action = model_postgre.DriftDeletedMismatch, model_postgre.RepairSoftDeleteDocument
+			issue.Detail = "referensi di trash tetapi dokumen masih aktif"
+		case !refDeleted && doc.DeletedAt != nil:
+			issue.Type, issue.Action = model_postgre.DriftDeletedMismatch, model_postgre.RepairRestoreDocument
+			issue.Detail = fmt.Sprintf("referensi berstatus %s tetapi dokumen di-soft delete", ref.Status)
+		default:
+			continue
+		}
+		r.record(ctx, report, issue, ref, &doc, actorID)
+	}
+
+	for _, doc := range docs {
+		if ctx.Err() != nil { return report, ctx.Err() }
+		mongoID := doc.ID.Hex()
+		if _, ok := groups[mongoID]; ok { continue }
+		if pending[mongoID] || doc.CreatedAt.After(settled) {
+			report.Skipped++
+			continue
+		}
+		r.record(ctx, report, model_postgre.DriftIssue{
+			Type: model_postgre.DriftOrphanDocument, Action: model_postgre.RepairDeleteDocument,
+			MongoID: mongoID, StudentID: doc.StudentID,
+			Detail: "dokumen prestasi tanpa referensi di PostgreSQL",
+		}, model_postgre.AchievementReference{}, &doc, actorID)
+	}
+	return report, nil
+}
+
+// record menambahkan temuan ke laporan dan menerapkan perbaikannya bila bukan dry run
+func (r *Reconciler) record(ctx context.Context, report *model_postgre.ReconcileReport, issue model_postgre.DriftIssue, ref model_postgre.AchievementReference, doc *model_mongo.AchievementSyncState, actorID string) {
+	report.Summary[issue.Type]++
+	if !report.DryRun {
+		if err := r.repair(ctx, issue, ref, doc, actorID); err != nil {
+			issue.Error = err.Error()
+			report.Failed++
+		} else {
+			issue.Repaired = true
+			report.Repaired++
+		}
+	}
+	report.Issues = append(report.Issues, issue)
+}
+
+func (r *Reconciler) repair(ctx context.Context, issue model_postgre.DriftIssue, ref model_postgre.AchievementReference, doc *model_mongo.AchievementSyncState, actorID string) error {
+	switch issue.Action {
+	case model_postgre.RepairTrashReference:
+		_, err := r.PgRepo.TrashReference(ref.ID, actorID, "Rekonsiliasi: "+issue.Detail)
+		return err
+	case model_postgre.RepairDeleteDocument:
+		return r.MongoRepo.DeleteByID(ctx, doc.ID)
+	case model_postgre.RepairSoftDeleteDocument:
+		return r.MongoRepo.SoftDelete(ctx, doc.ID)
+	case model_postgre.RepairRestoreDocument:
+		return r.MongoRepo.Restore(ctx, doc.ID)
+	}
+	return fmt.Errorf("perbaikan tidak dikenal: %s", issue.Action)
+}
+
+// primaryReference: Referensi pembuat bila ada, selain itu referensi yang paling akhir diubah
+func primaryReference(refs []model_postgre.AchievementReference) model_postgre.AchievementReference {
+	sort.SliceStable(refs, func(i, j int) bool {
+		if refs[i].CoMember != refs[j].CoMember { return !refs[i].CoMember }
+		return refs[i].UpdatedAt.After(refs[j].UpdatedAt)
+	})
+	return refs[0]
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AchievementCommentRepository interface {
+	Create(ctx context.Context, comment *model_mongo.AchievementComment) (*model_mongo.AchievementComment, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementComment, error)
+	ListByAchievement(ctx context.Context, achievementID primitive.ObjectID) ([]model_mongo.AchievementComment, error)
+	DeleteByAchievement(ctx context.Context, achievementID primitive.ObjectID) error // Hanya untuk purge permanen
+}
+
+type achievementCommentRepositoryImpl struct {
+	Collection *mongo.Collection
+}
+
+func NewAchievementCommentRepository(db *mongo.Database) AchievementCommentRepository {
+	return &achievementCommentRepositoryImpl{
+		Collection: db.Collection("achievement_comments"),
+	}
+}
+
+// Create menyimpan komentar baru. ID yang sudah diisi caller dipakai apa adanya (URL lampiran
+// komentar memuat ID komentar sehingga dibuat sebelum lampiran disimpan).
+func (r *achievementCommentRepositoryImpl) Create(ctx context.Context, comment *model_mongo.AchievementComment) (*model_mongo.AchievementComment, error) {
+	if comment.ID.IsZero() { comment.ID = primitive.NewObjectID() }
+	comment.CreatedAt = time.Now()
+	if _, err := r.Collection.InsertOne(ctx, comment); err != nil { return nil, err }
+	return comment, nil
+}
+
+func (r *achievementCommentRepositoryImpl) GetByID(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementComment, error) {
+	var comment model_mongo.AchievementComment
+	if err := r.Collection.FindOne(ctx, bson.M{"_id": id}).Decode(&comment); err != nil { return nil, err }
+	return &comment, nil
+}
+
+// ListByAchievement: Semua komentar (utama & balasan) urut waktu dibuat
+func (r *achievementCommentRepositoryImpl) ListByAchievement(ctx context.Context, achievementID primitive.ObjectID) ([]model_mongo.AchievementComment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.Collection.Find(ctx, bson.M{"achievementId": achievementID}, opts)
+	if err != nil { return nil, err }
+	defer cursor.Close(ctx)
+
+	comments := []model_mongo.AchievementComment{}
+	if err = cursor.All(ctx, &comments); err != nil { return nil, err }
+	return comments, nil
+}
+
+func (r *achievementCommentRepositoryImpl) DeleteByAchievement(ctx context.Context, achievementID primitive.ObjectID) error {
+	_, err := r.Collection.DeleteMany(ctx, bson.M{"achievementId": achievementID})
+	return err
+}
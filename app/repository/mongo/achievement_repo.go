@@ -8,6 +8,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type AchievementMongoRepository interface {
@@ -16,8 +17,16 @@ type AchievementMongoRepository interface {
 	GetDetailsByIDs(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error)
 	Update(ctx context.Context, id primitive.ObjectID, data *model_mongo.AchievementInput) error
 	SoftDelete(ctx context.Context, id primitive.ObjectID) error
+	Restore(ctx context.Context, id primitive.ObjectID) error
+	GetDeletedDetailsByIDs(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error)
 	DeleteByID(ctx context.Context, id primitive.ObjectID) error // Hard Delete (Rollback)
+	ListSyncStates(ctx context.Context) ([]model_mongo.AchievementSyncState, error)
 	AddAttachment(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) error
+	RemoveAttachment(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) (bool, error)
+	ReplaceAttachment(ctx context.Context, id primitive.ObjectID, old model_mongo.Attachment, replacement model_mongo.Attachment) (bool, error)
+	UpdatePoints(ctx context.Context, id primitive.ObjectID, points int) (bool, error)
+	FreezePoints(ctx context.Context, id primitive.ObjectID, points int) error
+	UnfreezePoints(ctx context.Context, id primitive.ObjectID) error
 }
 
 type achievementMongoRepositoryImpl struct {
@@ -81,11 +90,42 @@ func (r *achievementMongoRepositoryImpl) SoftDelete(ctx context.Context, id prim
 	return err
 }
 
+// Restore: Kebalikan SoftDelete
+func (r *achievementMongoRepositoryImpl) Restore(ctx context.Context, id primitive.ObjectID) error {
+	update := bson.M{"$unset": bson.M{"deletedAt": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	_, err := r.Collection.UpdateByID(ctx, id, update)
+	return err
+}
+
+// GetDeletedDetailsByIDs: Seperti GetDetailsByIDs tetapi hanya dokumen yang sudah di-soft delete (trash)
+func (r *achievementMongoRepositoryImpl) GetDeletedDetailsByIDs(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error) {
+	filter := bson.M{"_id": bson.M{"$in": ids}, "deletedAt": bson.M{"$ne": nil}}
+	cursor, err := r.Collection.Find(ctx, filter)
+	if err != nil { return nil, err }
+	defer cursor.Close(ctx)
+
+	achievements := []model_mongo.AchievementMongo{}
+	if err = cursor.All(ctx, &achievements); err != nil { return nil, err }
+	return achievements, nil
+}
+
 func (r *achievementMongoRepositoryImpl) DeleteByID(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.Collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
 
+// ListSyncStates: Semua dokumen (termasuk trash) dengan field yang dibutuhkan rekonsiliasi saja
+func (r *achievementMongoRepositoryImpl) ListSyncStates(ctx context.Context) ([]model_mongo.AchievementSyncState, error) {
+	projection := bson.M{"_id": 1, "studentId": 1, "createdAt": 1, "deletedAt": 1}
+	cursor, err := r.Collection.Find(ctx, bson.M{}, options.Find().SetProjection(projection))
+	if err != nil { return nil, err }
+	defer cursor.Close(ctx)
+
+	states := []model_mongo.AchievementSyncState{}
+	if err := cursor.All(ctx, &states); err != nil { return nil, err }
+	return states, nil
+}
+
 func (r *achievementMongoRepositoryImpl) AddAttachment(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) error {
 	update := bson.M{
 		"$push": bson.M{"attachments": attachment},
@@ -93,4 +133,56 @@ func (r *achievementMongoRepositoryImpl) AddAttachment(ctx context.Context, id p
 	}
 	_, err := r.Collection.UpdateByID(ctx, id, update)
 	return err
-}
\ No newline at end of file
+}
+
+// attachmentMatch: Lampiran dicocokkan dengan ID-nya (lampiran lama diberi ID oleh migrasi)
+func attachmentMatch(attachment model_mongo.Attachment) bson.M {
+	return bson.M{"id": attachment.ID}
+}
+
+// RemoveAttachment: $pull satu lampiran. return false bila dokumen/lampiran tidak ditemukan.
+func (r *achievementMongoRepositoryImpl) RemoveAttachment(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) (bool, error) {
+	filter := bson.M{"_id": id, "deletedAt": nil, "attachments": bson.M{"$elemMatch": attachmentMatch(attachment)}}
+	update := bson.M{
+		"$pull": bson.M{"attachments": attachmentMatch(attachment)},
+		"$set":  bson.M{"updatedAt": time.Now()},
+	}
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil { return false, err }
+	return result.ModifiedCount > 0, nil
+}
+
+// ReplaceAttachment: Ganti lampiran di posisinya (operator positional $) agar urutan tetap
+func (r *achievementMongoRepositoryImpl) ReplaceAttachment(ctx context.Context, id primitive.ObjectID, old model_mongo.Attachment, replacement model_mongo.Attachment) (bool, error) {
+	filter := bson.M{"_id": id, "deletedAt": nil, "attachments": bson.M{"$elemMatch": attachmentMatch(old)}}
+	update := bson.M{"$set": bson.M{"attachments.$": replacement, "updatedAt": time.Now()}}
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil { return false, err }
+	return result.MatchedCount > 0, nil
+}
+
+// UpdatePoints: Set poin hasil hitung ulang. Dokumen yang poinnya sudah dibekukan tidak disentuh;
+// return false bila tidak ada dokumen yang berubah.
+func (r *achievementMongoRepositoryImpl) UpdatePoints(ctx context.Context, id primitive.ObjectID, points int) (bool, error) {
+	filter := bson.M{"_id": id, "pointsFrozenAt": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"points": points, "updatedAt": time.Now()}}
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil { return false, err }
+	return result.ModifiedCount > 0, nil
+}
+
+// FreezePoints: Set poin final saat prestasi terverifikasi
+func (r *achievementMongoRepositoryImpl) FreezePoints(ctx context.Context, id primitive.ObjectID, points int) error {
+	now := time.Now()
+	update := bson.M{"$set": bson.M{"points": points, "pointsFrozenAt": now, "updatedAt": now}}
+	_, err := r.Collection.UpdateByID(ctx, id, update)
+	return err
+}
+
+// UnfreezePoints: Dipakai saat prestasi verified dikeluarkan dari status verified (override Admin),
+// sehingga poinnya kembali ikut hitung ulang
+func (r *achievementMongoRepositoryImpl) UnfreezePoints(ctx context.Context, id primitive.ObjectID) error {
+	update := bson.M{"$unset": bson.M{"pointsFrozenAt": ""}, "$set": bson.M{"updatedAt": time.Now()}}
+	_, err := r.Collection.UpdateByID(ctx, id, update)
+	return err
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Percobaan ulang bila dua update bersamaan mendapat nomor versi yang sama (unique index achievementId+version)
+const maxRevisionInsertAttempts = 3
+
+type AchievementRevisionRepository interface {
+	Create(ctx context.Context, revision *model_mongo.AchievementRevision) (*model_mongo.AchievementRevision, error)
+	ListByAchievement(ctx context.Context, achievementID primitive.ObjectID) ([]model_mongo.AchievementRevision, error)
+	GetByVersion(ctx context.Context, achievementID primitive.ObjectID, version int) (*model_mongo.AchievementRevision, error)
+	LatestVersion(ctx context.Context, achievementID primitive.ObjectID) (int, error)
+	DeleteByAchievement(ctx context.Context, achievementID primitive.ObjectID) error // Hanya untuk purge permanen
+}
+
+type achievementRevisionRepositoryImpl struct {
+	Collection *mongo.Collection
+}
+
+func NewAchievementRevisionRepository(db *mongo.Database) AchievementRevisionRepository {
+	return &achievementRevisionRepositoryImpl{
+		Collection: db.Collection("achievement_revisions"),
+	}
+}
+
+// Create: Nomor versi ditentukan di sini (versi terakhir + 1), nilai Version dari pemanggil diabaikan
+func (r *achievementRevisionRepositoryImpl) Create(ctx context.Context, revision *model_mongo.AchievementRevision) (*model_mongo.AchievementRevision, error) {
+	var err error
+	for attempt := 0; attempt < maxRevisionInsertAttempts; attempt++ {
+		latest, errLatest := r.LatestVersion(ctx, revision.AchievementID)
+		if errLatest != nil { return nil, errLatest }
+
+		revision.ID = primitive.NilObjectID
+		revision.Version = latest + 1
+		revision.CreatedAt = time.Now()
+		var result *mongo.InsertOneResult
+		result, err = r.Collection.InsertOne(ctx, revision)
+		if err == nil {
+			revision.ID = result.InsertedID.(primitive.ObjectID)
+			return revision, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) { return nil, err }
+	}
+	return nil, err
+}
+
+func (r *achievementRevisionRepositoryImpl) ListByAchievement(ctx context.Context, achievementID primitive.ObjectID) ([]model_mongo.AchievementRevision, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: 1}})
+	cursor, err := r.Collection.Find(ctx, bson.M{"achievementId": achievementID}, opts)
+	if err != nil { return nil, err }
+	defer cursor.Close(ctx)
+
+	revisions := []model_mongo.AchievementRevision{}
+	if err = cursor.All(ctx, &revisions); err != nil { return nil, err }
+	return revisions, nil
+}
+
+func (r *achievementRevisionRepositoryImpl) GetByVersion(ctx context.Context, achievementID primitive.ObjectID, version int) (*model_mongo.AchievementRevision, error) {
+	var revision model_mongo.AchievementRevision
+	err := r.Collection.FindOne(ctx, bson.M{"achievementId": achievementID, "version": version}).Decode(&revision)
+	if err != nil { return nil, err }
+	return &revision, nil
+}
+
+// LatestVersion: 0 bila belum ada revisi (dokumen lama sebelum fitur revisi)
+func (r *achievementRevisionRepositoryImpl) LatestVersion(ctx context.Context, achievementID primitive.ObjectID) (int, error) {
+	var latest model_mongo.AchievementRevision
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}).SetProjection(bson.M{"version": 1})
+	err := r.Collection.FindOne(ctx, bson.M{"achievementId": achievementID}, opts).Decode(&latest)
+	if err == mongo.ErrNoDocuments { return 0, nil }
+	if err != nil { return 0, err }
+	return latest.Version, nil
+}
+
+func (r *achievementRevisionRepositoryImpl) DeleteByAchievement(ctx context.Context, achievementID primitive.ObjectID) error {
+	_, err := r.Collection.DeleteMany(ctx, bson.M{"achievementId": achievementID})
+	return err
+}
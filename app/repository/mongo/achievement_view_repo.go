@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Nama parameter API -> field achievement_views
+var viewSortFields = map[string]string{
+	"created_at":   "createdAt",
+	"updated_at":   "updatedAt",
+	"submitted_at": "submittedAt",
+	"verified_at":  "verifiedAt",
+}
+
+// Ukuran batch bulk write saat rebuild
+const viewWriteBatch = 500
+
+type AchievementViewRepository interface {
+	ReplaceForAchievement(ctx context.Context, mongoID string, views []model_mongo.AchievementView) error
+	ReplaceAll(ctx context.Context, views []model_mongo.AchievementView) error
+	IsEmpty(ctx context.Context) (bool, error)
+	List(ctx context.Context, filter model_mongo.AchievementViewFilter) ([]model_mongo.AchievementView, int, error)
+	Search(ctx context.Context, query string, filter model_mongo.AchievementViewFilter, limit int) ([]model_mongo.AchievementViewHit, error)
+}
+
+type achievementViewRepositoryImpl struct {
+	Collection *mongo.Collection
+}
+
+func NewAchievementViewRepository(db *mongo.Database) AchievementViewRepository {
+	return &achievementViewRepositoryImpl{
+		Collection: db.Collection("achievement_views"),
+	}
+}
+
+// ReplaceForAchievement: Simpan view seluruh referensi satu dokumen prestasi. View referensi yang
+// sudah tidak ada dihapus; views kosong = hapus semua view dokumen tersebut.
+func (r *achievementViewRepositoryImpl) ReplaceForAchievement(ctx context.Context, mongoID string, views []model_mongo.AchievementView) error {
+	keep := make([]string, len(views))
+	for i, view := range views { keep[i] = view.ID }
+	_, err := r.Collection.DeleteMany(ctx, bson.M{"mongoAchievementId": mongoID, "_id": bson.M{"$nin": keep}})
+	if err != nil { return err }
+	return r.upsert(ctx, views, time.Now())
+}
+
+// ReplaceAll: Ganti seluruh isi koleksi (rebuild). View ditimpa per batch lalu view yang tidak
+// ikut diproyeksikan dihapus, sehingga pembaca tidak pernah melihat koleksi kosong.
+func (r *achievementViewRepositoryImpl) ReplaceAll(ctx context.Context, views []model_mongo.AchievementView) error {
+	now := time.Now()
+	for start := 0; start < len(views); start += viewWriteBatch {
+		end := start + viewWriteBatch
+		if end > len(views) { end = len(views) }
+		if err := r.upsert(ctx, views[start:end], now); err != nil { return err }
+	}
+	_, err := r.Collection.DeleteMany(ctx, bson.M{"projectedAt": bson.M{"$lt": now}})
+	return err
+}
+
+// IsEmpty: Koleksi belum berisi view sama sekali (read model belum pernah dibangun)
+func (r *achievementViewRepositoryImpl) IsEmpty(ctx context.Context) (bool, error) {
+	count, err := r.Collection.CountDocuments(ctx, bson.M{}, options.Count().SetLimit(1))
+	if err != nil { return false, err }
+	return count == 0, nil
+}
+
+func (r *achievementViewRepositoryImpl) upsert(ctx context.Context, views []model_mongo.AchievementView, projectedAt time.Time) error {
+	if len(views) == 0 { return nil }
+	models := make([]mongo.WriteModel, len(views))
+	for i := range views {
+		views[i].ProjectedAt = projectedAt
+		models[i] = mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": views[i].ID}).SetReplacement(views[i]).SetUpsert(true)
+	}
+	_, err := r.Collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// List: Satu halaman view sesuai filter beserta total seluruh view yang cocok.
+// Sort & DateField harus sudah divalidasi caller. Halaman berisi maksimal Limit+1 view agar caller
+// bisa mengetahui ada halaman berikutnya; Limit 0 = tanpa batas.
+func (r *achievementViewRepositoryImpl) List(ctx context.Context, filter model_mongo.AchievementViewFilter) ([]model_mongo.AchievementView, int, error) {
+	query, ok := viewFilterQuery(filter)
+	if !ok { return []model_mongo.AchievementView{}, 0, nil }
+
+	total, err := r.Collection.CountDocuments(ctx, query)
+	if err != nil { return nil, 0, err }
+
+	sortField := viewSortFields[filter.Sort]
+	direction, cmp := 1, "$gt"
+	if filter.Desc { direction, cmp = -1, "$lt" }
+	if filter.CursorValue != nil {
+		query = bson.M{"$and": bson.A{query, bson.M{"$or": bson.A{
+			bson.M{sortField: bson.M{cmp: *filter.CursorValue}},
+			bson.M{sortField: *filter.CursorValue, "_id": bson.M{cmp: filter.CursorID}},
+		}}}}
+	}
+	// Nilai kosong (mis. verifiedAt draft) berada di akhir urutan desc dan di awal urutan asc
+	opts := options.Find().SetSort(bson.D{{Key: sortField, Value: direction}, {Key: "_id", Value: direction}})
+	if filter.Limit > 0 {
+		opts.SetLimit(int64(filter.Limit + 1)).SetSkip(int64(filter.Offset))
+	}
+
+	cursor, err := r.Collection.Find(ctx, query, opts)
+	if err != nil { return nil, 0, err }
+	defer cursor.Close(ctx)
+
+	views := []model_mongo.AchievementView{}
+	if err = cursor.All(ctx, &views); err != nil { return nil, 0, err }
+	return views, int(total), nil
+}
+
+// Search: Pencarian teks (index achievement_views_text) pada judul, tag & deskripsi dengan scope
+// yang sama seperti List, diurutkan berdasarkan relevansi.
+func (r *achievementViewRepositoryImpl) Search(ctx context.Context, query string, filter model_mongo.AchievementViewFilter, limit int) ([]model_mongo.AchievementViewHit, error) {
+	scope, ok := viewFilterQuery(filter)
+	if !ok { return []model_mongo.AchievementViewHit{}, nil }
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.Collection.Find(ctx, bson.M{"$text": bson.M{"$search": query}, "$and": bson.A{scope}}, opts)
+	if err != nil { return nil, err }
+	defer cursor.Close(ctx)
+
+	hits := []model_mongo.AchievementViewHit{}
+	if err = cursor.All(ctx, &hits); err != nil { return nil, err }
+	return hits, nil
+}
+
+// viewFilterQuery menyusun query scope + filter. ok=false bila scope tidak mencakup mahasiswa mana pun.
+// View tanpa dokumen (DetailMissing) tidak ditampilkan; drift tersebut dilaporkan rekonsiliasi.
+func viewFilterQuery(filter model_mongo.AchievementViewFilter) (bson.M, bool) {
+	excluded := bson.A{string(model_postgre.StatusDeleted)}
+	if !filter.IncludeDrafts { excluded = append(excluded, string(model_postgre.StatusDraft)) }
+	conds := bson.A{bson.M{"status": bson.M{"$nin": excluded}, "detailMissing": false}}
+
+	if !filter.All {
+		scope := bson.A{}
+		if len(filter.StudentIDs) > 0 {
+			scope = append(scope, bson.M{"studentId": bson.M{"$in": filter.StudentIDs}})
+		}
+		if len(filter.PendingStudentIDs) > 0 {
+			scope = append(scope, bson.M{"studentId": bson.M{"$in": filter.PendingStudentIDs}, "status": string(model_postgre.StatusSubmitted)})
+		}
+		if len(scope) == 0 { return nil, false }
+		conds = append(conds, bson.M{"$or": scope})
+	}
+	if len(filter.Statuses) > 0 {
+		conds = append(conds, bson.M{"status": bson.M{"$in": filter.Statuses}})
+	}
+	if filter.StudentID != "" {
+		conds = append(conds, bson.M{"studentId": filter.StudentID})
+	}
+	if filter.DateFrom != nil || filter.DateTo != nil {
+		dates := bson.M{}
+		if filter.DateFrom != nil { dates["$gte"] = *filter.DateFrom }
+		if filter.DateTo != nil { dates["$lt"] = *filter.DateTo }
+		conds = append(conds, bson.M{viewSortFields[filter.DateField]: dates})
+	}
+	if filter.AchievementType != "" {
+		conds = append(conds, bson.M{"achievementType": filter.AchievementType})
+	}
+	if len(filter.Tags) > 0 {
+		conds = append(conds, bson.M{"tags": bson.M{"$all": filter.Tags}})
+	}
+	if filter.MinPoints != nil || filter.MaxPoints != nil {
+		points := bson.M{}
+		if filter.MinPoints != nil { points["$gte"] = *filter.MinPoints }
+		if filter.MaxPoints != nil { points["$lte"] = *filter.MaxPoints }
+		conds = append(conds, bson.M{"points": points})
+	}
+	return bson.M{"$and": conds}, true
+}
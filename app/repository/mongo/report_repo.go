@@ -2,13 +2,18 @@ package repository
 
 import (
 	"context"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ReportMongoRepository: Laporan dibaca dari read model achievement_views
 type ReportMongoRepository interface {
 	GetAchievementStatistics(ctx context.Context, studentIDs []string) ([]bson.M, error)
-	GetStudentAchievementDetails(ctx context.Context, studentIDHex string) ([]bson.M, error)
+	GetStudentAchievementViews(ctx context.Context, studentID string) ([]model_mongo.AchievementView, error)
 }
 
 type reportMongoRepositoryImpl struct {
@@ -17,31 +22,32 @@ type reportMongoRepositoryImpl struct {
 
 func NewReportMongoRepository(db *mongo.Database) ReportMongoRepository {
 	return &reportMongoRepositoryImpl{
-		Collection: db.Collection("achievements"),
+		Collection: db.Collection("achievement_views"),
 	}
 }
 
 func (r *reportMongoRepositoryImpl) GetAchievementStatistics(ctx context.Context, studentIDs []string) ([]bson.M, error) {
-	var matchStage bson.D
-	filter := bson.M{"deletedAt": nil}
+	filter := bson.M{"status": bson.M{"$ne": model_postgre.StatusDeleted}, "detailMissing": false}
 	if len(studentIDs) > 0 {
+		// Prestasi tim punya view per anggota, cukup filter studentId
 		filter["studentId"] = bson.M{"$in": studentIDs}
 	}
-	matchStage = bson.D{{Key: "$match", Value: filter}}
 
-	// Agregasi untuk Total per Tipe & Distribusi Level Kompetisi
-	groupStage := bson.D{
-		{Key: "$group", Value: bson.M{
-			"_id": bson.M{
-				"type": "$achievementType",
-				"level": "$details.competitionLevel", 
-			},
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		// Prestasi tim dihitung sekali per dokumen
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$mongoAchievementId",
+			"type":  bson.M{"$first": "$achievementType"},
+			"level": bson.M{"$first": "$competitionLevel"},
+		}}},
+		// Agregasi untuk Total per Tipe & Distribusi Level Kompetisi
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"type": "$type", "level": "$level"},
 			"count": bson.M{"$sum": 1},
-		}},
+		}}},
 	}
 
-	pipeline := mongo.Pipeline{matchStage, groupStage}
-	
 	cursor, err := r.Collection.Aggregate(ctx, pipeline)
 	if err != nil {
 		return nil, err
@@ -55,18 +61,20 @@ func (r *reportMongoRepositoryImpl) GetAchievementStatistics(ctx context.Context
 	return results, nil
 }
 
-func (r *reportMongoRepositoryImpl) GetStudentAchievementDetails(ctx context.Context, studentIDHex string) ([]bson.M, error) {
-	filter := bson.M{"studentId": studentIDHex, "deletedAt": nil}
-	
-	cursor, err := r.Collection.Find(ctx, filter)
+// GetStudentAchievementViews: Semua prestasi (selain yang dihapus) milik/bersama satu mahasiswa, terbaru dulu
+func (r *reportMongoRepositoryImpl) GetStudentAchievementViews(ctx context.Context, studentID string) ([]model_mongo.AchievementView, error) {
+	filter := bson.M{"studentId": studentID, "status": bson.M{"$ne": model_postgre.StatusDeleted}}
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	cursor, err := r.Collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, err
 	}
 	defer cursor.Close(ctx)
-	
-	var achievements []bson.M
-	if err = cursor.All(ctx, &achievements); err != nil {
+
+	views := []model_mongo.AchievementView{}
+	if err = cursor.All(ctx, &views); err != nil {
 		return nil, err
 	}
-	return achievements, nil
-}
\ No newline at end of file
+	return views, nil
+}
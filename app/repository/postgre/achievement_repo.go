@@ -5,29 +5,62 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
-	_ "github.com/lib/pq" 
+	_ "github.com/lib/pq"
 	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
 )
 
 type AchievementPGRepository interface {
-	CreateReference(ref *model_postgre.AchievementReference) (*model_postgre.AchievementReference, error)
+	CreateReference(ref *model_postgre.AchievementReference, actorID string, event *model_postgre.OutboxEvent) (*model_postgre.AchievementReference, error)
+	CreateTeamReferences(owner *model_postgre.AchievementReference, members []model_postgre.AchievementReference, actorID string, event *model_postgre.OutboxEvent) (*model_postgre.AchievementReference, error)
+	GetTeamReferences(mongoAchievementID string) ([]model_postgre.AchievementReference, error)
+	GetReferencesByMongoID(mongoAchievementID string) ([]model_postgre.AchievementReference, error)
+	ConfirmParticipation(refID string, studentID string) (*model_postgre.AchievementReference, error)
+	FindExistingStudentIDs(studentIDs []string) ([]string, error)
 	GetReferenceByID(id string) (*model_postgre.AchievementReference, error)
+	GetReferencesByIDs(ids []string) ([]model_postgre.AchievementReference, error)
 	
-	UpdateStatusToSubmitted(refID string) (*model_postgre.AchievementReference, error)
-	VerifyAchievement(refID string, verifierID string) (*model_postgre.AchievementReference, error)
-	RejectAchievement(refID string, verifierID string, rejectionNote string) (*model_postgre.AchievementReference, error)
-	SoftDeleteReference(refID string, studentID string) (*model_postgre.AchievementReference, error)
+	UpdateStatusToSubmitted(refID string, actorID string, workflowCode string, firstStage string) (*model_postgre.AchievementReference, error)
+	WithdrawSubmission(refID string, studentID string, actorID string) (*model_postgre.AchievementReference, error)
+	AdvanceStage(refID string, actorID string, onBehalfOf string, fromStage string, toStage string, note string) (*model_postgre.AchievementReference, error)
+	VerifyAchievement(refID string, verifierID string, onBehalfOf string, stage string, note string) (*model_postgre.AchievementReference, error)
+	RejectAchievement(refID string, verifierID string, onBehalfOf string, stage string, rejectionNote string) (*model_postgre.AchievementReference, error)
+	RequestRevision(refID string, verifierID string, onBehalfOf string, stage string, revisionNote string) (*model_postgre.AchievementReference, error)
+	SoftDeleteReference(refID string, studentID string, actorID string) (*model_postgre.AchievementReference, error)
+	OverrideStatus(refID string, to model_postgre.AchievementStatus, actorID string, reason string, workflowCode string, firstStage string, allowFromVerified bool) (*model_postgre.AchievementReference, error)
+	RestoreReference(refID string, studentID string, actorID string) (*model_postgre.AchievementReference, error)
+	GetDeletedReferences(studentID string) ([]model_postgre.AchievementReference, error)
+	GetPurgeableReferences(deletedBefore time.Time) ([]model_postgre.AchievementReference, error)
+	PurgeReferences(mongoAchievementID string) (int64, error)
+	GetStatusHistory(refID string) ([]model_postgre.AchievementStatusHistory, error)
 	
 	FindStudentIdByUserID(userID string) (string, error)
 	FindLecturerIdByUserID(userID string) (string, error)
 	GetAdviseeStudentIDs(lecturerID string) ([]string, error)
+	GetDelegatedAdvisees(lecturerID string) ([]model_postgre.DelegatedAdvisee, error)
 	
-	GetMyAchievements(studentID string) ([]model_postgre.AchievementReference, error)
 	GetAchievementsByStudentIDs(studentIDs []string) ([]model_postgre.AchievementReference, error)
 	GetAllAchievementReferences() ([]model_postgre.AchievementReference, error)
+	GetAllReferencesWithDeleted() ([]model_postgre.AchievementReference, error)
+	TrashReference(refID string, actorID string, note string) (*model_postgre.AchievementReference, error)
 }
 
+// Sentinel error untuk transisi status. Pesan asli (notFoundMsg) tetap dikembalikan ke client,
+// sementara caller dapat membedakan jenisnya dengan errors.Is.
+var (
+	ErrReferenceNotFound = errors.New("referensi prestasi tidak ditemukan")
+	ErrInvalidTransition = errors.New("status prestasi tidak sesuai untuk transisi ini")
+)
+
+type transitionError struct {
+	kind error
+	msg  string
+}
+
+func (e *transitionError) Error() string { return e.msg }
+func (e *transitionError) Unwrap() error { return e.kind }
+
 type achievementPGRepositoryImpl struct {
 	DB *sql.DB 
 }
@@ -36,95 +69,555 @@ func NewAchievementPGRepository(db *sql.DB) AchievementPGRepository {
 	return &achievementPGRepositoryImpl{DB: db}
 }
 
+// achievementColumns harus selalu sinkron dengan urutan Scan di scanAchievementRow
+const achievementColumns = `id, student_id, mongo_achievement_id, status, submitted_at, verified_at, updated_at, verified_by, rejection_note, created_at,
+		revision_count, revision_note, workflow_code, current_stage, team_role, co_member, participation_confirmed_at, deleted_at,
+		escalated_at, escalated_to, escalated_stage, stage_entered_at`
+
 func scanAchievementRow(scan func(dest ...interface{}) error) (*model_postgre.AchievementReference, error) {
 	ref := new(model_postgre.AchievementReference)
 	err := scan(
 		&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status,
 		&ref.SubmittedAt, &ref.VerifiedAt, &ref.UpdatedAt,
 		&ref.VerifiedBy, &ref.RejectionNote, &ref.CreatedAt,
+		&ref.RevisionCount, &ref.RevisionNote, &ref.WorkflowCode, &ref.CurrentStage,
+		&ref.TeamRole, &ref.CoMember, &ref.ParticipationConfirmedAt, &ref.DeletedAt,
+		&ref.EscalatedAt, &ref.EscalatedTo, &ref.EscalatedStage, &ref.StageEnteredAt,
 	)
 	return ref, err
 }
 
-func (r *achievementPGRepositoryImpl) CreateReference(ref *model_postgre.AchievementReference) (*model_postgre.AchievementReference, error) {
+// insertStatusHistory mencatat satu perubahan status. Selalu dipanggil di dalam transaksi
+// yang sama dengan UPDATE status agar log tidak pernah tertinggal dari data.
+func insertStatusHistory(tx *sql.Tx, refID string, from *model_postgre.AchievementStatus, to model_postgre.AchievementStatus, actorID string, note *string) error {
+	return insertHistoryRow(tx, refID, from, to, actorID, note, false, "")
+}
+
+// insertHistoryRow: onBehalfOf = user ID dosen wali asli bila aksi dilakukan lewat delegasi
+func insertHistoryRow(tx *sql.Tx, refID string, from *model_postgre.AchievementStatus, to model_postgre.AchievementStatus, actorID string, note *string, override bool, onBehalfOf string) error {
+	var actor, behalf *string
+	if actorID != "" { actor = &actorID }
+	if onBehalfOf != "" { behalf = &onBehalfOf }
+	_, err := tx.Exec(`
+		INSERT INTO achievement_status_history (achievement_ref_id, from_status, to_status, actor_id, note, is_override, on_behalf_of)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, refID, from, to, actor, note, override, behalf)
+	return err
+}
+
+// transitionStatus mengunci baris referensi, memastikan status saat ini termasuk allowedFrom,
+// menjalankan query UPDATE (yang tetap di-guard dengan status asal), lalu menulis riwayatnya
+// dalam satu transaksi.
+func (r *achievementPGRepositoryImpl) transitionStatus(refID string, allowedFrom []model_postgre.AchievementStatus, query string, args []interface{}, actorID string, note *string, notFoundMsg string) (*model_postgre.AchievementReference, error) {
+	return r.runTransition(refID, allowedFrom, query, args, actorID, note, notFoundMsg, false, "", "")
+}
+
+// runTransition adalah implementasi transitionStatus; override menandai baris riwayat sebagai override Admin,
+// onBehalfOf mencatat dosen wali asli untuk aksi verifikator delegasi, outboxOp dicatat sebagai event
+// outbox untuk dokumen Mongo referensi dalam transaksi yang sama (kosong = hanya refresh read model)
+func (r *achievementPGRepositoryImpl) runTransition(refID string, allowedFrom []model_postgre.AchievementStatus, query string, args []interface{}, actorID string, note *string, notFoundMsg string, override bool, onBehalfOf string, outboxOp string) (*model_postgre.AchievementReference, error) {
+	tx, err := r.DB.Begin()
+	if err != nil { return nil, err }
+	defer tx.Rollback()
+
+	// Kunci seluruh referensi yang berbagi dokumen Mongo (satu baris untuk prestasi individu,
+	// semua anggota untuk prestasi tim) dengan urutan tetap agar tidak terjadi deadlock
+	rows, err := tx.Query(`
+		SELECT id, status FROM achievement_references
+		WHERE mongo_achievement_id = (SELECT mongo_achievement_id FROM achievement_references WHERE id = $1)
+		ORDER BY id FOR UPDATE
+	`, refID)
+	if err != nil { return nil, err }
+	var current model_postgre.AchievementStatus
+	found := false
+	for rows.Next() {
+		var id string
+		var status model_postgre.AchievementStatus
+		if err := rows.Scan(&id, &status); err != nil { rows.Close(); return nil, err }
+		if id == refID { current, found = status, true }
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil { return nil, err }
+	if !found { return nil, &transitionError{kind: ErrReferenceNotFound, msg: notFoundMsg} }
+	if !statusIn(current, allowedFrom) { return nil, &transitionError{kind: ErrInvalidTransition, msg: notFoundMsg} }
+
+	ref, err := scanAchievementRow(tx.QueryRow(query, args...).Scan)
+	if errors.Is(err, sql.ErrNoRows) { return nil, &transitionError{kind: ErrInvalidTransition, msg: notFoundMsg} }
+	if err != nil { return nil, err }
+
+	if err := insertHistoryRow(tx, ref.ID, &current, ref.Status, actorID, note, override, onBehalfOf); err != nil {
+		return nil, fmt.Errorf("gagal mencatat riwayat status: %w", err)
+	}
+
+	memberIDs, err := syncTeamReferences(tx, ref.ID)
+	if err != nil { return nil, fmt.Errorf("gagal menyinkronkan anggota tim: %w", err) }
+	for _, memberID := range memberIDs {
+		if err := insertHistoryRow(tx, memberID, &current, ref.Status, actorID, note, override, onBehalfOf); err != nil {
+			return nil, fmt.Errorf("gagal mencatat riwayat status: %w", err)
+		}
+	}
+	if outboxOp == "" { outboxOp = model_postgre.OutboxRefreshView }
+	if err := insertOutboxEvent(tx, &model_postgre.OutboxEvent{AggregateID: ref.MongoAchievementID, Operation: outboxOp}); err != nil {
+		return nil, fmt.Errorf("gagal mencatat outbox: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil { return nil, err }
+	return ref, nil
+}
+
+// syncTeamReferences menyalin status & kolom workflow dari referensi yang baru bertransisi ke
+// referensi anggota tim lain (dokumen Mongo sama). Untuk prestasi individu tidak ada baris yang berubah.
+// Hapus/restore juga ikut tersinkron karena hanya pembuat yang dapat menghapus prestasi tim.
+func syncTeamReferences(tx *sql.Tx, refID string) ([]string, error) {
+	rows, err := tx.Query(`
+		UPDATE achievement_references t
+		SET status = p.status, submitted_at = p.submitted_at, verified_at = p.verified_at, verified_by = p.verified_by,
+			rejection_note = p.rejection_note, revision_count = p.revision_count, revision_note = p.revision_note,
+			workflow_code = p.workflow_code, current_stage = p.current_stage, updated_at = p.updated_at,
+			deleted_at = p.deleted_at, escalated_at = p.escalated_at, escalated_to = p.escalated_to,
+			escalated_stage = p.escalated_stage, stage_entered_at = p.stage_entered_at
+		FROM achievement_references p
+		WHERE p.id = $1 AND t.mongo_achievement_id = p.mongo_achievement_id AND t.id != p.id
+		RETURNING t.id
+	`, refID)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil { return nil, err }
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func statusIn(status model_postgre.AchievementStatus, list []model_postgre.AchievementStatus) bool {
+	for _, st := range list {
+		if st == status { return true }
+	}
+	return false
+}
+
+// CreateReference membuat referensi Draft. event (opsional) dicatat ke outbox dalam transaksi yang sama.
+func (r *achievementPGRepositoryImpl) CreateReference(ref *model_postgre.AchievementReference, actorID string, event *model_postgre.OutboxEvent) (*model_postgre.AchievementReference, error) {
 	query := `
 		INSERT INTO achievement_references (student_id, mongo_achievement_id, status)
 		VALUES ($1, $2, $3) RETURNING id, created_at, updated_at
 	`
 	ref.Status = model_postgre.StatusDraft 
-	err := r.DB.QueryRow(query, ref.StudentID, ref.MongoAchievementID, ref.Status).Scan(&ref.ID, &ref.CreatedAt, &ref.UpdatedAt)
+
+	tx, err := r.DB.Begin()
+	if err != nil { return nil, err }
+	defer tx.Rollback()
+
+	err = tx.QueryRow(query, ref.StudentID, ref.MongoAchievementID, ref.Status).Scan(&ref.ID, &ref.CreatedAt, &ref.UpdatedAt)
 	if err != nil { return nil, err }
+
+	if err := insertStatusHistory(tx, ref.ID, nil, ref.Status, actorID, nil); err != nil {
+		return nil, fmt.Errorf("gagal mencatat riwayat status: %w", err)
+	}
+	if event != nil {
+		if err := insertOutboxEvent(tx, event); err != nil { return nil, fmt.Errorf("gagal mencatat outbox: %w", err) }
+	}
+	if err := tx.Commit(); err != nil { return nil, err }
 	return ref, nil
 }
 
+// CreateTeamReferences membuat referensi pembuat dan seluruh anggota tim dalam satu transaksi.
+// Anggota ditandai co_member dan belum terkonfirmasi. event (opsional) dicatat ke outbox dalam transaksi yang sama.
+func (r *achievementPGRepositoryImpl) CreateTeamReferences(owner *model_postgre.AchievementReference, members []model_postgre.AchievementReference, actorID string, event *model_postgre.OutboxEvent) (*model_postgre.AchievementReference, error) {
+	query := `
+		INSERT INTO achievement_references (student_id, mongo_achievement_id, status, team_role, co_member)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+achievementColumns+`
+	`
+	tx, err := r.DB.Begin()
+	if err != nil { return nil, err }
+	defer tx.Rollback()
+
+	created, err := scanAchievementRow(tx.QueryRow(query, owner.StudentID, owner.MongoAchievementID, model_postgre.StatusDraft, owner.TeamRole, false).Scan)
+	if err != nil { return nil, err }
+	if err := insertStatusHistory(tx, created.ID, nil, created.Status, actorID, nil); err != nil {
+		return nil, fmt.Errorf("gagal mencatat riwayat status: %w", err)
+	}
+
+	for _, member := range members {
+		memberRef, err := scanAchievementRow(tx.QueryRow(query, member.StudentID, owner.MongoAchievementID, model_postgre.StatusDraft, member.TeamRole, true).Scan)
+		if err != nil { return nil, err }
+		if err := insertStatusHistory(tx, memberRef.ID, nil, memberRef.Status, actorID, nil); err != nil {
+			return nil, fmt.Errorf("gagal mencatat riwayat status: %w", err)
+		}
+	}
+	if event != nil {
+		if err := insertOutboxEvent(tx, event); err != nil { return nil, fmt.Errorf("gagal mencatat outbox: %w", err) }
+	}
+
+	if err := tx.Commit(); err != nil { return nil, err }
+	return created, nil
+}
+
+// GetTeamReferences: Semua referensi (selain yang dihapus) yang berbagi satu dokumen Mongo
+func (r *achievementPGRepositoryImpl) GetTeamReferences(mongoAchievementID string) ([]model_postgre.AchievementReference, error) {
+	query := `
+		SELECT `+achievementColumns+`
+		FROM achievement_references WHERE mongo_achievement_id = $1 AND status != $2
+		ORDER BY co_member, created_at
+	`
+	rows, err := r.DB.Query(query, mongoAchievementID, model_postgre.StatusDeleted)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	list := []model_postgre.AchievementReference{}
+	for rows.Next() {
+		ref, err := scanAchievementRow(rows.Scan)
+		if err != nil { return nil, err }
+		list = append(list, *ref)
+	}
+	return list, rows.Err()
+}
+
+// ConfirmParticipation: Anggota tim mengonfirmasi keikutsertaannya (hanya sebelum disubmit)
+func (r *achievementPGRepositoryImpl) ConfirmParticipation(refID string, studentID string) (*model_postgre.AchievementReference, error) {
+	query := `
+		UPDATE achievement_references SET participation_confirmed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND student_id = $2 AND co_member = TRUE AND participation_confirmed_at IS NULL
+			AND status IN ($3, $4)
+		RETURNING `+achievementColumns+`
+	`
+	tx, err := r.DB.Begin()
+	if err != nil { return nil, err }
+	defer tx.Rollback()
+
+	ref, err := scanAchievementRow(tx.QueryRow(query, refID, studentID, model_postgre.StatusDraft, model_postgre.StatusRevisionRequested).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, &transitionError{kind: ErrInvalidTransition, msg: "konfirmasi gagal: bukan anggota tim, sudah dikonfirmasi, atau prestasi sudah disubmit"}
+	}
+	if err != nil { return nil, err }
+	if err := insertOutboxEvent(tx, &model_postgre.OutboxEvent{AggregateID: ref.MongoAchievementID, Operation: model_postgre.OutboxRefreshView}); err != nil {
+		return nil, fmt.Errorf("gagal mencatat outbox: %w", err)
+	}
+	if err := tx.Commit(); err != nil { return nil, err }
+	return ref, nil
+}
+
+// FindExistingStudentIDs: Mengembalikan subset ID yang benar-benar ada di tabel students
+func (r *achievementPGRepositoryImpl) FindExistingStudentIDs(studentIDs []string) ([]string, error) {
+	if len(studentIDs) == 0 { return []string{}, nil }
+
+	placeholders := make([]string, len(studentIDs))
+	args := make([]interface{}, len(studentIDs))
+	for i, id := range studentIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+	rows, err := r.DB.Query(fmt.Sprintf(`SELECT id FROM students WHERE id::text IN (%s)`, strings.Join(placeholders, ",")), args...)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil { return nil, err }
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 func (r *achievementPGRepositoryImpl) GetReferenceByID(id string) (*model_postgre.AchievementReference, error) {
 	query := `
-		SELECT id, student_id, mongo_achievement_id, status, submitted_at, verified_at, updated_at, verified_by, rejection_note, created_at
+		SELECT `+achievementColumns+`
 		FROM achievement_references WHERE id = $1 AND status != $2
 	`
 	return scanAchievementRow(r.DB.QueryRow(query, id, model_postgre.StatusDeleted).Scan)
 }
 
-func (r *achievementPGRepositoryImpl) UpdateStatusToSubmitted(refID string) (*model_postgre.AchievementReference, error) {
+// GetReferencesByIDs: Ambil banyak referensi sekaligus (untuk aksi massal). ID yang tidak
+// ditemukan/terhapus cukup tidak muncul di hasil.
+func (r *achievementPGRepositoryImpl) GetReferencesByIDs(ids []string) ([]model_postgre.AchievementReference, error) {
+	if len(ids) == 0 { return []model_postgre.AchievementReference{}, nil }
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = id
+	}
+	args = append(args, model_postgre.StatusDeleted)
+
+	query := fmt.Sprintf(`
+		SELECT `+achievementColumns+`
+		FROM achievement_references WHERE id IN (%s) AND status != $%d
+	`, strings.Join(placeholders, ","), len(ids)+1)
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	var list []model_postgre.AchievementReference
+	for rows.Next() {
+		ref, err := scanAchievementRow(rows.Scan)
+		if err != nil { return nil, err }
+		list = append(list, *ref)
+	}
+	return list, rows.Err()
+}
+
+func (r *achievementPGRepositoryImpl) UpdateStatusToSubmitted(refID string, actorID string, workflowCode string, firstStage string) (*model_postgre.AchievementReference, error) {
 	query := `
-		UPDATE achievement_references SET status = $1, submitted_at = NOW(), updated_at = NOW()
-		WHERE id = $2 AND status = $3
-		RETURNING id, student_id, mongo_achievement_id, status, submitted_at, verified_at, updated_at, verified_by, rejection_note, created_at
+		UPDATE achievement_references
+		SET status = $1, submitted_at = NOW(), updated_at = NOW(), workflow_code = $2, current_stage = $3, stage_entered_at = NOW()
+		WHERE id = $4 AND status IN ($5, $6)
+		RETURNING `+achievementColumns+`
 	`
-	ref, err := scanAchievementRow(r.DB.QueryRow(query, model_postgre.StatusSubmitted, refID, model_postgre.StatusDraft).Scan)
-	if errors.Is(err, sql.ErrNoRows) { return nil, errors.New("prestasi tidak ditemukan/status bukan draft") }
-	return ref, err
+	allowed := []model_postgre.AchievementStatus{model_postgre.StatusDraft, model_postgre.StatusRevisionRequested}
+	args := []interface{}{model_postgre.StatusSubmitted, workflowCode, firstStage, refID, allowed[0], allowed[1]}
+	return r.transitionStatus(refID, allowed, query, args, actorID, nil, "prestasi tidak ditemukan/status bukan draft atau revision_requested")
 }
 
-func (r *achievementPGRepositoryImpl) VerifyAchievement(refID string, verifierID string) (*model_postgre.AchievementReference, error) {
+// WithdrawSubmission mengembalikan prestasi Submitted ke Draft atas permintaan pemilik. Hanya boleh
+// selama belum ada aksi verifikator sejak submit terakhir (setiap aksi verifikator pada prestasi
+// submitted tercatat di riwayat dengan from_status = submitted).
+func (r *achievementPGRepositoryImpl) WithdrawSubmission(refID string, studentID string, actorID string) (*model_postgre.AchievementReference, error) {
+	query := `
+		UPDATE achievement_references ar
+		SET status = $1, submitted_at = NULL, workflow_code = NULL, current_stage = NULL, stage_entered_at = NULL, updated_at = NOW()
+		WHERE ar.id = $2 AND ar.student_id = $3 AND ar.status = $4 AND ar.co_member = FALSE
+			AND NOT EXISTS (
+				SELECT 1 FROM achievement_status_history h
+				WHERE h.achievement_ref_id = ar.id AND h.from_status = $4 AND h.created_at >= ar.submitted_at
+			)
+		RETURNING `+achievementColumns+`
+	`
+	note := "Ditarik kembali oleh mahasiswa sebelum diproses verifikator"
+	args := []interface{}{model_postgre.StatusDraft, refID, studentID, model_postgre.StatusSubmitted}
+	return r.transitionStatus(refID, []model_postgre.AchievementStatus{model_postgre.StatusSubmitted}, query, args, actorID, &note, "gagal withdraw: bukan pemilik, status bukan submitted, atau verifikator sudah memproses prestasi")
+}
+
+// AdvanceStage memindahkan prestasi Submitted ke tahap verifikasi berikutnya. Status tetap
+// submitted; riwayat mencatat persetujuan tahap sebelumnya dan SLA tahap baru mulai dihitung.
+func (r *achievementPGRepositoryImpl) AdvanceStage(refID string, actorID string, onBehalfOf string, fromStage string, toStage string, note string) (*model_postgre.AchievementReference, error) {
+	query := `
+		UPDATE achievement_references SET current_stage = $1, stage_entered_at = NOW(), updated_at = NOW()
+		WHERE id = $2 AND status = $3 AND COALESCE(current_stage, '') = $4
+		RETURNING `+achievementColumns+`
+	`
+	args := []interface{}{toStage, refID, model_postgre.StatusSubmitted, fromStage}
+	return r.runTransition(refID, []model_postgre.AchievementStatus{model_postgre.StatusSubmitted}, query, args, actorID, &note, "prestasi tidak ditemukan/status bukan submitted/tahap sudah berubah", false, onBehalfOf, "")
+}
+
+// VerifyAchievement menyelesaikan tahap terakhir. stage dipakai sebagai guard agar tidak ada
+// tahap yang terlewati bila dua verifikator memproses bersamaan.
+func (r *achievementPGRepositoryImpl) VerifyAchievement(refID string, verifierID string, onBehalfOf string, stage string, note string) (*model_postgre.AchievementReference, error) {
 	query := `
 		UPDATE achievement_references SET status = $1, verified_by = $2, verified_at = NOW(), updated_at = NOW()
-		WHERE id = $3 AND status = $4
-		RETURNING id, student_id, mongo_achievement_id, status, submitted_at, verified_at, updated_at, verified_by, rejection_note, created_at
+		WHERE id = $3 AND status = $4 AND COALESCE(current_stage, '') = $5
+		RETURNING `+achievementColumns+`
 	`
-	ref, err := scanAchievementRow(r.DB.QueryRow(query, model_postgre.StatusVerified, verifierID, refID, model_postgre.StatusSubmitted).Scan)
-	if errors.Is(err, sql.ErrNoRows) { return nil, errors.New("prestasi tidak ditemukan/status bukan submitted") }
-	return ref, err
+	args := []interface{}{model_postgre.StatusVerified, verifierID, refID, model_postgre.StatusSubmitted, stage}
+	var historyNote *string
+	if note != "" { historyNote = &note }
+	return r.runTransition(refID, []model_postgre.AchievementStatus{model_postgre.StatusSubmitted}, query, args, verifierID, historyNote, "prestasi tidak ditemukan/status bukan submitted/tahap sudah berubah", false, onBehalfOf, "")
 }
 
-func (r *achievementPGRepositoryImpl) RejectAchievement(refID string, verifierID string, rejectionNote string) (*model_postgre.AchievementReference, error) {
+// RejectAchievement menolak prestasi pada tahap stage. Sama seperti VerifyAchievement, stage menjadi
+// guard agar penolakan tidak berlaku bila tahap sudah dipindahkan verifikator lain.
+func (r *achievementPGRepositoryImpl) RejectAchievement(refID string, verifierID string, onBehalfOf string, stage string, rejectionNote string) (*model_postgre.AchievementReference, error) {
 	query := `
 		UPDATE achievement_references SET status = $1, verified_by = $2, rejection_note = $3, verified_at = NOW(), updated_at = NOW()
-		WHERE id = $4 AND status = $5
-		RETURNING id, student_id, mongo_achievement_id, status, submitted_at, verified_at, updated_at, verified_by, rejection_note, created_at
+		WHERE id = $4 AND status = $5 AND COALESCE(current_stage, '') = $6
+		RETURNING `+achievementColumns+`
 	`
-	ref, err := scanAchievementRow(r.DB.QueryRow(query, model_postgre.StatusRejected, verifierID, rejectionNote, refID, model_postgre.StatusSubmitted).Scan)
-	if errors.Is(err, sql.ErrNoRows) { return nil, errors.New("prestasi tidak ditemukan/status bukan submitted") }
-	return ref, err
+	args := []interface{}{model_postgre.StatusRejected, verifierID, rejectionNote, refID, model_postgre.StatusSubmitted, stage}
+	return r.runTransition(refID, []model_postgre.AchievementStatus{model_postgre.StatusSubmitted}, query, args, verifierID, &rejectionNote, "prestasi tidak ditemukan/status bukan submitted/tahap sudah berubah", false, onBehalfOf, "")
 }
 
-func (r *achievementPGRepositoryImpl) SoftDeleteReference(refID string, studentID string) (*model_postgre.AchievementReference, error) {
+// RequestRevision mengembalikan prestasi ke mahasiswa dari tahap stage (guard yang sama dengan RejectAchievement).
+func (r *achievementPGRepositoryImpl) RequestRevision(refID string, verifierID string, onBehalfOf string, stage string, revisionNote string) (*model_postgre.AchievementReference, error) {
 	query := `
-		UPDATE achievement_references SET status = $1, updated_at = NOW()
-		WHERE id = $2 AND student_id = $3 AND status = $4
-		RETURNING id, student_id, mongo_achievement_id, status, submitted_at, verified_at, updated_at, verified_by, rejection_note, created_at
+		UPDATE achievement_references
+		SET status = $1, verified_by = $2, revision_note = $3, revision_count = revision_count + 1, current_stage = NULL, stage_entered_at = NULL, updated_at = NOW()
+		WHERE id = $4 AND status = $5 AND COALESCE(current_stage, '') = $6
+		RETURNING `+achievementColumns+`
 	`
-	ref, err := scanAchievementRow(r.DB.QueryRow(query, model_postgre.StatusDeleted, refID, studentID, model_postgre.StatusDraft).Scan)
-	if errors.Is(err, sql.ErrNoRows) { return nil, errors.New("gagal hapus: ID salah, bukan pemilik, atau status bukan draft") }
-	return ref, err
+	args := []interface{}{model_postgre.StatusRevisionRequested, verifierID, revisionNote, refID, model_postgre.StatusSubmitted, stage}
+	return r.runTransition(refID, []model_postgre.AchievementStatus{model_postgre.StatusSubmitted}, query, args, verifierID, &revisionNote, "prestasi tidak ditemukan/status bukan submitted/tahap sudah berubah", false, onBehalfOf, "")
+}
+
+// OverrideStatus memindahkan prestasi ke status apa pun (kecuali deleted) tanpa mengikuti alur normal.
+// Kolom pendukung disesuaikan dengan status tujuan (ke draft/submitted juga mengosongkan catatan
+// penolakan/revisi dan eskalasi lama); reason dicatat di riwayat dengan is_override = TRUE.
+// workflowCode/firstStage hanya dipakai bila tujuan = submitted. Bila allowFromVerified false,
+// prestasi yang (saat dikunci) berstatus verified ditolak agar konfirmasi Admin tidak terlewati.
+func (r *achievementPGRepositoryImpl) OverrideStatus(refID string, to model_postgre.AchievementStatus, actorID string, reason string, workflowCode string, firstStage string, allowFromVerified bool) (*model_postgre.AchievementReference, error) {
+	var allowedFrom []model_postgre.AchievementStatus
+	for _, st := range []model_postgre.AchievementStatus{model_postgre.StatusDraft, model_postgre.StatusSubmitted, model_postgre.StatusRevisionRequested, model_postgre.StatusVerified, model_postgre.StatusRejected} {
+		if st == to || (st == model_postgre.StatusVerified && !allowFromVerified) { continue }
+		allowedFrom = append(allowedFrom, st)
+	}
+
+	args := []interface{}{to}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// Draft/submitted memulai siklus baru: catatan penolakan/revisi dan eskalasi siklus lama dibuang
+	const clearStale = ", rejection_note = NULL, revision_note = NULL, escalated_at = NULL, escalated_to = NULL, escalated_stage = NULL"
+	set := "status = $1, updated_at = NOW()"
+	switch to {
+	case model_postgre.StatusDraft:
+		set += ", submitted_at = NULL, verified_at = NULL, verified_by = NULL, workflow_code = NULL, current_stage = NULL, stage_entered_at = NULL" + clearStale
+	case model_postgre.StatusSubmitted:
+		set += ", submitted_at = NOW(), verified_at = NULL, verified_by = NULL, workflow_code = " + arg(workflowCode) + ", current_stage = " + arg(firstStage) + ", stage_entered_at = NOW()" + clearStale
+	case model_postgre.StatusRevisionRequested:
+		set += ", verified_at = NULL, verified_by = " + arg(actorID) + ", revision_note = " + arg(reason) + ", revision_count = revision_count + 1, current_stage = NULL, stage_entered_at = NULL"
+	case model_postgre.StatusVerified:
+		set += ", verified_at = NOW(), verified_by = " + arg(actorID)
+	case model_postgre.StatusRejected:
+		set += ", verified_at = NOW(), verified_by = " + arg(actorID) + ", rejection_note = " + arg(reason)
+	default:
+		return nil, &transitionError{kind: ErrInvalidTransition, msg: "status tujuan override tidak valid"}
+	}
+
+	placeholders := make([]string, len(allowedFrom))
+	for i, st := range allowedFrom {
+		placeholders[i] = arg(st)
+	}
+	query := `
+		UPDATE achievement_references SET ` + set + `
+		WHERE id = ` + arg(refID) + ` AND status IN (` + strings.Join(placeholders, ",") + `)
+		RETURNING ` + achievementColumns
+
+	return r.runTransition(refID, allowedFrom, query, args, actorID, &reason, "override gagal: prestasi tidak ditemukan, sudah berstatus tujuan, atau berstatus verified tanpa konfirmasi", true, "", "")
+}
+
+// SoftDeleteReference memindahkan referensi ke trash dan mencatat soft delete dokumen Mongo ke outbox
+func (r *achievementPGRepositoryImpl) SoftDeleteReference(refID string, studentID string, actorID string) (*model_postgre.AchievementReference, error) {
+	query := `
+		UPDATE achievement_references SET status = $1, updated_at = NOW(), deleted_at = NOW()
+		WHERE id = $2 AND student_id = $3 AND status = $4 AND co_member = FALSE
+		RETURNING `+achievementColumns+`
+	`
+	args := []interface{}{model_postgre.StatusDeleted, refID, studentID, model_postgre.StatusDraft}
+	return r.runTransition(refID, []model_postgre.AchievementStatus{model_postgre.StatusDraft}, query, args, actorID, nil, "gagal hapus: ID salah, bukan pemilik, atau status bukan draft", false, "", model_postgre.OutboxSoftDelete)
+}
+
+// RestoreReference mengembalikan prestasi dari trash ke Draft (satu-satunya status yang bisa dihapus).
+// studentID kosong = Admin (tanpa cek kepemilikan). Restore dokumen Mongo dicatat ke outbox.
+func (r *achievementPGRepositoryImpl) RestoreReference(refID string, studentID string, actorID string) (*model_postgre.AchievementReference, error) {
+	query := `
+		UPDATE achievement_references SET status = $1, updated_at = NOW(), deleted_at = NULL
+		WHERE id = $2 AND status = $3 AND co_member = FALSE AND ($4 = '' OR student_id::text = $4)
+		RETURNING `+achievementColumns+`
+	`
+	args := []interface{}{model_postgre.StatusDraft, refID, model_postgre.StatusDeleted, studentID}
+	return r.runTransition(refID, []model_postgre.AchievementStatus{model_postgre.StatusDeleted}, query, args, actorID, nil, "gagal restore: ID salah, bukan pemilik, atau prestasi tidak ada di trash", false, "", model_postgre.OutboxRestore)
+}
+
+// TrashReference memindahkan referensi berstatus apa pun ke trash tanpa menyentuh MongoDB (dipakai
+// rekonsiliasi bila dokumen tidak ada). Dicatat sebagai override; anggota tim ikut tersinkron.
+func (r *achievementPGRepositoryImpl) TrashReference(refID string, actorID string, note string) (*model_postgre.AchievementReference, error) {
+	allowedFrom := []model_postgre.AchievementStatus{model_postgre.StatusDraft, model_postgre.StatusSubmitted, model_postgre.StatusRevisionRequested, model_postgre.StatusVerified, model_postgre.StatusRejected}
+	query := `
+		UPDATE achievement_references SET status = $1, updated_at = NOW(), deleted_at = NOW()
+		WHERE id = $2 AND status != $1
+		RETURNING `+achievementColumns+`
+	`
+	args := []interface{}{model_postgre.StatusDeleted, refID}
+	return r.runTransition(refID, allowedFrom, query, args, actorID, &note, "referensi tidak ditemukan atau sudah di trash", true, "", "")
+}
+
+// GetDeletedReferences: Isi trash (tanpa referensi anggota tim tertaut). studentID kosong = semua.
+func (r *achievementPGRepositoryImpl) GetDeletedReferences(studentID string) ([]model_postgre.AchievementReference, error) {
+	query := `
+		SELECT `+achievementColumns+`
+		FROM achievement_references
+		WHERE status = $1 AND co_member = FALSE AND ($2 = '' OR student_id::text = $2)
+		ORDER BY deleted_at DESC
+	`
+	return r.queryReferences(query, model_postgre.StatusDeleted, studentID)
+}
+
+// GetPurgeableReferences: Referensi pembuat di trash yang dihapus sebelum batas retensi
+func (r *achievementPGRepositoryImpl) GetPurgeableReferences(deletedBefore time.Time) ([]model_postgre.AchievementReference, error) {
+	query := `
+		SELECT `+achievementColumns+`
+		FROM achievement_references
+		WHERE status = $1 AND co_member = FALSE AND deleted_at < $2
+		ORDER BY deleted_at
+	`
+	return r.queryReferences(query, model_postgre.StatusDeleted, deletedBefore)
 }
 
-func (r *achievementPGRepositoryImpl) GetMyAchievements(studentID string) ([]model_postgre.AchievementReference, error) {
+// PurgeReferences menghapus permanen semua referensi (termasuk anggota tim) untuk satu dokumen
+// Mongo yang sudah di trash. Riwayat status ikut terhapus lewat ON DELETE CASCADE; view read model
+// dihapus lewat event outbox.
+func (r *achievementPGRepositoryImpl) PurgeReferences(mongoAchievementID string) (int64, error) {
+	tx, err := r.DB.Begin()
+	if err != nil { return 0, err }
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM achievement_references WHERE mongo_achievement_id = $1 AND status = $2`, mongoAchievementID, model_postgre.StatusDeleted)
+	if err != nil { return 0, err }
+	purged, err := result.RowsAffected()
+	if err != nil { return 0, err }
+	if purged > 0 {
+		if err := insertOutboxEvent(tx, &model_postgre.OutboxEvent{AggregateID: mongoAchievementID, Operation: model_postgre.OutboxRefreshView}); err != nil {
+			return 0, fmt.Errorf("gagal mencatat outbox: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil { return 0, err }
+	return purged, nil
+}
+
+// GetReferencesByMongoID: Semua referensi satu dokumen Mongo termasuk yang di trash (sumber read model)
+func (r *achievementPGRepositoryImpl) GetReferencesByMongoID(mongoAchievementID string) ([]model_postgre.AchievementReference, error) {
 	query := `
-		SELECT id, student_id, mongo_achievement_id, status, submitted_at, verified_at, updated_at, verified_by, rejection_note, created_at
-		FROM achievement_references WHERE student_id = $1 AND status != $2
+		SELECT `+achievementColumns+`
+		FROM achievement_references WHERE mongo_achievement_id = $1
+		ORDER BY co_member, created_at
 	`
-	rows, err := r.DB.Query(query, studentID, model_postgre.StatusDeleted)
+	return r.queryReferences(query, mongoAchievementID)
+}
+
+func (r *achievementPGRepositoryImpl) queryReferences(query string, args ...interface{}) ([]model_postgre.AchievementReference, error) {
+	rows, err := r.DB.Query(query, args...)
 	if err != nil { return nil, err }
 	defer rows.Close()
-	
-	var list []model_postgre.AchievementReference
+
+	list := []model_postgre.AchievementReference{}
 	for rows.Next() {
 		ref, err := scanAchievementRow(rows.Scan)
 		if err != nil { return nil, err }
 		list = append(list, *ref)
 	}
-	return list, nil
+	return list, rows.Err()
+}
+
+func (r *achievementPGRepositoryImpl) GetStatusHistory(refID string) ([]model_postgre.AchievementStatusHistory, error) {
+	query := `
+		SELECT h.id, h.achievement_ref_id, h.from_status, h.to_status, h.actor_id, u.full_name, h.note, h.is_override,
+			h.on_behalf_of, ob.full_name, h.created_at
+		FROM achievement_status_history h
+		LEFT JOIN users u ON u.id = h.actor_id
+		LEFT JOIN users ob ON ob.id = h.on_behalf_of
+		WHERE h.achievement_ref_id = $1
+		ORDER BY h.created_at ASC, h.id ASC
+	`
+	rows, err := r.DB.Query(query, refID)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	list := []model_postgre.AchievementStatusHistory{}
+	for rows.Next() {
+		var h model_postgre.AchievementStatusHistory
+		err := rows.Scan(&h.ID, &h.AchievementRefID, &h.FromStatus, &h.ToStatus, &h.ActorID, &h.ActorName, &h.Note, &h.IsOverride,
+			&h.OnBehalfOf, &h.OnBehalfOfName, &h.CreatedAt)
+		if err != nil { return nil, err }
+		list = append(list, h)
+	}
+	return list, rows.Err()
 }
 
 func (r *achievementPGRepositoryImpl) GetAchievementsByStudentIDs(studentIDs []string) ([]model_postgre.AchievementReference, error) {
@@ -139,7 +632,7 @@ func (r *achievementPGRepositoryImpl) GetAchievementsByStudentIDs(studentIDs []s
 	args = append(args, model_postgre.StatusDraft, model_postgre.StatusDeleted)
 	
 	query := fmt.Sprintf(`
-		SELECT id, student_id, mongo_achievement_id, status, submitted_at, verified_at, updated_at, verified_by, rejection_note, created_at
+		SELECT `+achievementColumns+`
 		FROM achievement_references WHERE student_id IN (%s) AND status != $%d AND status != $%d
 	`, strings.Join(placeholders, ","), len(studentIDs)+1, len(studentIDs)+2)
 
@@ -158,7 +651,7 @@ func (r *achievementPGRepositoryImpl) GetAchievementsByStudentIDs(studentIDs []s
 
 func (r *achievementPGRepositoryImpl) GetAllAchievementReferences() ([]model_postgre.AchievementReference, error) {
 	query := `
-		SELECT id, student_id, mongo_achievement_id, status, submitted_at, verified_at, updated_at, verified_by, rejection_note, created_at
+		SELECT `+achievementColumns+`
 		FROM achievement_references WHERE status != $1
 	`
 	rows, err := r.DB.Query(query, model_postgre.StatusDeleted)
@@ -174,6 +667,11 @@ func (r *achievementPGRepositoryImpl) GetAllAchievementReferences() ([]model_pos
 	return list, nil
 }
 
+// GetAllReferencesWithDeleted: Semua referensi termasuk trash, untuk rekonsiliasi
+func (r *achievementPGRepositoryImpl) GetAllReferencesWithDeleted() ([]model_postgre.AchievementReference, error) {
+	return r.queryReferences(`SELECT ` + achievementColumns + ` FROM achievement_references ORDER BY created_at`)
+}
+
 func (r *achievementPGRepositoryImpl) FindStudentIdByUserID(userID string) (string, error) {
 	var sid string
 	err := r.DB.QueryRow("SELECT id FROM students WHERE user_id = $1", userID).Scan(&sid)
@@ -197,4 +695,26 @@ func (r *achievementPGRepositoryImpl) GetAdviseeStudentIDs(lecturerID string) ([
 		ids = append(ids, id)
 	}
 	return ids, nil
+}
+
+// GetDelegatedAdvisees: Mahasiswa bimbingan dosen yang saat ini (delegasi aktif, belum dicabut)
+// mendelegasikan verifikasi kepada lecturerID, beserta user ID dosen wali aslinya
+func (r *achievementPGRepositoryImpl) GetDelegatedAdvisees(lecturerID string) ([]model_postgre.DelegatedAdvisee, error) {
+	rows, err := r.DB.Query(`
+		SELECT s.id, l.user_id, d.id
+		FROM verification_delegations d
+		JOIN lecturers l ON l.id = d.delegator_id
+		JOIN students s ON s.advisor_id = d.delegator_id
+		WHERE d.delegate_id = $1 AND d.revoked_at IS NULL AND d.starts_at <= NOW() AND d.ends_at > NOW()
+	`, lecturerID)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	list := []model_postgre.DelegatedAdvisee{}
+	for rows.Next() {
+		var a model_postgre.DelegatedAdvisee
+		if err := rows.Scan(&a.StudentID, &a.DelegatorUserID, &a.DelegationID); err != nil { return nil, err }
+		list = append(list, a)
+	}
+	return list, rows.Err()
 }
\ No newline at end of file
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+)
+
+// ErrDelegationOverlap: Delegator sudah memiliki delegasi aktif yang bertumpang tindih
+var ErrDelegationOverlap = errors.New("delegasi bertumpang tindih dengan delegasi lain yang belum dicabut")
+
+type DelegationRepository interface {
+	CreateDelegation(req *model_postgre.DelegationRequest, actorID string) (*model_postgre.VerificationDelegation, error)
+	GetDelegationByID(id string) (*model_postgre.VerificationDelegation, error)
+	ListDelegations(lecturerID string) ([]model_postgre.VerificationDelegation, error)
+	RevokeDelegation(id string) (*model_postgre.VerificationDelegation, error)
+}
+
+type delegationRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewDelegationRepository(db *sql.DB) DelegationRepository {
+	return &delegationRepositoryImpl{DB: db}
+}
+
+const delegationSelect = `
+	SELECT d.id, d.delegator_id, COALESCE(lu.full_name, ''), d.delegate_id, COALESCE(du.full_name, ''),
+		d.starts_at, d.ends_at, d.reason, d.created_by, d.revoked_at, d.created_at
+	FROM verification_delegations d
+	LEFT JOIN lecturers l ON l.id = d.delegator_id
+	LEFT JOIN users lu ON lu.id = l.user_id
+	LEFT JOIN lecturers dl ON dl.id = d.delegate_id
+	LEFT JOIN users du ON du.id = dl.user_id`
+
+func scanDelegation(scan func(dest ...interface{}) error) (*model_postgre.VerificationDelegation, error) {
+	d := new(model_postgre.VerificationDelegation)
+	err := scan(&d.ID, &d.DelegatorID, &d.DelegatorName, &d.DelegateID, &d.DelegateName,
+		&d.StartsAt, &d.EndsAt, &d.Reason, &d.CreatedBy, &d.RevokedAt, &d.CreatedAt)
+	return d, err
+}
+
+// CreateDelegation menyimpan delegasi baru. Satu delegator hanya boleh punya satu delegasi
+// (belum dicabut) pada satu waktu; baris delegator dikunci agar cek tumpang tindih tidak balapan.
+func (r *delegationRepositoryImpl) CreateDelegation(req *model_postgre.DelegationRequest, actorID string) (*model_postgre.VerificationDelegation, error) {
+	tx, err := r.DB.Begin()
+	if err != nil { return nil, err }
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT id FROM lecturers WHERE id = $1 FOR UPDATE`, req.DelegatorID); err != nil { return nil, err }
+
+	var overlap bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM verification_delegations
+			WHERE delegator_id = $1 AND revoked_at IS NULL AND starts_at < $3 AND ends_at > $2
+		)
+	`, req.DelegatorID, req.StartsAt, req.EndsAt).Scan(&overlap)
+	if err != nil { return nil, err }
+	if overlap { return nil, ErrDelegationOverlap }
+
+	var reason, actor *string
+	if req.Reason != "" { reason = &req.Reason }
+	if actorID != "" { actor = &actorID }
+	var id string
+	err = tx.QueryRow(`
+		INSERT INTO verification_delegations (delegator_id, delegate_id, starts_at, ends_at, reason, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id
+	`, req.DelegatorID, req.DelegateID, req.StartsAt, req.EndsAt, reason, actor).Scan(&id)
+	if err != nil { return nil, err }
+
+	d, err := scanDelegation(tx.QueryRow(delegationSelect+` WHERE d.id = $1`, id).Scan)
+	if err != nil { return nil, err }
+	if err := tx.Commit(); err != nil { return nil, err }
+	return d, nil
+}
+
+func (r *delegationRepositoryImpl) GetDelegationByID(id string) (*model_postgre.VerificationDelegation, error) {
+	return scanDelegation(r.DB.QueryRow(delegationSelect+` WHERE d.id = $1`, id).Scan)
+}
+
+// ListDelegations: Delegasi di mana lecturerID menjadi delegator atau delegate. lecturerID kosong = semua.
+func (r *delegationRepositoryImpl) ListDelegations(lecturerID string) ([]model_postgre.VerificationDelegation, error) {
+	rows, err := r.DB.Query(delegationSelect+`
+		WHERE $1 = '' OR d.delegator_id::text = $1 OR d.delegate_id::text = $1
+		ORDER BY d.starts_at DESC
+	`, lecturerID)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	list := []model_postgre.VerificationDelegation{}
+	for rows.Next() {
+		d, err := scanDelegation(rows.Scan)
+		if err != nil { return nil, err }
+		list = append(list, *d)
+	}
+	return list, rows.Err()
+}
+
+// RevokeDelegation mengakhiri delegasi lebih awal. Delegasi yang sudah dicabut menghasilkan sql.ErrNoRows.
+func (r *delegationRepositoryImpl) RevokeDelegation(id string) (*model_postgre.VerificationDelegation, error) {
+	var revokedID string
+	err := r.DB.QueryRow(`
+		UPDATE verification_delegations SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL RETURNING id
+	`, id).Scan(&revokedID)
+	if err != nil { return nil, err }
+	return r.GetDelegationByID(revokedID)
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+)
+
+type EscalationRepository interface {
+	GetOverdueReferences(stageEnteredBefore time.Time, lecturerID string) ([]model_postgre.OverdueAchievement, error)
+	Escalate(refID string, fallbackUserID string, slaDays int, stageEnteredBefore time.Time) (*model_postgre.AchievementEscalation, error)
+}
+
+type escalationRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewEscalationRepository(db *sql.DB) EscalationRepository {
+	return &escalationRepositoryImpl{DB: db}
+}
+
+// GetOverdueReferences: Prestasi Submitted (referensi pembuat saja, tanpa anggota tim tertaut)
+// yang masuk tahap verifikasi aktif sebelum batas SLA, terurut dari yang paling lama tertahan.
+// lecturerID kosong = semua dosen.
+func (r *escalationRepositoryImpl) GetOverdueReferences(stageEnteredBefore time.Time, lecturerID string) ([]model_postgre.OverdueAchievement, error) {
+	query := `
+		SELECT ar.id, ar.student_id, COALESCE(su.full_name, ''), ar.mongo_achievement_id, ar.current_stage, ar.submitted_at,
+			ar.stage_entered_at, s.advisor_id, lu.full_name, ar.escalated_at, ar.escalated_to
+		FROM achievement_references ar
+		LEFT JOIN students s ON s.id = ar.student_id
+		LEFT JOIN users su ON su.id = s.user_id
+		LEFT JOIN lecturers l ON l.id = s.advisor_id
+		LEFT JOIN users lu ON lu.id = l.user_id
+		WHERE ar.status = $1 AND ar.co_member = FALSE AND ar.stage_entered_at < $2
+			AND ($3 = '' OR s.advisor_id::text = $3)
+		ORDER BY ar.stage_entered_at
+	`
+	rows, err := r.DB.Query(query, model_postgre.StatusSubmitted, stageEnteredBefore, lecturerID)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	list := []model_postgre.OverdueAchievement{}
+	for rows.Next() {
+		var item model_postgre.OverdueAchievement
+		err := rows.Scan(&item.ID, &item.StudentID, &item.StudentName, &item.MongoAchievementID, &item.CurrentStage, &item.SubmittedAt,
+			&item.StageEnteredAt, &item.AdvisorID, &item.AdvisorName, &item.EscalatedAt, &item.EscalatedTo)
+		if err != nil { return nil, err }
+		list = append(list, item)
+	}
+	return list, rows.Err()
+}
+
+// Escalate menandai prestasi (beserta referensi anggota tim) sebagai tereskalasi ke verifikator
+// cadangan untuk tahap verifikasi yang sedang berjalan, lalu mencatatnya di achievement_escalations.
+// Kondisi overdue dicek ulang setelah baris dikunci sehingga prestasi yang baru saja diproses
+// verifikator atau sudah dieskalasi pada tahap yang sama dilewati (sql.ErrNoRows). User
+// cadangan yang tidak ada/nonaktif diganti NULL (eskalasi ke semua Admin).
+func (r *escalationRepositoryImpl) Escalate(refID string, fallbackUserID string, slaDays int, stageEnteredBefore time.Time) (*model_postgre.AchievementEscalation, error) {
+	tx, err := r.DB.Begin()
+	if err != nil { return nil, err }
+	defer tx.Rollback()
+
+	// Urutan kunci sama dengan transisi status (seluruh grup dokumen Mongo, ORDER BY id)
+	_, err = tx.Exec(`
+		SELECT id FROM achievement_references
+		WHERE mongo_achievement_id = (SELECT mongo_achievement_id FROM achievement_references WHERE id = $1)
+		ORDER BY id FOR UPDATE
+	`, refID)
+	if err != nil { return nil, err }
+
+	var esc model_postgre.AchievementEscalation
+	err = tx.QueryRow(`
+		WITH target AS (
+			SELECT ar.id, ar.mongo_achievement_id, ar.submitted_at, ar.stage_entered_at, ar.current_stage, s.advisor_id
+			FROM achievement_references ar
+			LEFT JOIN students s ON s.id = ar.student_id
+			WHERE ar.id = $1 AND ar.status = $2 AND ar.stage_entered_at < $3
+				AND (ar.escalated_at IS NULL OR ar.escalated_at < ar.stage_entered_at)
+		), fallback AS (
+			SELECT id FROM users WHERE id::text = $4 AND is_active = TRUE
+		), marked AS (
+			UPDATE achievement_references a
+			SET escalated_at = NOW(), escalated_to = (SELECT id FROM fallback), escalated_stage = t.current_stage
+			FROM target t
+			WHERE a.mongo_achievement_id = t.mongo_achievement_id
+		)
+		INSERT INTO achievement_escalations (achievement_ref_id, advisor_id, escalated_to, submitted_at, stage_entered_at, sla_days)
+		SELECT t.id, t.advisor_id, (SELECT id FROM fallback), t.submitted_at, t.stage_entered_at, $5 FROM target t
+		RETURNING id, achievement_ref_id, advisor_id, escalated_to, submitted_at, stage_entered_at, sla_days, created_at
+	`, refID, model_postgre.StatusSubmitted, stageEnteredBefore, fallbackUserID, slaDays).Scan(
+		&esc.ID, &esc.AchievementRefID, &esc.AdvisorID, &esc.EscalatedTo, &esc.SubmittedAt, &esc.StageEnteredAt, &esc.SLADays, &esc.CreatedAt,
+	)
+	if err != nil { return nil, err }
+
+	if err := tx.Commit(); err != nil { return nil, err }
+	return &esc, nil
+}
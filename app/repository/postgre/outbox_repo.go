@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+)
+
+type OutboxRepository interface {
+	Enqueue(event *model_postgre.OutboxEvent) error
+	ClaimDue(aggregateID string, lease time.Duration, limit int) ([]model_postgre.OutboxEvent, error)
+	MarkProcessed(id int64) error
+	MarkFailed(id int64, errMsg string, retryIn time.Duration) error
+	CountPending(aggregateID string) (int, error)
+	PendingAggregateIDs() ([]string, error)
+}
+
+type outboxRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepositoryImpl{DB: db}
+}
+
+const outboxColumns = `id, aggregate_id, operation, payload, attempts, last_error, next_attempt_at, processed_at, created_at`
+
+// insertOutboxEvent dipanggil di dalam transaksi perubahan referensi agar niat penulisan ke Mongo
+// ikut commit (atau ikut batal) bersama data PostgreSQL
+func insertOutboxEvent(tx *sql.Tx, event *model_postgre.OutboxEvent) error {
+	payload := event.Payload
+	if payload == "" { payload = "{}" }
+	return tx.QueryRow(`
+		INSERT INTO achievement_outbox (aggregate_id, operation, payload)
+		VALUES ($1, $2, $3)
+		RETURNING id, next_attempt_at, created_at
+	`, event.AggregateID, event.Operation, payload).Scan(&event.ID, &event.NextAttemptAt, &event.CreatedAt)
+}
+
+// Enqueue mencatat event di luar transaksi referensi, untuk perubahan yang hanya menyentuh MongoDB
+// (mis. edit konten) namun tetap harus diproyeksikan ke read model
+func (r *outboxRepositoryImpl) Enqueue(event *model_postgre.OutboxEvent) error {
+	tx, err := r.DB.Begin()
+	if err != nil { return err }
+	defer tx.Rollback()
+	if err := insertOutboxEvent(tx, event); err != nil { return err }
+	return tx.Commit()
+}
+
+// ClaimDue mengunci (lease) event yang jatuh tempo. Hanya event tertua yang belum selesai per
+// aggregate yang bisa diambil sehingga urutan operasi per dokumen selalu terjaga.
+// aggregateID kosong = semua dokumen.
+func (r *outboxRepositoryImpl) ClaimDue(aggregateID string, lease time.Duration, limit int) ([]model_postgre.OutboxEvent, error) {
+	rows, err := r.DB.Query(`
+		UPDATE achievement_outbox SET locked_until = NOW() + make_interval(secs => $1)
+		WHERE id IN (
+			SELECT o.id FROM achievement_outbox o
+			WHERE o.processed_at IS NULL AND o.next_attempt_at <= NOW()
+				AND (o.locked_until IS NULL OR o.locked_until < NOW())
+				AND ($2 = '' OR o.aggregate_id = $2)
+				AND NOT EXISTS (
+					SELECT 1 FROM achievement_outbox p
+					WHERE p.aggregate_id = o.aggregate_id AND p.processed_at IS NULL AND p.id < o.id
+				)
+			ORDER BY o.id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+outboxColumns, lease.Seconds(), aggregateID, limit)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	events := []model_postgre.OutboxEvent{}
+	for rows.Next() {
+		var e model_postgre.OutboxEvent
+		if err := rows.Scan(&e.ID, &e.AggregateID, &e.Operation, &e.Payload, &e.Attempts, &e.LastError, &e.NextAttemptAt, &e.ProcessedAt, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *outboxRepositoryImpl) MarkProcessed(id int64) error {
+	_, err := r.DB.Exec(`
+		UPDATE achievement_outbox SET processed_at = NOW(), attempts = attempts + 1, locked_until = NULL, last_error = NULL
+		WHERE id = $1
+	`, id)
+	return err
+}
+
+// MarkFailed mencatat kegagalan dan menjadwalkan percobaan berikutnya setelah retryIn
+func (r *outboxRepositoryImpl) MarkFailed(id int64, errMsg string, retryIn time.Duration) error {
+	_, err := r.DB.Exec(`
+		UPDATE achievement_outbox
+		SET attempts = attempts + 1, last_error = $2, locked_until = NULL, next_attempt_at = NOW() + make_interval(secs => $3)
+		WHERE id = $1
+	`, id, errMsg, retryIn.Seconds())
+	return err
+}
+
+// CountPending: Jumlah event yang belum berhasil diterapkan. aggregateID kosong = semua dokumen.
+func (r *outboxRepositoryImpl) CountPending(aggregateID string) (int, error) {
+	var n int
+	err := r.DB.QueryRow(`SELECT COUNT(*) FROM achievement_outbox WHERE processed_at IS NULL AND ($1 = '' OR aggregate_id = $1)`, aggregateID).Scan(&n)
+	return n, err
+}
+
+// PendingAggregateIDs: Dokumen Mongo yang masih memiliki event belum diterapkan
+func (r *outboxRepositoryImpl) PendingAggregateIDs() ([]string, error) {
+	rows, err := r.DB.Query(`SELECT DISTINCT aggregate_id FROM achievement_outbox WHERE processed_at IS NULL`)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil { return nil, err }
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+)
+
+type PointRuleRepository interface {
+	GetAllRules() ([]model_postgre.PointRule, error)
+	GetRuleByID(id string) (*model_postgre.PointRule, error)
+	CreateRule(input *model_postgre.PointRuleInput) (*model_postgre.PointRule, error)
+	UpdateRule(id string, input *model_postgre.PointRuleInput) (*model_postgre.PointRule, error)
+	DeleteRule(id string) error
+}
+
+type pointRuleRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewPointRuleRepository(db *sql.DB) PointRuleRepository {
+	return &pointRuleRepositoryImpl{DB: db}
+}
+
+const pointRuleColumns = `id, achievement_type, level, rank, points, team_percent, created_at, updated_at`
+
+func scanPointRule(scan func(dest ...interface{}) error) (*model_postgre.PointRule, error) {
+	rule := new(model_postgre.PointRule)
+	err := scan(&rule.ID, &rule.AchievementType, &rule.Level, &rule.Rank, &rule.Points, &rule.TeamPercent, &rule.CreatedAt, &rule.UpdatedAt)
+	return rule, err
+}
+
+func teamPercentOrDefault(input *model_postgre.PointRuleInput) int {
+	if input.TeamPercent == nil { return 100 }
+	return *input.TeamPercent
+}
+
+func (r *pointRuleRepositoryImpl) GetAllRules() ([]model_postgre.PointRule, error) {
+	query := `SELECT ` + pointRuleColumns + ` FROM point_rules ORDER BY achievement_type, level NULLS LAST, rank NULLS LAST`
+	rows, err := r.DB.Query(query)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	rules := []model_postgre.PointRule{}
+	for rows.Next() {
+		rule, err := scanPointRule(rows.Scan)
+		if err != nil { return nil, err }
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *pointRuleRepositoryImpl) GetRuleByID(id string) (*model_postgre.PointRule, error) {
+	query := `SELECT ` + pointRuleColumns + ` FROM point_rules WHERE id = $1`
+	return scanPointRule(r.DB.QueryRow(query, id).Scan)
+}
+
+func (r *pointRuleRepositoryImpl) CreateRule(input *model_postgre.PointRuleInput) (*model_postgre.PointRule, error) {
+	query := `
+		INSERT INTO point_rules (achievement_type, level, rank, points, team_percent)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + pointRuleColumns
+	return scanPointRule(r.DB.QueryRow(query, input.AchievementType, input.Level, input.Rank, input.Points, teamPercentOrDefault(input)).Scan)
+}
+
+func (r *pointRuleRepositoryImpl) UpdateRule(id string, input *model_postgre.PointRuleInput) (*model_postgre.PointRule, error) {
+	query := `
+		UPDATE point_rules
+		SET achievement_type = $1, level = $2, rank = $3, points = $4, team_percent = $5, updated_at = NOW()
+		WHERE id = $6
+		RETURNING ` + pointRuleColumns
+	return scanPointRule(r.DB.QueryRow(query, input.AchievementType, input.Level, input.Rank, input.Points, teamPercentOrDefault(input), id).Scan)
+}
+
+func (r *pointRuleRepositoryImpl) DeleteRule(id string) error {
+	result, err := r.DB.Exec(`DELETE FROM point_rules WHERE id = $1`, id)
+	if err != nil { return err }
+	affected, _ := result.RowsAffected()
+	if affected == 0 { return sql.ErrNoRows }
+	return nil
+}
@@ -3,7 +3,6 @@ package repository
 import (
 	"database/sql"
 	"errors"
-	"fmt"
 
 	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
 )
@@ -11,7 +10,6 @@ import (
 type ReportPGRepository interface {
 	FindStudentIdByUserID(userID string) (string, error)
 	FindStudentProfile(studentID string) (*model_postgre.Student, error)
-	GetStudentAchievementReferences(studentID string) ([]model_postgre.AchievementReference, error)
 	FindLecturerIdByUserID(userID string) (string, error)
     GetAdviseeStudentIDs(lecturerID string) ([]string, error)
 }
@@ -64,39 +62,6 @@ func (r *reportPGRepositoryImpl) FindStudentProfile(studentID string) (*model_po
 	return profile, nil
 }
 
-func (r *reportPGRepositoryImpl) GetStudentAchievementReferences(studentID string) ([]model_postgre.AchievementReference, error) {
-	query := `
-		SELECT 
-			id, student_id, mongo_achievement_id, status, submitted_at, verified_at, updated_at, 
-			verified_by, rejection_note, created_at
-		FROM achievement_references
-		WHERE student_id = $1 AND status != $2
-	`
-	rows, err := r.DB.Query(query, studentID, model_postgre.StatusDeleted)
-	if err != nil {
-		return nil, fmt.Errorf("SQL error in report repo: %w", err)
-	}
-	defer rows.Close()
-
-	var references []model_postgre.AchievementReference
-	for rows.Next() {
-		var ref model_postgre.AchievementReference
-		
-		err := rows.Scan(
-			&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status,
-			&ref.SubmittedAt, &ref.VerifiedAt, &ref.UpdatedAt,
-			&ref.VerifiedBy, &ref.RejectionNote,
-			&ref.CreatedAt,
-		)
-		
-		if err != nil {
-			return nil, fmt.Errorf("error scanning row in GetStudentAchievementReferences: %w", err)
-		}
-		references = append(references, ref)
-	}
-	return references, nil
-}
-
 func (r *reportPGRepositoryImpl) FindLecturerIdByUserID(userID string) (string, error) {
     var lecturerID string
     query := `SELECT id FROM lecturers WHERE user_id = $1`
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+)
+
+type SettingRepository interface {
+	ListSettings() ([]model_postgre.SystemSetting, error)
+	GetSetting(key string) (*model_postgre.SystemSetting, error)
+	UpsertSetting(key string, value string, actorID string) (*model_postgre.SystemSetting, error)
+	GetIntSetting(key string, fallback int) (int, error)
+}
+
+type settingRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewSettingRepository(db *sql.DB) SettingRepository {
+	return &settingRepositoryImpl{DB: db}
+}
+
+func (r *settingRepositoryImpl) ListSettings() ([]model_postgre.SystemSetting, error) {
+	rows, err := r.DB.Query(`SELECT key, value, updated_by, updated_at FROM system_settings ORDER BY key`)
+	if err != nil { return nil, err }
+	defer rows.Close()
+
+	settings := []model_postgre.SystemSetting{}
+	for rows.Next() {
+		var s model_postgre.SystemSetting
+		if err := rows.Scan(&s.Key, &s.Value, &s.UpdatedBy, &s.UpdatedAt); err != nil { return nil, err }
+		settings = append(settings, s)
+	}
+	return settings, rows.Err()
+}
+
+func (r *settingRepositoryImpl) GetSetting(key string) (*model_postgre.SystemSetting, error) {
+	var s model_postgre.SystemSetting
+	err := r.DB.QueryRow(`SELECT key, value, updated_by, updated_at FROM system_settings WHERE key = $1`, key).
+		Scan(&s.Key, &s.Value, &s.UpdatedBy, &s.UpdatedAt)
+	if err != nil { return nil, err }
+	return &s, nil
+}
+
+func (r *settingRepositoryImpl) UpsertSetting(key string, value string, actorID string) (*model_postgre.SystemSetting, error) {
+	var actor *string
+	if actorID != "" { actor = &actorID }
+	var s model_postgre.SystemSetting
+	err := r.DB.QueryRow(`
+		INSERT INTO system_settings (key, value, updated_by, updated_at) VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING key, value, updated_by, updated_at
+	`, key, value, actor).Scan(&s.Key, &s.Value, &s.UpdatedBy, &s.UpdatedAt)
+	if err != nil { return nil, err }
+	return &s, nil
+}
+
+// GetIntSetting: Nilai integer sebuah pengaturan; fallback bila belum ada atau bukan angka
+func (r *settingRepositoryImpl) GetIntSetting(key string, fallback int) (int, error) {
+	setting, err := r.GetSetting(key)
+	if errors.Is(err, sql.ErrNoRows) { return fallback, nil }
+	if err != nil { return fallback, err }
+	value, err := strconv.Atoi(setting.Value)
+	if err != nil { return fallback, nil }
+	return value, nil
+}
@@ -0,0 +1,89 @@
+package revision
+
+import (
+	"reflect"
+	"sort"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Snapshot: Membuat revisi dari konten dokumen prestasi saat ini
+func Snapshot(doc *model_mongo.AchievementMongo, editedBy string) *model_mongo.AchievementRevision {
+	return &model_mongo.AchievementRevision{
+		AchievementID:   doc.ID,
+		AchievementType: doc.AchievementType,
+		Title:           doc.Title,
+		Description:     doc.Description,
+		Details:         doc.Details,
+		Tags:            doc.Tags,
+		Points:          doc.Points,
+		EditedBy:        editedBy,
+	}
+}
+
+// Diff membandingkan dua revisi field per field: achievementType, title, description,
+// setiap key di details (terurut), lalu tags. Field yang sama tidak dikembalikan.
+func Diff(from, to *model_mongo.AchievementRevision) []model_mongo.RevisionChange {
+	changes := []model_mongo.RevisionChange{}
+	add := func(field string, before, after interface{}) {
+		if !equal(before, after) {
+			changes = append(changes, model_mongo.RevisionChange{Field: field, Before: before, After: after})
+		}
+	}
+
+	add("achievementType", from.AchievementType, to.AchievementType)
+	add("title", from.Title, to.Title)
+	add("description", from.Description, to.Description)
+
+	keys := map[string]bool{}
+	for k := range from.Details { keys[k] = true }
+	for k := range to.Details { keys[k] = true }
+	sorted := make([]string, 0, len(keys))
+	for k := range keys { sorted = append(sorted, k) }
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		add("details."+k, from.Details[k], to.Details[k])
+	}
+
+	add("tags", nonNilTags(from.Tags), nonNilTags(to.Tags))
+	return changes
+}
+
+// equal menyamakan tipe hasil decode BSON (int32/int64/primitive.A) sebelum dibandingkan
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case int32:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case int:
+		return float64(t)
+	case primitive.A:
+		return normalize([]interface{}(t))
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t { out[i] = normalize(item) }
+		return out
+	case []string:
+		out := make([]interface{}, len(t))
+		for i, item := range t { out[i] = item }
+		return out
+	case primitive.M:
+		return normalize(map[string]interface{}(t))
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, item := range t { out[k] = normalize(item) }
+		return out
+	}
+	return v
+}
+
+func nonNilTags(tags []string) []string {
+	if tags == nil { return []string{} }
+	return tags
+}
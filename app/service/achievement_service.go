@@ -2,176 +2,270 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/safrizal-hk/uas-gofiber/app/achievementtype"
 	modelMongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
 	modelPostgres "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/outbox"
+	"github.com/safrizal-hk/uas-gofiber/app/points"
+	"github.com/safrizal-hk/uas-gofiber/app/policy"
+	"github.com/safrizal-hk/uas-gofiber/app/readmodel"
+	"github.com/safrizal-hk/uas-gofiber/app/revision"
+	"github.com/safrizal-hk/uas-gofiber/app/storage"
+	"github.com/safrizal-hk/uas-gofiber/app/upload"
 	repoMongo "github.com/safrizal-hk/uas-gofiber/app/repository/mongo"
 	repoPostgres "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/workflow"
 	"github.com/safrizal-hk/uas-gofiber/middleware"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type AchievementService struct {
-	MongoRepo repoMongo.AchievementMongoRepository
-	PgRepo    repoPostgres.AchievementPGRepository
+	MongoRepo    repoMongo.AchievementMongoRepository
+	RevisionRepo repoMongo.AchievementRevisionRepository
+	CommentRepo  repoMongo.AchievementCommentRepository
+	PgRepo       repoPostgres.AchievementPGRepository
+	PointRepo    repoPostgres.PointRuleRepository
+	SettingRepo  repoPostgres.SettingRepository
+	ViewRepo     repoMongo.AchievementViewRepository
+	Storage      storage.Storage
+	Uploads      *upload.Validator
+	Outbox       *outbox.Dispatcher
+	Workflow     *workflow.Engine
+	Policy       *policy.Policy
+	Types        *achievementtype.Registry
 }
 
-func NewAchievementService(mongoRepo repoMongo.AchievementMongoRepository, pgRepo repoPostgres.AchievementPGRepository) *AchievementService {
+func NewAchievementService(mongoRepo repoMongo.AchievementMongoRepository, pgRepo repoPostgres.AchievementPGRepository, pointRepo repoPostgres.PointRuleRepository, revisionRepo repoMongo.AchievementRevisionRepository, commentRepo repoMongo.AchievementCommentRepository, settingRepo repoPostgres.SettingRepository, outboxRepo repoPostgres.OutboxRepository, viewRepo repoMongo.AchievementViewRepository, store storage.Storage, uploads *upload.Validator, workflowEngine *workflow.Engine) *AchievementService {
 	return &AchievementService{
-		MongoRepo: mongoRepo,
-		PgRepo:    pgRepo,
+		MongoRepo:    mongoRepo,
+		RevisionRepo: revisionRepo,
+		CommentRepo:  commentRepo,
+		PgRepo:       pgRepo,
+		PointRepo:    pointRepo,
+		SettingRepo:  settingRepo,
+		ViewRepo:     viewRepo,
+		Storage:      store,
+		Uploads:      uploads,
+		Outbox:       outbox.NewDispatcher(outboxRepo, mongoRepo, readmodel.NewProjector(pgRepo, mongoRepo, viewRepo)),
+		Workflow:     workflowEngine,
+		Policy:    policy.New(pgRepo).WithDelegations(pgRepo),
+		Types:     achievementtype.NewRegistry(achievementtype.DefaultTypes()),
 	}
 }
 
 // ListAllAchievements godoc
 // @Summary      List Data Prestasi
-// @Description  Melihat daftar prestasi yang difilter otomatis berdasarkan Role (Admin=All, Dosen=Bimbingan, Mahasiswa=Milik Sendiri)
+// @Description  Melihat daftar prestasi yang difilter otomatis berdasarkan Role (Admin=All, Dosen=Bimbingan, Mahasiswa=Milik Sendiri). Dosen penerima delegasi aktif juga melihat antrean Submitted bimbingan delegator (ditandai on_behalf_of). Mendukung paginasi halaman (page/limit) atau cursor (next_cursor dari respons sebelumnya; hanya untuk sort created_at/updated_at). Dibaca dari read model achievement_views.
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        page             query     int     false  "Nomor halaman (default 1)"
+// @Param        limit            query     int     false  "Jumlah per halaman (default 20, maks. 100)"
+// @Param        cursor           query     string  false  "Cursor halaman berikutnya (menggantikan page)"
+// @Param        status           query     string  false  "Status, pisahkan dengan koma"
+// @Param        achievementType  query     string  false  "Tipe prestasi"
+// @Param        tags             query     string  false  "Tag, pisahkan dengan koma (harus memiliki semua)"
+// @Param        studentId        query     string  false  "Student ID (UUID)"
+// @Param        dateField        query     string  false  "Kolom rentang tanggal: created_at (default), updated_at, submitted_at, verified_at"
+// @Param        dateFrom         query     string  false  "Tanggal awal (YYYY-MM-DD)"
+// @Param        dateTo           query     string  false  "Tanggal akhir, inklusif (YYYY-MM-DD)"
+// @Param        minPoints        query     int     false  "Poin minimal"
+// @Param        maxPoints        query     int     false  "Poin maksimal"
+// @Param        sort             query     string  false  "created_at (default), updated_at, submitted_at, verified_at"
+// @Param        order            query     string  false  "asc atau desc (default)"
 // @Success      200  {object}  map[string]interface{} "Data Prestasi"
+// @Failure      400  {object}  map[string]interface{} "Parameter tidak valid"
 // @Failure      403  {object}  map[string]interface{} "Forbidden"
 // @Failure      500  {object}  map[string]interface{} "Internal Server Error"
 // @Router       /achievements [get]
 func (s *AchievementService) ListAllAchievements(c *fiber.Ctx) error {
 	profile := middleware.GetUserProfileFromContext(c)
-	var references []modelPostgres.AchievementReference
-	var err error
-
-	switch profile.Role {
-	case "Admin":
-		references, err = s.PgRepo.GetAllAchievementReferences()
-	case "Mahasiswa":
-		studentID, errLookup := s.PgRepo.FindStudentIdByUserID(profile.ID)
-		if errLookup != nil || studentID == "" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Akun tidak terhubung ke data Mahasiswa.", "code": "403"})
-		}
-		references, err = s.PgRepo.GetMyAchievements(studentID)
-	case "Dosen Wali":
-		lecturerID, errLookup := s.PgRepo.FindLecturerIdByUserID(profile.ID)
-		if errLookup != nil || lecturerID == "" {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Akun tidak terhubung ke data Dosen Wali.", "code": "403"})
-		}
-		studentIDs, errLookup := s.PgRepo.GetAdviseeStudentIDs(lecturerID)
-		if errLookup != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil daftar mahasiswa bimbingan.", "code": "500"})
-		}
-		if len(studentIDs) > 0 {
-			references, err = s.PgRepo.GetAchievementsByStudentIDs(studentIDs)
-		} else {
-			references = []modelPostgres.AchievementReference{}
-		}
+	scope, errPolicy := s.Policy.ScopeFor(profile)
+	if errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
 
-	default:
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Role tidak dikenal.", "code": "403"})
+	filter, page, errQuery := parseListQuery(c)
+	if errQuery != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": errQuery.Error(), "code": "400"})
 	}
 
+	applyListScope(&filter, scope)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	views, total, err := s.ViewRepo.List(ctx, filter)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil data prestasi.", "code": "500"})
 	}
-
-	var mongoIDs []primitive.ObjectID
-	for _, ref := range references {
-		if oid, err := primitive.ObjectIDFromHex(ref.MongoAchievementID); err == nil {
-			mongoIDs = append(mongoIDs, oid)
-		}
+	hasNext := len(views) > filter.Limit
+	if hasNext {
+		views = views[:filter.Limit]
 	}
 
-	details, err := s.MongoRepo.GetDetailsByIDs(context.Background(), mongoIDs)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil detail dari MongoDB.", "code": "500"})
+	finalData := make([]fiber.Map, 0, len(views))
+	for _, view := range views {
+		finalData = append(finalData, viewListItem(view, scope))
 	}
 
-	mongoDetailMap := make(map[string]modelMongo.AchievementMongo)
-	for _, detail := range details {
-		mongoDetailMap[detail.ID.Hex()] = detail
+	pagination := fiber.Map{
+		"limit":       filter.Limit,
+		"total":       total,
+		"total_pages": (total + filter.Limit - 1) / filter.Limit,
+		"has_next":    hasNext,
 	}
-
-	var finalData []fiber.Map
-	for _, ref := range references {
-		if detail, ok := mongoDetailMap[ref.MongoAchievementID]; ok {
-			combined := fiber.Map{
-				"id":              ref.ID,
-				"student_id":      ref.StudentID,
-				"status":          ref.Status,
-				"submitted_at":    ref.SubmittedAt,
-				"verified_at":     ref.VerifiedAt,
-				"verified_by":     ref.VerifiedBy,
-				"rejection_note":  ref.RejectionNote,
-				"title":           detail.Title,
-				"achievementType": detail.AchievementType,
-				"description":     detail.Description,
-				"points":          detail.Points,
-				"tags":            detail.Tags,
-				"details":         detail.Details,
-				"attachments":     detail.Attachments,
-				"created_at":      ref.CreatedAt,
-			}
-			finalData = append(finalData, combined)
-		}
+	if filter.CursorValue == nil {
+		pagination["page"] = page
+		if hasNext { pagination["next_page"] = page + 1 }
+	}
+	if hasNext && containsString(modelMongo.CursorSortFields, filter.Sort) {
+		pagination["next_cursor"] = encodeListCursor(views[len(views)-1], filter.Sort)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"status": "success",
-		"total":  len(finalData),
-		"data":   finalData,
+		"status":     "success",
+		"total":      total,
+		"data":       finalData,
+		"pagination": pagination,
 	})
 }
 
+// SearchAchievements godoc
+// @Summary      Cari Prestasi (Full-text)
+// @Description  Pencarian teks pada judul, deskripsi dan tag prestasi, diurutkan berdasarkan relevansi (score). Cakupan data sama dengan List Data Prestasi sesuai Role; dibaca dari read model achievement_views.
+// @Tags         Achievements
+// @Produce      json
+// @Security     BearerAuth
+// @Param        q      query     string  true   "Kata kunci"
+// @Param        limit  query     int     false  "Jumlah hasil (default 20, maks. 100)"
+// @Success      200  {object}  map[string]interface{} "Hasil Pencarian"
+// @Failure      400  {object}  map[string]interface{} "Parameter tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Forbidden"
+// @Failure      500  {object}  map[string]interface{} "Internal Server Error"
+// @Router       /achievements/search [get]
+func (s *AchievementService) SearchAchievements(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	scope, errPolicy := s.Policy.ScopeFor(profile)
+	if errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Parameter q wajib diisi", "code": "400"})
+	}
+	if len([]rune(q)) > modelMongo.MaxSearchQueryLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": fmt.Sprintf("Parameter q maksimal %d karakter", modelMongo.MaxSearchQueryLength), "code": "400"})
+	}
+	limit := c.QueryInt("limit", modelMongo.DefaultSearchLimit)
+	if limit < 1 || limit > modelMongo.MaxSearchLimit {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": fmt.Sprintf("limit harus di antara 1 dan %d", modelMongo.MaxSearchLimit), "code": "400"})
+	}
+
+	if !scope.All && len(scope.StudentIDs) == 0 {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "total": 0, "data": []fiber.Map{}})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Aturan scope sama seperti daftar prestasi (draft, delegasi, terhapus)
+	var filter modelMongo.AchievementViewFilter
+	applyListScope(&filter, scope)
+	hits, err := s.ViewRepo.Search(ctx, q, filter, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menjalankan pencarian di MongoDB.", "code": "500"})
+	}
+
+	results := make([]fiber.Map, 0, len(hits))
+	for _, hit := range hits {
+		item := viewListItem(hit.AchievementView, scope)
+		item["score"] = hit.Score
+		results = append(results, item)
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "total": len(results), "data": results})
+}
+
 // GetAchievementDetail godoc
 // @Summary      Detail Prestasi
-// @Description  Melihat detail lengkap satu prestasi (Gabungan Postgres & Mongo)
+// @Description  Melihat detail lengkap satu prestasi (Gabungan Postgres & Mongo) beserta thread komentar
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Achievement ID (Postgres UUID)"
 // @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{} "Bukan pemilik/dosen wali"
 // @Failure      404  {object}  map[string]interface{} "Not Found"
 // @Failure      500  {object}  map[string]interface{} "Internal Error"
 // @Router       /achievements/{id} [get]
 func (s *AchievementService) GetAchievementDetail(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
 	achievementID := c.Params("id")
 
 	ref, err := s.PgRepo.GetReferenceByID(achievementID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
 	}
+	if errPolicy := s.requireReadAccess(profile, ref); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
 
 	mongoID, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
 	detail, err := s.MongoRepo.GetDetailByID(context.Background(), mongoID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil detail data", "code": "500"})
 	}
+	comments, err := s.CommentRepo.ListByAchievement(context.Background(), mongoID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil komentar", "code": "500"})
+	}
+
+	data := fiber.Map{
+		"reference": ref,
+		"detail":    detail,
+		"comments":  buildCommentThreads(comments),
+	}
+	if ref.TeamRole != nil {
+		team, err := s.PgRepo.GetTeamReferences(ref.MongoAchievementID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil data anggota tim", "code": "500"})
+		}
+		data["team"] = team
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "success",
-		"data": fiber.Map{
-			"reference": ref,
-			"detail":    detail,
-		},
+		"data":   data,
 	})
 }
 
 // SubmitPrestasi godoc
 // @Summary      Buat Prestasi (Draft)
-// @Description  Mahasiswa membuat draft laporan prestasi baru.
+// @Description  Mahasiswa membuat draft laporan prestasi baru. Poin dihitung otomatis dari aturan poin (tipe, tingkat, peringkat, jumlah anggota tim). Untuk prestasi tim isi teamMembers; setiap anggota mendapat referensi tertaut dan harus mengonfirmasi partisipasi sebelum prestasi dapat disubmit. Referensi dan niat penulisan detail disimpan dalam satu transaksi PostgreSQL; synced=false berarti detail MongoDB masih menunggu worker outbox.
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        body body      modelMongo.AchievementInput true "Data Prestasi"
 // @Success      201  {object}  map[string]interface{} "Created"
-// @Failure      400  {object}  map[string]interface{} "Bad Request"
+// @Failure      400  {object}  map[string]interface{} "Bad Request / details tidak sesuai skema (lihat field errors)"
 // @Failure      403  {object}  map[string]interface{} "Forbidden (Bukan Mahasiswa)"
 // @Router       /achievements [post]
 func (s *AchievementService) SubmitPrestasi(c *fiber.Ctx) error {
@@ -192,51 +286,95 @@ func (s *AchievementService) SubmitPrestasi(c *fiber.Ctx) error {
 	if err := c.BodyParser(req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Input prestasi tidak valid", "code": "400"})
 	}
+	if fieldErrs := s.validateInput(req); len(fieldErrs) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Data prestasi tidak sesuai skema tipe", "code": "400", "errors": fieldErrs})
+	}
+
+	team, fieldErrs := s.buildTeam(studentID, req.TeamMembers)
+	if len(fieldErrs) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Data anggota tim tidak valid", "code": "400", "errors": fieldErrs})
+	}
+	if len(team) > 0 {
+		// Jumlah anggota selalu diambil dari daftar tim, bukan dari input details
+		if req.Details == nil { req.Details = map[string]interface{}{} }
+		req.Details["teamSize"] = len(team)
+	}
 
-	if req.Attachments == nil {
-        req.Attachments = []modelMongo.Attachment{}
+	// Poin selalu dihitung server dari aturan poin
+	pointResult, err := s.calculatePoints(req.AchievementType, req.Details)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menghitung poin prestasi", "code": "500"})
 	}
 	
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// ID dokumen ditentukan di sini agar penulisan ke Mongo lewat outbox idempoten
 	mongoAch := modelMongo.AchievementMongo{
+		ID:              primitive.NewObjectID(),
 		StudentID:       studentID,
 		AchievementType: req.AchievementType,
 		Title:           req.Title,
 		Description:     req.Description,
 		Details:         req.Details,
-		Attachments:     req.Attachments,
+		Attachments:     []modelMongo.Attachment{}, // Lampiran ditambahkan lewat POST /achievements/:id/attachments
 		Tags:            req.Tags,
-		Points:          req.Points,
+		TeamMembers:     team,
+		Points:          pointResult.Points,
 	}
-	createdMongo, err := s.MongoRepo.Create(ctx, &mongoAch)
+	event, err := outbox.NewCreateEvent(&mongoAch)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menyimpan detail ke MongoDB", "code": "500"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menyiapkan detail prestasi", "code": "500"})
 	}
+	mongoID := mongoAch.ID.Hex()
 
 	pgRef := modelPostgres.AchievementReference{
 		StudentID:          studentID,
-		MongoAchievementID: createdMongo.ID.Hex(),
+		MongoAchievementID: mongoID,
+	}
+	var createdRef *modelPostgres.AchievementReference
+	if len(team) > 0 {
+		// Setiap anggota mendapat referensi sendiri yang tertaut ke dokumen Mongo yang sama
+		var members []modelPostgres.AchievementReference
+		for _, member := range team {
+			role := member.Role
+			if member.StudentID == studentID {
+				pgRef.TeamRole = &role
+				continue
+			}
+			members = append(members, modelPostgres.AchievementReference{StudentID: member.StudentID, TeamRole: &role})
+		}
+		createdRef, err = s.PgRepo.CreateTeamReferences(&pgRef, members, profile.ID, event)
+	} else {
+		createdRef, err = s.PgRepo.CreateReference(&pgRef, profile.ID, event)
 	}
-	createdRef, err := s.PgRepo.CreateReference(&pgRef)
 
 	if err != nil {
-		s.MongoRepo.DeleteByID(ctx, createdMongo.ID)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menyimpan referensi ke PostgreSQL", "code": "500"})
 	}
 
+	// Referensi sudah commit; detail Mongo diterapkan sekarang atau nanti oleh worker outbox
+	synced := s.dispatchOutbox(ctx, mongoID)
+	if synced {
+		// Revisi 1 = konten awal
+		if _, err := s.RevisionRepo.Create(ctx, revision.Snapshot(&mongoAch, profile.ID)); err != nil {
+			log.Printf("gagal menyimpan revisi awal prestasi %s: %v", mongoID, err)
+		}
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"status":   "success",
 		"message":  "Prestasi berhasil disimpan sebagai DRAFT",
 		"id":       createdRef.ID,
-		"mongo_id": createdMongo.ID.Hex(),
+		"mongo_id": mongoID,
+		"points":   pointResult.Points,
+		"synced":   synced,
 	})
 }
 
 // UpdatePrestasi godoc
 // @Summary      Update Prestasi
-// @Description  Update data prestasi (Hanya jika status Draft/Rejected).
+// @Description  Update data prestasi (Hanya jika status Draft/Rejected/Revision Requested).
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
@@ -244,8 +382,9 @@ func (s *AchievementService) SubmitPrestasi(c *fiber.Ctx) error {
 // @Param        id   path      string                     true "Achievement ID"
 // @Param        body body      modelMongo.AchievementInput true "Data Update"
 // @Success      200  {object}  map[string]interface{}
-// @Failure      400  {object}  map[string]interface{} "Status bukan Draft"
+// @Failure      400  {object}  map[string]interface{} "Status bukan Draft / details tidak sesuai skema"
 // @Failure      403  {object}  map[string]interface{} "Bukan pemilik"
+// @Failure      409  {object}  map[string]interface{} "Prestasi masih disinkronkan ke MongoDB"
 // @Router       /achievements/{id} [put]
 func (s *AchievementService) UpdatePrestasi(c *fiber.Ctx) error {
 	profile := middleware.GetUserProfileFromContext(c)
@@ -259,13 +398,11 @@ func (s *AchievementService) UpdatePrestasi(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
 	}
-
-	studentID, _ := s.PgRepo.FindStudentIdByUserID(profile.ID)
-	if ref.StudentID != studentID {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Bukan milik Anda", "code": "403"})
+	if errPolicy := s.requireContentOwner(profile, ref); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
 	}
 
-	if ref.Status != modelPostgres.StatusDraft && ref.Status != modelPostgres.StatusRejected {
+	if !ref.Status.IsEditable() {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Tidak dapat mengubah prestasi yang sudah disubmit/diverifikasi", "code": "400"})
 	}
 
@@ -273,19 +410,57 @@ func (s *AchievementService) UpdatePrestasi(c *fiber.Ctx) error {
 	if err := c.BodyParser(req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Input tidak valid", "code": "400"})
 	}
+	if fieldErrs := s.validateInput(req); len(fieldErrs) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Data prestasi tidak sesuai skema tipe", "code": "400", "errors": fieldErrs})
+	}
+
+	if ref.TeamRole != nil {
+		// Keanggotaan tim tidak diubah lewat update; teamSize dipertahankan sesuai referensi tertaut
+		team, err := s.PgRepo.GetTeamReferences(ref.MongoAchievementID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil data anggota tim", "code": "500"})
+		}
+		if req.Details == nil { req.Details = map[string]interface{}{} }
+		req.Details["teamSize"] = len(team)
+	}
+
+	pointResult, err := s.calculatePoints(req.AchievementType, req.Details)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menghitung poin prestasi", "code": "500"})
+	}
+	req.Points = pointResult.Points
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
+	if errSync := s.requireSynced(ctx, ref.MongoAchievementID); errSync != nil {
+		return policy.Respond(c, errSync)
+	}
 	mongoID, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-	err = s.MongoRepo.Update(context.Background(), mongoID, req)
+	if errBaseline := s.ensureBaselineRevision(ctx, mongoID); errBaseline != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menyimpan revisi awal", "code": "500"})
+	}
+
+	err = s.MongoRepo.Update(ctx, mongoID, req)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal update data", "code": "500"})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "message": "Prestasi berhasil diperbarui"})
+	updated := modelMongo.AchievementMongo{
+		ID: mongoID, AchievementType: req.AchievementType, Title: req.Title, Description: req.Description,
+		Details: req.Details, Tags: req.Tags, Points: req.Points,
+	}
+	if _, err := s.RevisionRepo.Create(ctx, revision.Snapshot(&updated, profile.ID)); err != nil {
+		log.Printf("gagal menyimpan revisi prestasi %s: %v", mongoID.Hex(), err)
+	}
+	s.refreshView(ctx, ref.MongoAchievementID)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "message": "Prestasi berhasil diperbarui", "points": pointResult.Points})
 }
 
 // DeletePrestasi godoc
 // @Summary      Hapus Prestasi (Soft Delete)
-// @Description  Menghapus prestasi (Hanya jika status Draft).
+// @Description  Memindahkan prestasi ke trash (Hanya jika status Draft). Dapat di-restore sampai masa retensi trash habis.
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Achievement ID"
@@ -300,37 +475,137 @@ func (s *AchievementService) DeletePrestasi(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Hanya Mahasiswa yang dapat menghapus", "code": "403"})
 	}
 
-	studentID, err := s.PgRepo.FindStudentIdByUserID(profile.ID)
-	if err != nil || studentID == "" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Akun tidak terhubung ke data Mahasiswa.", "code": "403"})
+	scope, errPolicy := s.Policy.ScopeFor(profile)
+	if errPolicy != nil {
+		return policy.Respond(c, errPolicy)
 	}
 
-	deletedRef, err := s.PgRepo.SoftDeleteReference(achievementID, studentID)
+	// Kepemilikan dijaga oleh kondisi student_id pada query soft delete; soft delete Mongo ikut dicatat ke outbox
+	deletedRef, err := s.PgRepo.SoftDeleteReference(achievementID, scope.StudentID, profile.ID)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error(), "code": "400"})
 	}
 
-	mongoObjectID, err := primitive.ObjectIDFromHex(deletedRef.MongoAchievementID)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	synced := s.dispatchOutbox(ctx, deletedRef.MongoAchievementID)
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success", "message": "Prestasi dipindahkan ke trash (soft deleted).", "synced": synced,
+	})
+}
+
+// ListTrash godoc
+// @Summary      Trash Prestasi
+// @Description  Daftar prestasi yang dihapus (Mahasiswa: milik sendiri, Admin: semua) beserta waktu purge permanen sesuai pengaturan trash_retention_days.
+// @Tags         Achievements
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /achievements/trash [get]
+func (s *AchievementService) ListTrash(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	scope, errPolicy := s.Policy.ScopeFor(profile)
+	if errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+	if !scope.All && scope.Role != "Mahasiswa" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Trash hanya untuk pemilik prestasi dan Admin", "code": "403"})
+	}
+
+	references, err := s.PgRepo.GetDeletedReferences(scope.StudentID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil data trash", "code": "500"})
+	}
+	retentionDays, err := s.SettingRepo.GetIntSetting(modelPostgres.SettingTrashRetentionDays, modelPostgres.DefaultTrashRetentionDays)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "ID Mongo corrupt", "code": "500"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal membaca pengaturan retensi", "code": "500"})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	var mongoIDs []primitive.ObjectID
+	for _, ref := range references {
+		if oid, err := primitive.ObjectIDFromHex(ref.MongoAchievementID); err == nil {
+			mongoIDs = append(mongoIDs, oid)
+		}
+	}
+	detailMap := make(map[string]modelMongo.AchievementMongo)
+	if len(mongoIDs) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		details, err := s.MongoRepo.GetDeletedDetailsByIDs(ctx, mongoIDs)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil detail trash", "code": "500"})
+		}
+		for _, d := range details {
+			detailMap[d.ID.Hex()] = d
+		}
+	}
+
+	items := []fiber.Map{}
+	for _, ref := range references {
+		item := fiber.Map{
+			"id":         ref.ID,
+			"student_id": ref.StudentID,
+			"team_role":  ref.TeamRole,
+			"deleted_at": ref.DeletedAt,
+			"purge_at":   nil,
+		}
+		if ref.DeletedAt != nil {
+			item["purge_at"] = ref.DeletedAt.AddDate(0, 0, retentionDays)
+		}
+		if detail, ok := detailMap[ref.MongoAchievementID]; ok {
+			item["title"] = detail.Title
+			item["achievement_type"] = detail.AchievementType
+		}
+		items = append(items, item)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "retention_days": retentionDays, "data": items})
+}
+
+// RestorePrestasi godoc
+// @Summary      Restore Prestasi dari Trash
+// @Description  Mengembalikan prestasi yang dihapus ke status Draft (PostgreSQL & MongoDB). Mahasiswa pemilik atau Admin. synced=false berarti detail MongoDB masih menunggu worker outbox.
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Achievement ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /achievements/{id}/restore [post]
+func (s *AchievementService) RestorePrestasi(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	achievementID := c.Params("id")
 
-	err = s.MongoRepo.SoftDelete(ctx, mongoObjectID)
+	scope, errPolicy := s.Policy.ScopeFor(profile)
+	if errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+	if !scope.All && scope.Role != "Mahasiswa" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Restore hanya untuk pemilik prestasi dan Admin", "code": "403"})
+	}
+
+	// Kepemilikan dijaga kondisi student_id pada query (kosong untuk Admin); restore Mongo ikut dicatat ke outbox
+	restoredRef, err := s.PgRepo.RestoreReference(achievementID, scope.StudentID, profile.ID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal hapus detail Mongo", "code": "500"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error(), "code": "400"})
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	synced := s.dispatchOutbox(ctx, restoredRef.MongoAchievementID)
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"status": "success", "message": "Prestasi berhasil dihapus (soft deleted).",
+		"status": "success", "message": "Prestasi berhasil dikembalikan sebagai Draft.", "data": restoredRef, "synced": synced,
 	})
 }
 
+const msgNotSubmittable = "Gagal submit: Prestasi harus berstatus DRAFT atau REVISION_REQUESTED."
+
 // SubmitForVerification godoc
 // @Summary      Submit untuk Verifikasi
-// @Description  Mengubah status Draft menjadi Submitted.
+// @Description  Mengubah status Draft (atau Revision Requested untuk pengajuan ulang) menjadi Submitted. Syarat bukti tipe prestasi (lihat /achievement-types) harus terpenuhi; bila belum, daftar unmet_requirements dikembalikan.
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Achievement ID"
@@ -349,49 +624,96 @@ func (s *AchievementService) SubmitForVerification(c *fiber.Ctx) error {
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
 	}
+	if errPolicy := s.requireContentOwner(profile, ref); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+	// Cek awal sebelum memuat tim & dokumen Mongo; repository tetap menjaga status saat baris dikunci
+	if !ref.Status.IsSubmittable() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": msgNotSubmittable, "code": "400"})
+	}
 
-	studentID, err := s.PgRepo.FindStudentIdByUserID(profile.ID)
-	if err != nil || studentID == "" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Data mahasiswa invalid", "code": "403"})
+	if ref.TeamRole != nil {
+		team, err := s.PgRepo.GetTeamReferences(ref.MongoAchievementID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil data anggota tim", "code": "500"})
+		}
+		pending := []string{}
+		for _, member := range team {
+			if member.IsPendingConfirmation() { pending = append(pending, member.StudentID) }
+		}
+		if len(pending) > 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"message": "Belum semua anggota tim mengonfirmasi partisipasi.", "code": "400", "pending_members": pending,
+			})
+		}
+	}
+
+	mongoID, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	detail, err := s.MongoRepo.GetDetailByID(context.Background(), mongoID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil detail data", "code": "500"})
 	}
 
-	if ref.StudentID != studentID {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Bukan milik Anda", "code": "403"})
+	if unmet := s.Types.CheckEvidence(detail.AchievementType, detail.Attachments, detail.Details); len(unmet) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"message": "Bukti pendukung belum lengkap. Lengkapi lampiran/data berikut sebelum submit.", "code": "400", "unmet_requirements": unmet,
+		})
 	}
 
-	updatedRef, err := s.PgRepo.UpdateStatusToSubmitted(achievementID)
+	// Alur verifikasi ditentukan saat submit, berdasarkan tipe & level prestasi
+	flow := s.Workflow.Resolve(detail.AchievementType, detail.Details)
+
+	updatedRef, err := s.PgRepo.UpdateStatusToSubmitted(achievementID, profile.ID, flow.Code, flow.FirstStage().Code)
 	if err != nil {
-		if errors.Is(err, errors.New("prestasi tidak ditemukan atau status sudah berubah")) {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Gagal submit: Prestasi harus berstatus DRAFT.", "code": "400"})
+		if errors.Is(err, repoPostgres.ErrInvalidTransition) || errors.Is(err, repoPostgres.ErrReferenceNotFound) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": msgNotSubmittable, "code": "400"})
 		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error(), "code": "500"})
 	}
+	s.syncView(updatedRef)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "success", "message": "Prestasi berhasil disubmit untuk verifikasi", "new_status": updatedRef.Status,
+		"workflow": flow.Code, "current_stage": updatedRef.CurrentStage,
 	})
 }
 
 // VerifyPrestasi godoc
-// @Summary      Verifikasi Prestasi (Dosen)
-// @Description  Mengubah status menjadi Verified.
+// @Summary      Verifikasi Prestasi (Dosen/Verifikator Tahap)
+// @Description  Menyetujui tahap verifikasi yang sedang berjalan. Jika masih ada tahap berikutnya prestasi diteruskan (status tetap Submitted), jika tahap terakhir status menjadi Verified. Dosen penerima delegasi aktif dapat memproses bimbingan delegator; riwayat mencatat on_behalf_of.
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Achievement ID"
 // @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{} "Bukan verifikator tahap ini"
 // @Router       /achievements/{id}/verify [post]
 func (s *AchievementService) VerifyPrestasi(c *fiber.Ctx) error {
 	profile := middleware.GetUserProfileFromContext(c)
 	achievementID := c.Params("id")
 
-	if profile.Role != "Dosen Wali" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Akses ditolak. Hanya Dosen Wali.", "code": "403"})
+	ref, err := s.PgRepo.GetReferenceByID(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
+	}
+
+	flow, stage, onBehalfOf, errMsg := s.activeStage(ref, profile)
+	if errMsg != nil {
+		return policy.Respond(c, errMsg)
 	}
 
-	updatedRef, err := s.PgRepo.VerifyAchievement(achievementID, profile.ID)
+	updatedRef, err := s.approveStage(ref, flow, stage, profile.ID, onBehalfOf, "")
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error(), "code": "400"})
 	}
+	s.syncView(updatedRef)
+
+	if next, hasNext := flow.NextStage(stage.Code); hasNext {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"status": "success", "message": fmt.Sprintf("Tahap '%s' disetujui. Menunggu '%s'.", stage.Name, next.Name),
+			"new_status": updatedRef.Status, "current_stage": updatedRef.CurrentStage,
+		})
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "success", "message": "Prestasi berhasil diverifikasi.", "new_status": updatedRef.Status,
@@ -400,7 +722,7 @@ func (s *AchievementService) VerifyPrestasi(c *fiber.Ctx) error {
 
 // RejectPrestasi godoc
 // @Summary      Tolak Prestasi (Dosen)
-// @Description  Mengubah status menjadi Rejected dengan catatan.
+// @Description  Mengubah status menjadi Rejected dengan catatan. Penolakan bersifat final (gunakan request-revision untuk meminta perbaikan).
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Achievement ID"
@@ -411,8 +733,13 @@ func (s *AchievementService) RejectPrestasi(c *fiber.Ctx) error {
 	profile := middleware.GetUserProfileFromContext(c)
 	achievementID := c.Params("id")
 
-	if profile.Role != "Dosen Wali" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Akses ditolak", "code": "403"})
+	ref, err := s.PgRepo.GetReferenceByID(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
+	}
+	_, _, onBehalfOf, errMsg := s.activeStage(ref, profile)
+	if errMsg != nil {
+		return policy.Respond(c, errMsg)
 	}
 
 	var input map[string]string
@@ -424,121 +751,1582 @@ func (s *AchievementService) RejectPrestasi(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Catatan penolakan wajib diisi.", "code": "400"})
 	}
 
-	updatedRef, err := s.PgRepo.RejectAchievement(achievementID, profile.ID, rejectionNote)
+	updatedRef, err := s.PgRepo.RejectAchievement(ref.ID, profile.ID, onBehalfOf, derefString(ref.CurrentStage), rejectionNote)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error(), "code": "400"})
 	}
+	s.syncView(updatedRef)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "success", "message": "Prestasi ditolak.", "new_status": updatedRef.Status,
 	})
 }
 
-// GetHistory godoc
-// @Summary      Lihat Riwayat Status
-// @Description  Melihat log perubahan status prestasi.
+// RequestRevisionPrestasi godoc
+// @Summary      Minta Revisi Prestasi (Dosen)
+// @Description  Mengembalikan prestasi Submitted ke mahasiswa untuk diperbaiki (status Revision Requested). Mahasiswa dapat mengedit lalu submit ulang; jumlah siklus revisi dicatat.
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Achievement ID"
+// @Param        body body      map[string]string true "Catatan Revisi: {'revision_note': '...'}"
 // @Success      200  {object}  map[string]interface{}
-// @Router       /achievements/{id}/history [get]
-func (s *AchievementService) GetHistory(c *fiber.Ctx) error {
+// @Failure      400  {object}  map[string]interface{}
+// @Router       /achievements/{id}/request-revision [post]
+func (s *AchievementService) RequestRevisionPrestasi(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
 	achievementID := c.Params("id")
+
 	ref, err := s.PgRepo.GetReferenceByID(achievementID)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Not Found"})
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
+	}
+	_, _, onBehalfOf, errMsg := s.activeStage(ref, profile)
+	if errMsg != nil {
+		return policy.Respond(c, errMsg)
 	}
 
-	history := []fiber.Map{{"status": "created", "timestamp": ref.CreatedAt}}
-	if ref.SubmittedAt != nil {
-		history = append(history, fiber.Map{"status": "submitted", "timestamp": ref.SubmittedAt})
+	var input map[string]string
+	if err := json.Unmarshal(c.Body(), &input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Input tidak valid", "code": "400"})
 	}
-	if ref.VerifiedAt != nil {
-		history = append(history, fiber.Map{"status": string(ref.Status), "timestamp": ref.VerifiedAt, "note": ref.RejectionNote})
+	revisionNote, ok := input["revision_note"]
+	if !ok || strings.TrimSpace(revisionNote) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Catatan revisi wajib diisi.", "code": "400"})
 	}
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "data": history})
+
+	updatedRef, err := s.PgRepo.RequestRevision(ref.ID, profile.ID, onBehalfOf, derefString(ref.CurrentStage), revisionNote)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error(), "code": "400"})
+	}
+	s.syncView(updatedRef)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success", "message": "Permintaan revisi dikirim ke mahasiswa.",
+		"new_status": updatedRef.Status, "revision_count": updatedRef.RevisionCount,
+	})
 }
 
-// AddAttachment godoc
-// @Summary      Upload Attachment
-// @Description  Mengunggah file lampiran prestasi.
+// OverrideStatus godoc
+// @Summary      Override Status Prestasi (Admin)
+// @Description  Memindahkan prestasi ke status apa pun (draft, submitted, revision_requested, verified, rejected) di luar alur normal, misalnya untuk memperbaiki salah verifikasi. Alasan wajib (min. 10 karakter) dan dicatat di riwayat sebagai override. Mengubah prestasi Verified memengaruhi statistik & laporan sehingga wajib confirm=true.
 // @Tags         Achievements
+// @Accept       json
+// @Produce      json
 // @Security     BearerAuth
-// @Accept       multipart/form-data
-// @Param        id   path      string  true  "Achievement ID"
-// @Param        file formData  file    true  "File Lampiran"
+// @Param        id   path      string                              true "Achievement ID"
+// @Param        body body      modelPostgres.StatusOverrideRequest true "Status tujuan & alasan"
 // @Success      200  {object}  map[string]interface{}
-// @Router       /achievements/{id}/attachments [post]
-func (s *AchievementService) AddAttachment(c *fiber.Ctx) error {
+// @Failure      400  {object}  map[string]interface{} "Status/alasan tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Bukan Admin"
+// @Failure      409  {object}  map[string]interface{} "Perlu konfirmasi (prestasi Verified) / status sudah berubah"
+// @Router       /achievements/{id}/override-status [post]
+func (s *AchievementService) OverrideStatus(c *fiber.Ctx) error {
 	profile := middleware.GetUserProfileFromContext(c)
 	achievementID := c.Params("id")
 
-	// 1. Validasi Role
-	if profile.Role != "Mahasiswa" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Akses ditolak. Hanya Mahasiswa.", "code": "403"})
+	if profile.Role != "Admin" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Hanya Admin yang dapat melakukan override status", "code": "403"})
+	}
+
+	req := new(modelPostgres.StatusOverrideRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Input tidak valid", "code": "400"})
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if !req.Status.IsOverridable() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Status tujuan harus salah satu dari: draft, submitted, revision_requested, verified, rejected", "code": "400"})
+	}
+	if len(req.Reason) < modelPostgres.MinOverrideReasonLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": fmt.Sprintf("Alasan override wajib diisi (minimal %d karakter)", modelPostgres.MinOverrideReasonLength), "code": "400"})
 	}
 
-	// 2. Cek Referensi di Postgres
 	ref, err := s.PgRepo.GetReferenceByID(achievementID)
-	if err != nil {
+	if err != nil || ref == nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
 	}
+	if ref.Status == req.Status {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Prestasi sudah berstatus " + string(req.Status), "code": "400"})
+	}
 
-	// 3. Ambil File dari Form
-	file, err := c.FormFile("file") 
-	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"message": "Gagal mengambil file. Pastikan key adalah 'file'", "error": err.Error()})
+	leavingVerified := ref.Status == modelPostgres.StatusVerified
+	warnings := []string{}
+	if leavingVerified {
+		warnings = append(warnings, "Prestasi ini sebelumnya Verified: statistik, laporan mahasiswa, dan poin yang sudah dibekukan akan berubah.")
+		if !req.Confirm {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"message": "Override prestasi Verified memengaruhi laporan. Kirim ulang dengan confirm=true untuk melanjutkan.",
+				"code": "409", "warnings": warnings, "current_status": ref.Status,
+			})
+		}
+	}
+
+	workflowCode, firstStage := "", ""
+	if req.Status == modelPostgres.StatusSubmitted {
+		mongoID, _ := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+		detail, err := s.MongoRepo.GetDetailByID(context.Background(), mongoID)
+		if err != nil || detail == nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil detail data", "code": "500"})
+		}
+		// Alur verifikasi dimulai ulang dari tahap pertama
+		flow := s.Workflow.Resolve(detail.AchievementType, detail.Details)
+		workflowCode, firstStage = flow.Code, flow.FirstStage().Code
 	}
 
-	// 4. Buat Folder & Simpan File
-	uploadDir := "./uploads"
-	if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
-		os.Mkdir(uploadDir, 0755)
+	updatedRef, err := s.PgRepo.OverrideStatus(ref.ID, req.Status, profile.ID, req.Reason, workflowCode, firstStage, leavingVerified)
+	if err != nil {
+		if errors.Is(err, repoPostgres.ErrInvalidTransition) || errors.Is(err, repoPostgres.ErrReferenceNotFound) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": "Status prestasi berubah saat diproses, muat ulang lalu coba lagi: " + err.Error(), "code": "409"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error(), "code": "500"})
+	}
+	log.Printf("override status prestasi %s: %s -> %s oleh %s (alasan: %s)", ref.ID, ref.Status, updatedRef.Status, profile.ID, req.Reason)
+
+	if updatedRef.Status == modelPostgres.StatusVerified {
+		s.freezePoints(updatedRef)
+	} else if leavingVerified {
+		if mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID); err == nil {
+			if err := s.MongoRepo.UnfreezePoints(context.Background(), mongoID); err != nil {
+				log.Printf("unfreeze poin %s: %v", ref.ID, err)
+			}
+		}
 	}
+	s.syncView(updatedRef)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success", "message": fmt.Sprintf("Status prestasi diubah dari %s ke %s (override).", ref.Status, updatedRef.Status),
+		"data": updatedRef, "warnings": warnings,
+	})
+}
+
+// BulkVerifyPrestasi godoc
+// @Summary      Verifikasi Massal (Dosen/Verifikator Tahap)
+// @Description  Menyetujui tahap verifikasi untuk banyak prestasi sekaligus. Setiap ID diproses terpisah (partial success); hasil per ID menyertakan alasan gagal: not_found, invalid_status, not_advisee, wrong_stage.
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body body      modelPostgres.BulkActionRequest true "Daftar ID prestasi & catatan opsional"
+// @Success      200  {object}  map[string]interface{} "Hasil per ID"
+// @Failure      400  {object}  map[string]interface{} "Input tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Forbidden"
+// @Router       /achievements/bulk/verify [post]
+func (s *AchievementService) BulkVerifyPrestasi(c *fiber.Ctx) error {
+	return s.processBulk(c, false)
+}
+
+// BulkRejectPrestasi godoc
+// @Summary      Tolak Massal (Dosen/Verifikator Tahap)
+// @Description  Menolak banyak prestasi sekaligus dengan satu catatan penolakan bersama (wajib). Setiap ID diproses terpisah (partial success).
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body body      modelPostgres.BulkActionRequest true "Daftar ID prestasi & catatan penolakan"
+// @Success      200  {object}  map[string]interface{} "Hasil per ID"
+// @Failure      400  {object}  map[string]interface{} "Input tidak valid"
+// @Failure      403  {object}  map[string]interface{} "Forbidden"
+// @Router       /achievements/bulk/reject [post]
+func (s *AchievementService) BulkRejectPrestasi(c *fiber.Ctx) error {
+	return s.processBulk(c, true)
+}
 
-	filename := fmt.Sprintf("%d-%s", time.Now().Unix(), file.Filename)
-	savePath := filepath.Join(uploadDir, filename)
+// maxBulkItems membatasi jumlah ID per request agar satu request tidak menahan koneksi terlalu lama
+const maxBulkItems = 100
 
-	if err := c.SaveFile(file, savePath); err != nil {
-		return c.Status(500).JSON(fiber.Map{"message": "Gagal menyimpan file fisik", "error": err.Error()})
+func (s *AchievementService) processBulk(c *fiber.Ctx, reject bool) error {
+	profile := middleware.GetUserProfileFromContext(c)
+
+	req := new(modelPostgres.BulkActionRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Input tidak valid", "code": "400"})
 	}
 
-	// 5. Generate URL & Struct
-	// Ganti localhost:3000 dengan domain/IP server jika di deploy
-	fileUrl := fmt.Sprintf("http://localhost:3000/uploads/%s", filename) 
-	
-	attachment := modelMongo.Attachment{
-		FileName:   file.Filename,
-		FileUrl:    fileUrl,
-		FileType:   file.Header.Get("Content-Type"),
-		UploadedAt: time.Now(),
+	// Buang ID kosong & duplikat, urutan dipertahankan
+	ids := make([]string, 0, len(req.IDs))
+	seen := make(map[string]bool)
+	for _, id := range req.IDs {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] { continue }
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Daftar ids wajib diisi.", "code": "400"})
+	}
+	if len(ids) > maxBulkItems {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": fmt.Sprintf("Maksimal %d prestasi per request.", maxBulkItems), "code": "400"})
+	}
+	note := strings.TrimSpace(req.Note)
+	if reject && note == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Catatan penolakan wajib diisi.", "code": "400"})
 	}
 
-	// ⚠️ PERBAIKAN 1: Cek Error Konversi ID
-	mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{
-			"message": "Format MongoID di database PostgreSQL rusak/invalid", 
-			"detail": ref.MongoAchievementID,
-		})
+	// Scope dihitung sekali untuk seluruh batch
+	scope, errPolicy := s.Policy.ScopeFor(profile)
+	if errPolicy != nil {
+		return policy.Respond(c, errPolicy)
 	}
 
-	// 6. Update MongoDB
-	err = s.MongoRepo.AddAttachment(context.Background(), mongoID, attachment)
-	
+	refs, err := s.PgRepo.GetReferencesByIDs(ids)
 	if err != nil {
-		// ⚠️ PERBAIKAN 2: Tampilkan Error Asli untuk Debugging
-		fmt.Println("MONGO ERROR:", err) // Print ke terminal
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"message": "Gagal menyimpan lampiran ke database", 
-			"error": err.Error(), // Kirim pesan error asli ke client
-			"code": "500",
-		})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil data prestasi.", "code": "500"})
 	}
-	
+	refMap := make(map[string]*modelPostgres.AchievementReference, len(refs))
+	for i := range refs {
+		refMap[refs[i].ID] = &refs[i]
+	}
+
+	results := make([]modelPostgres.BulkActionResult, 0, len(ids))
+	succeeded := 0
+	for _, id := range ids {
+		result := s.processBulkItem(id, refMap[id], scope, profile, reject, note)
+		if result.Success { succeeded++ }
+		results = append(results, result)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"status": "success", 
-		"message": "Lampiran berhasil ditambahkan",
-		"data": attachment,
+		"status":  "success",
+		"message": fmt.Sprintf("%d dari %d prestasi berhasil diproses.", succeeded, len(ids)),
+		"data": fiber.Map{
+			"succeeded": succeeded,
+			"failed":    len(ids) - succeeded,
+			"results":   results,
+		},
+	})
+}
+
+// processBulkItem memproses satu ID; tiap transisi berjalan di transaksinya sendiri sehingga
+// kegagalan satu item tidak membatalkan item lain.
+func (s *AchievementService) processBulkItem(id string, ref *modelPostgres.AchievementReference, scope *policy.Scope, profile modelPostgres.UserProfile, reject bool, note string) modelPostgres.BulkActionResult {
+	result := modelPostgres.BulkActionResult{ID: id}
+
+	if ref == nil {
+		result.Reason, result.Message = modelPostgres.BulkReasonNotFound, "Prestasi tidak ditemukan"
+		return result
+	}
+	if ref.Status != modelPostgres.StatusSubmitted {
+		result.Reason, result.Message = modelPostgres.BulkReasonInvalidStatus, fmt.Sprintf("Status saat ini '%s', bukan submitted", ref.Status)
+		return result
+	}
+	if !scope.AllowsStudent(ref.StudentID) && !ref.IsEscalatedTo(profile) {
+		result.Reason, result.Message = modelPostgres.BulkReasonNotAdvisee, "Mahasiswa pemilik prestasi bukan bimbingan Anda"
+		return result
+	}
+	flow, stage, errMsg := s.resolveStage(ref, profile)
+	if errMsg != nil {
+		result.Reason, result.Message = modelPostgres.BulkReasonWrongStage, errMsg.Message
+		return result
+	}
+
+	var updatedRef *modelPostgres.AchievementReference
+	var err error
+	onBehalfOf := scope.DelegatorFor(ref.StudentID)
+	if reject {
+		updatedRef, err = s.PgRepo.RejectAchievement(ref.ID, profile.ID, onBehalfOf, derefString(ref.CurrentStage), note)
+	} else {
+		updatedRef, err = s.approveStage(ref, flow, stage, profile.ID, onBehalfOf, note)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, repoPostgres.ErrReferenceNotFound):
+			result.Reason = modelPostgres.BulkReasonNotFound
+		case errors.Is(err, repoPostgres.ErrInvalidTransition):
+			result.Reason = modelPostgres.BulkReasonInvalidStatus
+		default:
+			result.Reason = modelPostgres.BulkReasonError
+		}
+		result.Message = err.Error()
+		return result
+	}
+
+	s.syncView(updatedRef)
+	result.Success = true
+	result.NewStatus = updatedRef.Status
+	result.CurrentStage = updatedRef.CurrentStage
+	return result
+}
+
+// WithdrawPrestasi godoc
+// @Summary      Tarik Kembali Pengajuan
+// @Description  Mengembalikan prestasi Submitted ke Draft agar bisa diperbaiki. Hanya pemilik dan hanya selama verifikator belum memproses (belum ada tahap yang disetujui/ditolak).
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Achievement ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /achievements/{id}/withdraw [post]
+func (s *AchievementService) WithdrawPrestasi(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	achievementID := c.Params("id")
+
+	if profile.Role != "Mahasiswa" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Akses ditolak", "code": "403"})
+	}
+
+	ref, err := s.PgRepo.GetReferenceByID(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
+	}
+	if errPolicy := s.requireContentOwner(profile, ref); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+
+	updatedRef, err := s.PgRepo.WithdrawSubmission(ref.ID, ref.StudentID, profile.ID)
+	if err != nil {
+		if errors.Is(err, repoPostgres.ErrInvalidTransition) || errors.Is(err, repoPostgres.ErrReferenceNotFound) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Gagal withdraw: Prestasi harus berstatus SUBMITTED dan belum diproses verifikator.", "code": "400"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": err.Error(), "code": "500"})
+	}
+	s.syncView(updatedRef)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success", "message": "Pengajuan ditarik kembali, prestasi kembali menjadi Draft", "new_status": updatedRef.Status,
+	})
+}
+
+// ConfirmParticipation godoc
+// @Summary      Konfirmasi Partisipasi Tim (Mahasiswa)
+// @Description  Anggota prestasi tim mengonfirmasi keikutsertaannya. Prestasi tim baru dapat disubmit setelah semua anggota mengonfirmasi.
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Achievement ID (referensi milik anggota)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /achievements/{id}/confirm-participation [post]
+func (s *AchievementService) ConfirmParticipation(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	achievementID := c.Params("id")
+
+	ref, err := s.PgRepo.GetReferenceByID(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
+	}
+	if errPolicy := s.Policy.RequireOwner(profile, ref.StudentID); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+	if !ref.CoMember {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Konfirmasi hanya untuk anggota prestasi tim", "code": "400"})
+	}
+
+	updatedRef, err := s.PgRepo.ConfirmParticipation(ref.ID, ref.StudentID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": err.Error(), "code": "400"})
+	}
+	s.syncView(updatedRef)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success", "message": "Partisipasi tim berhasil dikonfirmasi.",
+		"participation_confirmed_at": updatedRef.ParticipationConfirmedAt,
+	})
+}
+
+// GetHistory godoc
+// @Summary      Lihat Riwayat Status
+// @Description  Melihat log perubahan status prestasi (urut dari yang paling lama), termasuk aktor dan catatan tiap perubahan.
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Achievement ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{} "Not Found"
+// @Failure      500  {object}  map[string]interface{} "Internal Error"
+// @Router       /achievements/{id}/history [get]
+func (s *AchievementService) GetHistory(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	achievementID := c.Params("id")
+	ref, err := s.PgRepo.GetReferenceByID(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
+	}
+	if errPolicy := s.requireReadAccess(profile, ref); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+
+	history, err := s.PgRepo.GetStatusHistory(ref.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil riwayat status", "code": "500"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "data": history})
+}
+
+// ListRevisions godoc
+// @Summary      Riwayat Revisi Konten Prestasi
+// @Description  Mendapatkan semua revisi konten (title, description, details, tags) dari yang terlama. Versi 1 = konten awal.
+// @Tags         Achievements
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Achievement ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /achievements/{id}/revisions [get]
+func (s *AchievementService) ListRevisions(c *fiber.Ctx) error {
+	mongoID, errResp := s.revisionTarget(c)
+	if errResp != nil {
+		return policy.Respond(c, errResp)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	revisions, err := s.RevisionRepo.ListByAchievement(ctx, mongoID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil revisi prestasi", "code": "500"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "data": revisions})
+}
+
+// DiffRevisions godoc
+// @Summary      Bandingkan Dua Revisi
+// @Description  Menampilkan perbedaan field per field (title, description, details.*, tags) antara dua versi. Default: versi terakhir dibandingkan dengan versi sebelumnya.
+// @Tags         Achievements
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string  true   "Achievement ID"
+// @Param        from  query     int     false  "Versi awal (default: to - 1)"
+// @Param        to    query     int     false  "Versi akhir (default: versi terakhir)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /achievements/{id}/revisions/diff [get]
+func (s *AchievementService) DiffRevisions(c *fiber.Ctx) error {
+	mongoID, errResp := s.revisionTarget(c)
+	if errResp != nil {
+		return policy.Respond(c, errResp)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	to := c.QueryInt("to", 0)
+	if to == 0 {
+		latest, err := s.RevisionRepo.LatestVersion(ctx, mongoID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil revisi prestasi", "code": "500"})
+		}
+		to = latest
+	}
+	from := c.QueryInt("from", to-1)
+	if from < 1 || to < 1 || from == to {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Parameter from/to harus nomor versi yang valid dan berbeda (minimal butuh dua revisi)", "code": "400"})
+	}
+
+	fromRev, err := s.RevisionRepo.GetByVersion(ctx, mongoID, from)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": fmt.Sprintf("Revisi versi %d tidak ditemukan", from), "code": "404"})
+	}
+	toRev, err := s.RevisionRepo.GetByVersion(ctx, mongoID, to)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": fmt.Sprintf("Revisi versi %d tidak ditemukan", to), "code": "404"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"from":    fiber.Map{"version": fromRev.Version, "edited_by": fromRev.EditedBy, "created_at": fromRev.CreatedAt},
+			"to":      fiber.Map{"version": toRev.Version, "edited_by": toRev.EditedBy, "created_at": toRev.CreatedAt},
+			"changes": revision.Diff(fromRev, toRev),
+		},
 	})
-}
\ No newline at end of file
+}
+
+// ListComments godoc
+// @Summary      Thread Komentar Prestasi
+// @Description  Mendapatkan komentar antara mahasiswa dan verifikator, dikelompokkan per komentar utama beserta balasannya (urut waktu).
+// @Tags         Achievements
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Achievement ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /achievements/{id}/comments [get]
+func (s *AchievementService) ListComments(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	ref, err := s.PgRepo.GetReferenceByID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
+	}
+	if errPolicy := s.requireReadAccess(profile, ref); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+	mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Format MongoID di database PostgreSQL rusak/invalid", "code": "500"})
+	}
+
+	comments, err := s.CommentRepo.ListByAchievement(context.Background(), mongoID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil komentar", "code": "500"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "total": len(comments), "data": buildCommentThreads(comments)})
+}
+
+// AddComment godoc
+// @Summary      Tambah Komentar Prestasi
+// @Description  Mengirim komentar (atau balasan dengan parent_id) pada prestasi. Dapat dikirim sebagai JSON atau multipart form dengan lampiran pada key "files" (maks. 5 file, ukuran tiap file sesuai batas jenisnya; total request maks. 5x batas terbesar + 1 MB). Hanya pihak yang boleh melihat prestasi (pemilik/anggota tim, dosen wali/delegasi, Admin).
+// @Tags         Achievements
+// @Accept       json
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      string  true   "Achievement ID"
+// @Param        message   formData  string  false  "Isi komentar (wajib bila tanpa lampiran)"
+// @Param        parent_id formData  string  false  "ID komentar yang dibalas"
+// @Param        files     formData  file    false  "Lampiran komentar (PDF/PNG/JPEG, aturan sama dengan lampiran prestasi)"
+// @Success      201  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      413  {object}  map[string]interface{} "Ukuran file melebihi batas"
+// @Router       /achievements/{id}/comments [post]
+func (s *AchievementService) AddComment(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	ref, err := s.PgRepo.GetReferenceByID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
+	}
+	if errPolicy := s.requireReadAccess(profile, ref); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+	if ref.Status == modelPostgres.StatusDeleted {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Prestasi sudah dihapus, tidak dapat dikomentari", "code": "400"})
+	}
+	mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Format MongoID di database PostgreSQL rusak/invalid", "code": "500"})
+	}
+
+	req := new(modelMongo.CommentInput)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Input tidak valid", "code": "400"})
+	}
+	req.Message = strings.TrimSpace(req.Message)
+	var files []*multipart.FileHeader
+	if form, err := c.MultipartForm(); err == nil {
+		files = form.File["files"]
+	}
+	if req.Message == "" && len(files) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Komentar tidak boleh kosong", "code": "400"})
+	}
+	if len([]rune(req.Message)) > modelMongo.MaxCommentLength {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": fmt.Sprintf("Komentar maksimal %d karakter", modelMongo.MaxCommentLength), "code": "400"})
+	}
+	if len(files) > modelMongo.MaxCommentAttachments {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": fmt.Sprintf("Lampiran komentar maksimal %d file", modelMongo.MaxCommentAttachments), "code": "400"})
+	}
+	uploads, rejections := s.validateUploads(files)
+	if len(rejections) > 0 {
+		return c.Status(rejections[0].Status).JSON(fiber.Map{"message": "Lampiran komentar ditolak: " + rejections[0].Message, "code": strconv.Itoa(rejections[0].Status), "errors": rejections})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	comment := &modelMongo.AchievementComment{
+		ID:            primitive.NewObjectID(),
+		AchievementID: mongoID,
+		AuthorID:      profile.ID,
+		AuthorName:    profile.FullName,
+		AuthorRole:    profile.Role,
+		Message:       req.Message,
+	}
+	if parentHex := strings.TrimSpace(req.ParentID); parentHex != "" {
+		parentID, err := primitive.ObjectIDFromHex(parentHex)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "parent_id tidak valid", "code": "400"})
+		}
+		parent, err := s.CommentRepo.GetByID(ctx, parentID)
+		if err != nil || parent.AchievementID != mongoID {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Komentar yang dibalas tidak ditemukan pada prestasi ini", "code": "400"})
+		}
+		// Thread satu tingkat: balasan atas balasan ditautkan ke komentar utamanya
+		if parent.ParentID != nil { parentID = *parent.ParentID }
+		comment.ParentID = &parentID
+	}
+
+	for _, file := range uploads {
+		attachment, err := s.saveUpload(ctx, file)
+		if err != nil {
+			s.discardUploads(comment.Attachments)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menyimpan file lampiran komentar", "code": "500"})
+		}
+		// Seperti lampiran prestasi, hanya bisa diambil lewat endpoint unduh yang memeriksa akses
+		attachment.FileUrl = fmt.Sprintf("/api/v1/achievements/%s/comments/%s/attachments/%s", ref.ID, comment.ID.Hex(), attachment.ID)
+		comment.Attachments = append(comment.Attachments, attachment)
+	}
+
+	created, err := s.CommentRepo.Create(ctx, comment)
+	if err != nil {
+		s.discardUploads(comment.Attachments)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menyimpan komentar", "code": "500"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"status": "success", "message": "Komentar terkirim", "data": created})
+}
+
+// AddAttachment godoc
+// @Summary      Upload Attachment
+// @Description  Mengunggah file lampiran prestasi. Kategori dipakai untuk memeriksa syarat bukti saat submit (mis. sertifikat & foto untuk kompetisi). Hanya pemilik saat status draft, rejected atau revision_requested. Hanya PDF, PNG dan JPEG; jenis file diperiksa dari isinya, bukan header Content-Type. Penolakan dikirim di errors[] dengan reason: empty_file, file_too_large, unsupported_type, content_mismatch.
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Param        id       path      string  true   "Achievement ID"
+// @Param        file     formData  file    true   "File Lampiran (PDF maks. 10 MB, PNG/JPEG maks. 5 MB secara default)"
+// @Param        category formData  string  false  "Kategori: certificate, photo, document, other (default other)"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{} "File kosong / kategori tidak valid / status tidak dapat diubah"
+// @Failure      409  {object}  map[string]interface{} "Prestasi masih disinkronkan ke MongoDB"
+// @Failure      413  {object}  map[string]interface{} "Ukuran file melebihi batas jenisnya"
+// @Failure      415  {object}  map[string]interface{} "Jenis file tidak didukung atau isi tidak cocok dengan ekstensi"
+// @Router       /achievements/{id}/attachments [post]
+func (s *AchievementService) AddAttachment(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	achievementID := c.Params("id")
+
+	// 1. Validasi Role
+	if profile.Role != "Mahasiswa" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Akses ditolak. Hanya Mahasiswa.", "code": "403"})
+	}
+
+	// 2. Cek Referensi di Postgres & Kepemilikan
+	ref, err := s.PgRepo.GetReferenceByID(achievementID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
+	}
+	if errPolicy := s.requireContentOwner(profile, ref); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+	if !ref.Status.IsEditable() {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Lampiran hanya dapat diubah saat prestasi berstatus draft, rejected atau revision_requested", "code": "400"})
+	}
+
+	// 3. Ambil File & Kategori dari Form
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Gagal mengambil file. Pastikan key adalah 'file'", "code": "400"})
+	}
+	category := strings.TrimSpace(c.FormValue("category", modelMongo.AttachmentOther))
+	if !containsString(modelMongo.AttachmentCategories, category) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Kategori lampiran harus salah satu dari: " + strings.Join(modelMongo.AttachmentCategories, ", "), "code": "400"})
+	}
+	checked, rejection := s.Uploads.Validate(file)
+	if rejection != nil {
+		return c.Status(rejection.Status).JSON(fiber.Map{"message": rejection.Message, "code": strconv.Itoa(rejection.Status), "errors": []upload.Rejection{*rejection}})
+	}
+
+	// 4. Cek ID Mongo & sinkronisasi dokumen sebelum file disimpan
+	mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Format MongoID di database PostgreSQL rusak/invalid", "code": "500"})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if errSync := s.requireSynced(ctx, ref.MongoAchievementID); errSync != nil {
+		return policy.Respond(c, errSync)
+	}
+
+	// 5. Simpan File & Buat Struct
+	attachment, err := s.saveUpload(ctx, checked)
+	if err != nil {
+		log.Printf("simpan lampiran prestasi %s: %v", ref.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menyimpan file fisik", "code": "500"})
+	}
+	attachment.Category = category
+	// Lampiran hanya bisa diambil lewat endpoint unduh yang memeriksa akses
+	attachment.FileUrl = fmt.Sprintf("/api/v1/achievements/%s/attachments/%s", achievementID, attachment.ID)
+
+	// 6. Update MongoDB
+	if err := s.MongoRepo.AddAttachment(ctx, mongoID, attachment); err != nil {
+		log.Printf("tambah lampiran prestasi %s: %v", ref.ID, err)
+		s.discardUploads([]modelMongo.Attachment{attachment})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menyimpan lampiran ke database", "code": "500"})
+	}
+	s.refreshView(ctx, ref.MongoAchievementID)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"message": "Lampiran berhasil ditambahkan",
+		"data": attachment,
+	})
+}
+
+// DownloadAttachment godoc
+// @Summary      Unduh Lampiran
+// @Description  Mengalirkan file lampiran prestasi setelah memeriksa akses (pemilik, dosen wali, Admin). Mendukung header Range (satu rentang) untuk unduhan sebagian, mis. PDF besar. download=true memaksa Content-Disposition attachment.
+// @Tags         Achievements
+// @Produce      octet-stream
+// @Security     BearerAuth
+// @Param        id            path      string  true   "Achievement ID"
+// @Param        attachmentId  path      string  true   "Attachment ID"
+// @Param        download      query     bool    false  "Unduh sebagai file (default tampil inline)"
+// @Param        Range         header    string  false  "Rentang byte, mis. bytes=0-1023"
+// @Success      200  {file}    file
+// @Success      206  {file}    file  "Partial Content"
+// @Failure      403  {object}  map[string]interface{} "Bukan pemilik/dosen wali"
+// @Failure      404  {object}  map[string]interface{} "Not Found"
+// @Failure      416  {object}  map[string]interface{} "Range di luar ukuran file"
+// @Router       /achievements/{id}/attachments/{attachmentId} [get]
+func (s *AchievementService) DownloadAttachment(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+
+	ref, err := s.PgRepo.GetReferenceByID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
+	}
+	if errPolicy := s.requireReadAccess(profile, ref); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+	mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Lampiran tidak ditemukan", "code": "404"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	detail, err := s.MongoRepo.GetDetailByID(ctx, mongoID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Lampiran tidak ditemukan", "code": "404"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil detail data", "code": "500"})
+	}
+	var attachment *modelMongo.Attachment
+	for i := range detail.Attachments {
+		if detail.Attachments[i].HasID(c.Params("attachmentId")) {
+			attachment = &detail.Attachments[i]
+			break
+		}
+	}
+	if attachment == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Lampiran tidak ditemukan", "code": "404"})
+	}
+	return s.serveAttachment(c, attachment)
+}
+
+// DownloadCommentAttachment godoc
+// @Summary      Unduh Lampiran Komentar
+// @Description  Mengalirkan file lampiran komentar dengan aturan akses yang sama seperti membaca prestasi (pemilik/anggota tim, dosen wali/delegasi, penerima eskalasi, Admin). Mendukung header Range seperti unduh lampiran prestasi.
+// @Tags         Achievements
+// @Produce      octet-stream
+// @Security     BearerAuth
+// @Param        id            path      string  true   "Achievement ID"
+// @Param        commentId     path      string  true   "Comment ID"
+// @Param        attachmentId  path      string  true   "Attachment ID"
+// @Param        download      query     bool    false  "Unduh sebagai file (default tampil inline)"
+// @Param        Range         header    string  false  "Rentang byte, mis. bytes=0-1023"
+// @Success      200  {file}    file
+// @Success      206  {file}    file  "Partial Content"
+// @Failure      403  {object}  map[string]interface{} "Tidak berhak melihat prestasi"
+// @Failure      404  {object}  map[string]interface{} "Not Found"
+// @Failure      416  {object}  map[string]interface{} "Range di luar ukuran file"
+// @Router       /achievements/{id}/comments/{commentId}/attachments/{attachmentId} [get]
+func (s *AchievementService) DownloadCommentAttachment(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+
+	ref, err := s.PgRepo.GetReferenceByID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Prestasi tidak ditemukan", "code": "404"})
+	}
+	if errPolicy := s.requireReadAccess(profile, ref); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+	mongoID, errMongo := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	commentID, errComment := primitive.ObjectIDFromHex(c.Params("commentId"))
+	if errMongo != nil || errComment != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Lampiran tidak ditemukan", "code": "404"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// Komentar harus milik prestasi yang aksesnya sudah diperiksa di atas
+	comment, err := s.CommentRepo.GetByID(ctx, commentID)
+	if err != nil || comment.AchievementID != mongoID {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Lampiran tidak ditemukan", "code": "404"})
+	}
+	for i := range comment.Attachments {
+		if comment.Attachments[i].HasID(c.Params("attachmentId")) {
+			return s.serveAttachment(c, &comment.Attachments[i])
+		}
+	}
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Lampiran tidak ditemukan", "code": "404"})
+}
+
+// serveAttachment mengalirkan file lampiran dari storage (seluruhnya atau satu rentang Range).
+// Akses harus sudah diperiksa caller.
+func (s *AchievementService) serveAttachment(c *fiber.Ctx, attachment *modelMongo.Attachment) error {
+	// Body dibaca setelah handler selesai, jadi pembacaan storage tidak memakai ctx yang dibatalkan di atas
+	key := attachment.ObjectKey()
+	info, err := s.Storage.Stat(context.Background(), key)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "File lampiran tidak ditemukan di storage", "code": "404"})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal membaca file lampiran", "code": "500"})
+	}
+
+	offset, length, partial, ok := parseByteRange(c.Get(fiber.HeaderRange), info.Size)
+	if !ok {
+		c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", info.Size))
+		return c.Status(fiber.StatusRequestedRangeNotSatisfiable).JSON(fiber.Map{"message": "Rentang byte di luar ukuran file", "code": "416"})
+	}
+	var obj *storage.Object
+	if partial {
+		obj, err = s.Storage.GetRange(context.Background(), key, offset, length)
+	} else {
+		obj, err = s.Storage.Get(context.Background(), key)
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal membaca file lampiran", "code": "500"})
+	}
+
+	contentType := attachment.FileType
+	if contentType == "" { contentType = info.ContentType }
+	if contentType == "" { contentType = "application/octet-stream" }
+	disposition := "inline"
+	if c.QueryBool("download", false) { disposition = "attachment" }
+	if formatted := mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}); formatted != "" {
+		disposition = formatted
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, disposition)
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderCacheControl, "private")
+	if !info.ModTime.IsZero() {
+		c.Set(fiber.HeaderLastModified, info.ModTime.UTC().Format(http.TimeFormat))
+	}
+	if !partial {
+		return c.Status(fiber.StatusOK).SendStream(obj.Body, int(info.Size))
+	}
+	c.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", offset, offset+length-1, info.Size))
+	return c.Status(fiber.StatusPartialContent).SendStream(obj.Body, int(length))
+}
+
+// DeleteAttachment godoc
+// @Summary      Hapus Lampiran
+// @Description  Menghapus satu lampiran beserta file-nya di storage. Hanya pemilik (pembuat untuk prestasi tim) saat status draft, rejected atau revision_requested.
+// @Tags         Achievements
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id            path      string  true  "Achievement ID"
+// @Param        attachmentId  path      string  true  "Attachment ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{} "Status bukan draft/rejected"
+// @Failure      403  {object}  map[string]interface{} "Bukan pemilik"
+// @Failure      404  {object}  map[string]interface{} "Not Found"
+// @Failure      409  {object}  map[string]interface{} "Prestasi masih disinkronkan ke MongoDB"
+// @Router       /achievements/{id}/attachments/{attachmentId} [delete]
+func (s *AchievementService) DeleteAttachment(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ref, mongoID, attachment, errAttachment := s.editableAttachment(ctx, c)
+	if errAttachment != nil {
+		return policy.Respond(c, errAttachment)
+	}
+
+	// Mongo dulu: bila penghapusan file gagal, yang tersisa hanya file yatim, bukan lampiran rusak
+	removed, err := s.MongoRepo.RemoveAttachment(ctx, mongoID, *attachment)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menghapus lampiran", "code": "500"})
+	}
+	if !removed {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Lampiran tidak ditemukan", "code": "404"})
+	}
+	s.deleteObject(ctx, attachment.ObjectKey())
+	s.refreshView(ctx, ref.MongoAchievementID)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "message": "Lampiran berhasil dihapus"})
+}
+
+// ReplaceAttachment godoc
+// @Summary      Ganti Lampiran
+// @Description  Mengganti file satu lampiran dengan ID dan posisi yang sama. File lama dihapus dari storage setelah data tersimpan. Kategori opsional (default kategori lama). Hanya pemilik saat status draft, rejected atau revision_requested.
+// @Tags         Achievements
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id            path      string  true   "Achievement ID"
+// @Param        attachmentId  path      string  true   "Attachment ID"
+// @Param        file          formData  file    true   "File pengganti (PDF maks. 10 MB, PNG/JPEG maks. 5 MB)"
+// @Param        category      formData  string  false  "certificate | photo | document | other"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{} "Status bukan draft/rejected"
+// @Failure      403  {object}  map[string]interface{} "Bukan pemilik"
+// @Failure      404  {object}  map[string]interface{} "Not Found"
+// @Failure      409  {object}  map[string]interface{} "Prestasi masih disinkronkan ke MongoDB"
+// @Failure      413  {object}  map[string]interface{} "File terlalu besar"
+// @Failure      415  {object}  map[string]interface{} "Jenis file tidak didukung"
+// @Router       /achievements/{id}/attachments/{attachmentId} [put]
+func (s *AchievementService) ReplaceAttachment(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	ref, mongoID, old, errAttachment := s.editableAttachment(ctx, c)
+	if errAttachment != nil {
+		return policy.Respond(c, errAttachment)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Gagal mengambil file. Pastikan key adalah 'file'", "code": "400"})
+	}
+	category := strings.TrimSpace(c.FormValue("category", old.Category))
+	if category == "" { category = modelMongo.AttachmentOther }
+	if !containsString(modelMongo.AttachmentCategories, category) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Kategori lampiran harus salah satu dari: " + strings.Join(modelMongo.AttachmentCategories, ", "), "code": "400"})
+	}
+	checked, rejection := s.Uploads.Validate(file)
+	if rejection != nil {
+		return c.Status(rejection.Status).JSON(fiber.Map{"message": rejection.Message, "code": strconv.Itoa(rejection.Status), "errors": []upload.Rejection{*rejection}})
+	}
+
+	replacement, err := s.saveUpload(ctx, checked)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menyimpan file fisik", "code": "500"})
+	}
+	// ID lama dipertahankan agar tautan yang sudah dibagikan tetap mengarah ke lampiran ini
+	replacement.ID = old.ID
+	replacement.Category = category
+	replacement.FileUrl = fmt.Sprintf("/api/v1/achievements/%s/attachments/%s", ref.ID, replacement.ID)
+
+	replaced, err := s.MongoRepo.ReplaceAttachment(ctx, mongoID, *old, replacement)
+	if err != nil || !replaced {
+		s.deleteObject(ctx, replacement.StorageKey)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menyimpan lampiran ke database", "code": "500"})
+		}
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Lampiran tidak ditemukan", "code": "404"})
+	}
+	if old.ObjectKey() != replacement.StorageKey {
+		s.deleteObject(ctx, old.ObjectKey())
+	}
+	s.refreshView(ctx, ref.MongoAchievementID)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "message": "Lampiran berhasil diganti", "data": replacement})
+}
+
+// ListAchievementTypes godoc
+// @Summary      List Tipe Prestasi
+// @Description  Mendapatkan semua tipe prestasi beserta skema field details (wajib/opsional, enum, tanggal) untuk membangun form di frontend.
+// @Tags         Achievement Types
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{} "List Tipe & Skema"
+// @Router       /achievement-types [get]
+func (s *AchievementService) ListAchievementTypes(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "data": s.Types.List()})
+}
+
+// GetAchievementType godoc
+// @Summary      Skema Tipe Prestasi
+// @Description  Mendapatkan skema details untuk satu tipe prestasi.
+// @Tags         Achievement Types
+// @Produce      json
+// @Security     BearerAuth
+// @Param        type  path      string  true  "Kode tipe (competition, publication, organization, certification, academic, other)"
+// @Success      200  {object}  map[string]interface{} "Skema Tipe"
+// @Failure      404  {object}  map[string]interface{} "Tipe tidak dikenal"
+// @Router       /achievement-types/{type} [get]
+func (s *AchievementService) GetAchievementType(c *fiber.Ctx) error {
+	t, ok := s.Types.Get(c.Params("type"))
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Tipe prestasi tidak ditemukan", "code": "404"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "data": t})
+}
+
+// validateInput: Validasi field umum + details sesuai skema tipe prestasi
+func (s *AchievementService) validateInput(req *modelMongo.AchievementInput) []achievementtype.FieldError {
+	fieldErrs := []achievementtype.FieldError{}
+	if strings.TrimSpace(req.Title) == "" {
+		fieldErrs = append(fieldErrs, achievementtype.FieldError{Field: "title", Message: "Judul wajib diisi"})
+	}
+	return append(fieldErrs, s.Types.Validate(req.AchievementType, req.Details)...)
+}
+
+// requireContentOwner: Mahasiswa pemilik. Untuk prestasi tim hanya pembuat yang boleh mengubah
+// konten/status; anggota tertaut cukup mengonfirmasi partisipasi.
+func (s *AchievementService) requireContentOwner(profile modelPostgres.UserProfile, ref *modelPostgres.AchievementReference) *fiber.Error {
+	if errPolicy := s.Policy.RequireOwner(profile, ref.StudentID); errPolicy != nil {
+		return errPolicy
+	}
+	if ref.CoMember {
+		return fiber.NewError(fiber.StatusForbidden, "Hanya pembuat prestasi tim yang dapat mengubah data ini.")
+	}
+	return nil
+}
+
+// buildTeam memvalidasi teamMembers dan mengembalikan daftar tim lengkap (pembuat selalu
+// termasuk, di urutan pertama). Input kosong = prestasi individu.
+func (s *AchievementService) buildTeam(creatorID string, input []modelMongo.TeamMember) ([]modelMongo.TeamMember, []achievementtype.FieldError) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	fieldErrs := []achievementtype.FieldError{}
+	creatorRole := ""
+	captains := 0
+	seen := map[string]bool{}
+	var others []modelMongo.TeamMember
+	for i, member := range input {
+		field := fmt.Sprintf("teamMembers[%d]", i)
+		member.StudentID = strings.TrimSpace(member.StudentID)
+		if member.Role == "" { member.Role = modelPostgres.TeamRoleMember }
+
+		if member.StudentID == "" {
+			fieldErrs = append(fieldErrs, achievementtype.FieldError{Field: field + ".student_id", Message: "ID mahasiswa wajib diisi"})
+			continue
+		}
+		if member.Role != modelPostgres.TeamRoleCaptain && member.Role != modelPostgres.TeamRoleMember {
+			fieldErrs = append(fieldErrs, achievementtype.FieldError{Field: field + ".role", Message: "Role harus captain atau member"})
+			continue
+		}
+		if seen[member.StudentID] {
+			fieldErrs = append(fieldErrs, achievementtype.FieldError{Field: field + ".student_id", Message: "Anggota tim duplikat"})
+			continue
+		}
+		seen[member.StudentID] = true
+		if member.Role == modelPostgres.TeamRoleCaptain { captains++ }
+
+		if member.StudentID == creatorID {
+			creatorRole = member.Role
+		} else {
+			others = append(others, member)
+		}
+	}
+	if captains > 1 {
+		fieldErrs = append(fieldErrs, achievementtype.FieldError{Field: "teamMembers", Message: "Hanya boleh ada satu captain"})
+	}
+	if len(others) == 0 {
+		fieldErrs = append(fieldErrs, achievementtype.FieldError{Field: "teamMembers", Message: "Prestasi tim minimal memiliki satu anggota selain Anda"})
+	}
+	if len(fieldErrs) > 0 {
+		return nil, fieldErrs
+	}
+
+	otherIDs := make([]string, len(others))
+	for i, member := range others {
+		otherIDs[i] = member.StudentID
+	}
+	existing, err := s.PgRepo.FindExistingStudentIDs(otherIDs)
+	if err != nil {
+		return nil, []achievementtype.FieldError{{Field: "teamMembers", Message: "Gagal memeriksa data mahasiswa"}}
+	}
+	found := map[string]bool{}
+	for _, id := range existing {
+		found[id] = true
+	}
+	for i, member := range others {
+		if !found[member.StudentID] {
+			fieldErrs = append(fieldErrs, achievementtype.FieldError{Field: fmt.Sprintf("teamMembers[%d].student_id", i), Message: "Mahasiswa tidak ditemukan: " + member.StudentID})
+		}
+	}
+	if len(fieldErrs) > 0 {
+		return nil, fieldErrs
+	}
+
+	// Pembuat yang tidak mencantumkan dirinya menjadi captain bila belum ada captain
+	if creatorRole == "" {
+		creatorRole = modelPostgres.TeamRoleMember
+		if captains == 0 { creatorRole = modelPostgres.TeamRoleCaptain }
+	}
+	return append([]modelMongo.TeamMember{{StudentID: creatorID, Role: creatorRole}}, others...), nil
+}
+
+// revisionTarget: Cek akses (pemilik/dosen wali/admin) lalu kembalikan ID dokumen Mongo prestasi
+func (s *AchievementService) revisionTarget(c *fiber.Ctx) (primitive.ObjectID, *fiber.Error) {
+	profile := middleware.GetUserProfileFromContext(c)
+	ref, err := s.PgRepo.GetReferenceByID(c.Params("id"))
+	if err != nil || ref == nil {
+		return primitive.NilObjectID, fiber.NewError(fiber.StatusNotFound, "Prestasi tidak ditemukan")
+	}
+	if errPolicy := s.requireReadAccess(profile, ref); errPolicy != nil {
+		return primitive.NilObjectID, errPolicy
+	}
+	mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err != nil {
+		return primitive.NilObjectID, fiber.NewError(fiber.StatusInternalServerError, "ID Mongo corrupt")
+	}
+	return mongoID, nil
+}
+
+// ensureBaselineRevision: Prestasi yang dibuat sebelum fitur revisi belum punya versi 1;
+// simpan konten saat ini sebagai versi 1 sebelum ditimpa update pertama.
+func (s *AchievementService) ensureBaselineRevision(ctx context.Context, mongoID primitive.ObjectID) error {
+	latest, err := s.RevisionRepo.LatestVersion(ctx, mongoID)
+	if err != nil || latest > 0 { return err }
+
+	current, err := s.MongoRepo.GetDetailByID(ctx, mongoID)
+	if err != nil { return err }
+	if current == nil { return nil }
+	// Pembuat konten awal tidak tercatat, editedBy dibiarkan kosong
+	_, err = s.RevisionRepo.Create(ctx, revision.Snapshot(current, ""))
+	return err
+}
+
+// activeStage mengembalikan workflow & tahap verifikasi yang sedang berjalan, sekaligus
+// memastikan user berhak memproses tahap tersebut. onBehalfOf berisi user ID dosen wali asli
+// bila user memproses lewat delegasi.
+func (s *AchievementService) activeStage(ref *modelPostgres.AchievementReference, profile modelPostgres.UserProfile) (workflow.Definition, workflow.Stage, string, *fiber.Error) {
+	flow, stage, errMsg := s.resolveStage(ref, profile)
+	if errMsg != nil {
+		return flow, stage, "", errMsg
+	}
+	// Selain peran yang sesuai tahap, verifikator harus berhak atas mahasiswa pemilik prestasi
+	// (Dosen Wali hanya untuk bimbingannya sendiri atau bimbingan delegator aktif).
+	// Penerima eskalasi SLA tidak dibatasi bimbingan.
+	if ref.IsEscalatedTo(profile) {
+		return flow, stage, "", nil
+	}
+	scope, errPolicy := s.Policy.ScopeFor(profile)
+	if errPolicy != nil {
+		return flow, stage, "", errPolicy
+	}
+	if !scope.AllowsStudent(ref.StudentID) {
+		return flow, stage, "", fiber.NewError(fiber.StatusForbidden, "Anda tidak berhak mengakses data prestasi mahasiswa ini.")
+	}
+	return flow, stage, scope.DelegatorFor(ref.StudentID), nil
+}
+
+// resolveStage: Cek status submitted, tahap aktif, dan peran/permission tahap (tanpa cek kepemilikan).
+// Tahap yang dieskalasi karena melewati SLA juga boleh diproses Admin penerima eskalasi; verifikator
+// cadangan dengan peran lain tetap harus memenuhi peran tahap tersebut.
+func (s *AchievementService) resolveStage(ref *modelPostgres.AchievementReference, profile modelPostgres.UserProfile) (workflow.Definition, workflow.Stage, *fiber.Error) {
+	if ref.Status != modelPostgres.StatusSubmitted {
+		return workflow.Definition{}, workflow.Stage{}, fiber.NewError(fiber.StatusBadRequest, "Prestasi tidak sedang menunggu verifikasi (status bukan submitted)")
+	}
+
+	flow := s.Workflow.Get(derefString(ref.WorkflowCode))
+	stage, ok := flow.CurrentStage(derefString(ref.CurrentStage))
+	if !ok {
+		return flow, stage, fiber.NewError(fiber.StatusConflict, "Tahap verifikasi tidak dikenali pada konfigurasi workflow saat ini")
+	}
+	if !stage.Allows(profile) && !(profile.Role == "Admin" && ref.IsEscalatedTo(profile)) {
+		return flow, stage, fiber.NewError(fiber.StatusForbidden, fmt.Sprintf("Akses ditolak. Tahap '%s' harus diproses oleh %s.", stage.Name, strings.Join(stage.Roles, "/")))
+	}
+	return flow, stage, nil
+}
+
+// approveStage menyetujui tahap aktif: diteruskan ke tahap berikutnya bila ada, atau
+// menyelesaikan verifikasi bila ini tahap terakhir.
+func (s *AchievementService) approveStage(ref *modelPostgres.AchievementReference, flow workflow.Definition, stage workflow.Stage, actorID string, onBehalfOf string, note string) (*modelPostgres.AchievementReference, error) {
+	if next, hasNext := flow.NextStage(stage.Code); hasNext {
+		stageNote := fmt.Sprintf("Tahap '%s' disetujui, diteruskan ke '%s'", stage.Name, next.Name)
+		if note != "" { stageNote += ": " + note }
+		return s.PgRepo.AdvanceStage(ref.ID, actorID, onBehalfOf, derefString(ref.CurrentStage), next.Code, stageNote)
+	}
+	updatedRef, err := s.PgRepo.VerifyAchievement(ref.ID, actorID, onBehalfOf, derefString(ref.CurrentStage), note)
+	if err != nil {
+		return nil, err
+	}
+	s.freezePoints(updatedRef)
+	return updatedRef, nil
+}
+
+// calculatePoints menghitung poin dengan aturan poin terbaru di database
+func (s *AchievementService) calculatePoints(achievementType string, details map[string]interface{}) (points.Result, error) {
+	rules, err := s.PointRepo.GetAllRules()
+	if err != nil {
+		return points.Result{}, err
+	}
+	return points.Calculate(rules, achievementType, details), nil
+}
+
+// dispatchOutbox menerapkan event outbox satu dokumen segera setelah commit. Kegagalan hanya dicatat di
+// log: niat penulisan sudah tersimpan di PostgreSQL dan akan diulang oleh worker outbox.
+func (s *AchievementService) dispatchOutbox(ctx context.Context, mongoID string) bool {
+	synced, err := s.Outbox.Dispatch(ctx, mongoID)
+	if err != nil {
+		log.Printf("outbox prestasi %s tertunda: %v", mongoID, err)
+	}
+	return synced
+}
+
+// requireSynced dipanggil sebelum handler menulis langsung ke MongoDB. Event jatuh tempo diterapkan
+// dulu; bila masih ada yang tertunda (mis. create yang belum sampai ke Mongo) penulisan ditolak 409,
+// karena UpdateByID pada dokumen yang belum ada tidak error dan hasilnya tertimpa event create.
+func (s *AchievementService) requireSynced(ctx context.Context, mongoID string) *fiber.Error {
+	if !s.dispatchOutbox(ctx, mongoID) {
+		return fiber.NewError(fiber.StatusConflict, "Prestasi masih disinkronkan, coba lagi")
+	}
+	return nil
+}
+
+// syncView menerapkan event outbox transisi status agar read model langsung diperbarui (gagal = diulang worker)
+func (s *AchievementService) syncView(ref *modelPostgres.AchievementReference) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.dispatchOutbox(ctx, ref.MongoAchievementID)
+}
+
+// refreshView memproyeksikan ulang read model setelah penulisan yang hanya menyentuh MongoDB
+func (s *AchievementService) refreshView(ctx context.Context, mongoID string) {
+	if _, err := s.Outbox.Refresh(ctx, mongoID); err != nil {
+		log.Printf("refresh read model prestasi %s tertunda: %v", mongoID, err)
+	}
+}
+
+// freezePoints menghitung poin final dan membekukannya saat prestasi terverifikasi. Kegagalan
+// hanya dicatat di log: status verified sudah committed, dan recalculate tidak pernah menyentuh
+// prestasi verified sehingga poin tidak akan berubah lagi.
+func (s *AchievementService) freezePoints(ref *modelPostgres.AchievementReference) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err != nil {
+		log.Printf("freeze poin %s: mongo id tidak valid: %v", ref.ID, err)
+		return
+	}
+	detail, err := s.MongoRepo.GetDetailByID(ctx, mongoID)
+	if err != nil || detail == nil {
+		log.Printf("freeze poin %s: detail tidak ditemukan: %v", ref.ID, err)
+		return
+	}
+	result, err := s.calculatePoints(detail.AchievementType, detail.Details)
+	if err != nil {
+		log.Printf("freeze poin %s: gagal menghitung poin: %v", ref.ID, err)
+		return
+	}
+	if err := s.MongoRepo.FreezePoints(ctx, mongoID, result.Points); err != nil {
+		log.Printf("freeze poin %s: %v", ref.ID, err)
+		return
+	}
+	if result.Points != detail.Points {
+		s.refreshView(ctx, ref.MongoAchievementID)
+	}
+}
+
+// requireReadAccess: Aturan baca prestasi (detail, riwayat, revisi & komentar). Selain scope
+// policy, penerima eskalasi SLA boleh melihat prestasi yang ditugaskan kepadanya.
+func (s *AchievementService) requireReadAccess(profile modelPostgres.UserProfile, ref *modelPostgres.AchievementReference) *fiber.Error {
+	if ref.IsEscalatedTo(profile) {
+		return nil
+	}
+	return s.Policy.CanAccessStudent(profile, ref.StudentID)
+}
+
+// editableAttachment: Lampiran yang boleh diubah mahasiswa pemilik, dengan aturan status yang sama
+// seperti UpdatePrestasi (draft/rejected/revision_requested).
+func (s *AchievementService) editableAttachment(ctx context.Context, c *fiber.Ctx) (*modelPostgres.AchievementReference, primitive.ObjectID, *modelMongo.Attachment, *fiber.Error) {
+	profile := middleware.GetUserProfileFromContext(c)
+	if profile.Role != "Mahasiswa" {
+		return nil, primitive.NilObjectID, nil, fiber.NewError(fiber.StatusForbidden, "Akses ditolak. Hanya Mahasiswa.")
+	}
+	ref, err := s.PgRepo.GetReferenceByID(c.Params("id"))
+	if err != nil {
+		return nil, primitive.NilObjectID, nil, fiber.NewError(fiber.StatusNotFound, "Prestasi tidak ditemukan")
+	}
+	if errPolicy := s.requireContentOwner(profile, ref); errPolicy != nil {
+		return nil, primitive.NilObjectID, nil, errPolicy
+	}
+	if !ref.Status.IsEditable() {
+		return nil, primitive.NilObjectID, nil, fiber.NewError(fiber.StatusBadRequest, "Lampiran hanya dapat diubah saat prestasi berstatus draft, rejected atau revision_requested")
+	}
+
+	mongoID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+	if err != nil {
+		return nil, primitive.NilObjectID, nil, fiber.NewError(fiber.StatusNotFound, "Lampiran tidak ditemukan")
+	}
+	if errSync := s.requireSynced(ctx, ref.MongoAchievementID); errSync != nil {
+		return nil, primitive.NilObjectID, nil, errSync
+	}
+	detail, err := s.MongoRepo.GetDetailByID(ctx, mongoID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, primitive.NilObjectID, nil, fiber.NewError(fiber.StatusNotFound, "Lampiran tidak ditemukan")
+	}
+	if err != nil {
+		return nil, primitive.NilObjectID, nil, fiber.NewError(fiber.StatusInternalServerError, "Gagal mengambil detail data")
+	}
+	for i := range detail.Attachments {
+		if detail.Attachments[i].HasID(c.Params("attachmentId")) {
+			return ref, mongoID, &detail.Attachments[i], nil
+		}
+	}
+	return nil, primitive.NilObjectID, nil, fiber.NewError(fiber.StatusNotFound, "Lampiran tidak ditemukan")
+}
+
+// deleteObject: Kegagalan hanya dicatat; file yatim tidak mempengaruhi data lampiran
+func (s *AchievementService) deleteObject(ctx context.Context, key string) {
+	if key == "" { return }
+	if err := s.Storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("gagal menghapus file lampiran %s: %v", key, err)
+	}
+}
+
+// discardUploads menghapus file yang sudah tersimpan bila datanya gagal disimpan. Context sendiri
+// karena context request bisa sudah habis (penyebab kegagalannya).
+func (s *AchievementService) discardUploads(attachments []modelMongo.Attachment) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, attachment := range attachments {
+		s.deleteObject(ctx, attachment.StorageKey)
+	}
+}
+
+// validateUploads memeriksa semua file sekaligus agar frontend bisa menampilkan setiap penolakan
+func (s *AchievementService) validateUploads(files []*multipart.FileHeader) ([]*upload.File, []upload.Rejection) {
+	checked := []*upload.File{}
+	rejections := []upload.Rejection{}
+	for _, file := range files {
+		ok, rejection := s.Uploads.Validate(file)
+		if rejection != nil {
+			rejections = append(rejections, *rejection)
+			continue
+		}
+		checked = append(checked, ok)
+	}
+	return checked, rejections
+}
+
+// saveUpload menyimpan file yang sudah divalidasi ke storage dan mengembalikan metadata lampirannya.
+// Key memakai nama yang sudah disanitasi; content type hasil sniffing, bukan header dari client.
+func (s *AchievementService) saveUpload(ctx context.Context, file *upload.File) (modelMongo.Attachment, error) {
+	src, err := file.Header.Open()
+	if err != nil {
+		return modelMongo.Attachment{}, err
+	}
+	defer src.Close()
+
+	id := primitive.NewObjectID().Hex()
+	key := fmt.Sprintf("%s-%s", id, file.SafeName)
+	if err := s.Storage.Put(ctx, key, src, file.Header.Size, file.ContentType); err != nil {
+		return modelMongo.Attachment{}, err
+	}
+
+	return modelMongo.Attachment{
+		ID:         id,
+		FileName:   file.DisplayName,
+		FileUrl:    s.Storage.URL(key),
+		FileType:   file.ContentType,
+		StorageKey: key,
+		UploadedAt: time.Now(),
+	}, nil
+}
+
+// parseByteRange membaca header Range satu rentang ("bytes=a-b", "bytes=a-", "bytes=-n").
+// Header kosong, tidak valid, atau multi-rentang diabaikan (file dikirim utuh, partial=false).
+// ok=false berarti rentang tidak dapat dipenuhi (416).
+func parseByteRange(header string, size int64) (offset, length int64, partial, ok bool) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, size, false, true
+	}
+	startStr, endStr, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, size, false, true
+	}
+
+	if startStr == "" {
+		n, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || n < 0 { return 0, size, false, true }
+		if n == 0 || size == 0 { return 0, 0, false, false }
+		if n > size { n = size }
+		return size - n, n, true, true
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 { return 0, size, false, true }
+	end := size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start { return 0, size, false, true }
+		if end > size-1 { end = size - 1 }
+	}
+	if start >= size { return 0, 0, false, false }
+	return start, end - start + 1, true, true
+}
+
+// buildCommentThreads mengelompokkan komentar (urut waktu) menjadi komentar utama + balasan.
+// Balasan yang induknya tidak ditemukan ditampilkan sebagai komentar utama.
+func buildCommentThreads(comments []modelMongo.AchievementComment) []modelMongo.CommentThread {
+	threads := []modelMongo.CommentThread{}
+	index := map[primitive.ObjectID]int{}
+	for _, comment := range comments {
+		if comment.ParentID != nil {
+			if i, ok := index[*comment.ParentID]; ok {
+				threads[i].Replies = append(threads[i].Replies, comment)
+				continue
+			}
+		}
+		index[comment.ID] = len(threads)
+		threads = append(threads, modelMongo.CommentThread{AchievementComment: comment, Replies: []modelMongo.AchievementComment{}})
+	}
+	return threads
+}
+
+// applyListScope: Scope role untuk daftar prestasi. Draft hanya untuk pemilik & Admin,
+// bimbingan delegator hanya antrean Submitted.
+func applyListScope(filter *modelMongo.AchievementViewFilter, scope *policy.Scope) {
+	filter.All = scope.All
+	filter.IncludeDrafts = scope.All || scope.Role == "Mahasiswa"
+	for _, studentID := range scope.StudentIDs {
+		if scope.DelegatorFor(studentID) != "" {
+			filter.PendingStudentIDs = append(filter.PendingStudentIDs, studentID)
+		} else {
+			filter.StudentIDs = append(filter.StudentIDs, studentID)
+		}
+	}
+}
+
+// viewListItem: Satu baris daftar/pencarian prestasi dari read model
+func viewListItem(view modelMongo.AchievementView, scope *policy.Scope) fiber.Map {
+	item := fiber.Map{
+		"id":              view.ID,
+		"student_id":      view.StudentID,
+		"status":          view.Status,
+		"submitted_at":    view.SubmittedAt,
+		"verified_at":     view.VerifiedAt,
+		"verified_by":     view.VerifiedBy,
+		"rejection_note":  view.RejectionNote,
+		"revision_count":  view.RevisionCount,
+		"revision_note":   view.RevisionNote,
+		"team_role":       view.TeamRole,
+		"co_member":       view.CoMember,
+		"participation_confirmed_at": view.ParticipationConfirmedAt,
+		"title":           view.Title,
+		"achievementType": view.AchievementType,
+		"description":     view.Description,
+		"points":          view.Points,
+		"tags":            view.Tags,
+		"details":         view.Details,
+		"attachments":     view.Attachments,
+		"created_at":      view.CreatedAt,
+	}
+	if delegator := scope.DelegatorFor(view.StudentID); delegator != "" {
+		item["on_behalf_of"] = delegator
+	}
+	return item
+}
+
+// parseListQuery membaca query string ListAllAchievements menjadi filter read model
+func parseListQuery(c *fiber.Ctx) (modelMongo.AchievementViewFilter, int, error) {
+	filter := modelMongo.AchievementViewFilter{Sort: "created_at", DateField: "created_at", Desc: true}
+
+	page := c.QueryInt("page", 1)
+	filter.Limit = c.QueryInt("limit", modelMongo.DefaultListLimit)
+	if page < 1 {
+		return filter, 0, errors.New("page minimal 1")
+	}
+	if filter.Limit < 1 || filter.Limit > modelMongo.MaxListLimit {
+		return filter, 0, fmt.Errorf("limit harus di antara 1 dan %d", modelMongo.MaxListLimit)
+	}
+	filter.Offset = (page - 1) * filter.Limit
+
+	if sort := c.Query("sort"); sort != "" {
+		if !containsString(modelMongo.ListSortFields, sort) {
+			return filter, 0, fmt.Errorf("sort harus salah satu dari: %s", strings.Join(modelMongo.ListSortFields, ", "))
+		}
+		filter.Sort = sort
+	}
+	switch c.Query("order", "desc") {
+	case "desc":
+	case "asc":
+		filter.Desc = false
+	default:
+		return filter, 0, errors.New("order harus asc atau desc")
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		if !containsString(modelMongo.CursorSortFields, filter.Sort) {
+			return filter, 0, fmt.Errorf("cursor hanya didukung untuk sort: %s", strings.Join(modelMongo.CursorSortFields, ", "))
+		}
+		value, id, ok := decodeListCursor(cursor)
+		if !ok {
+			return filter, 0, errors.New("cursor tidak valid")
+		}
+		filter.CursorValue, filter.CursorID, filter.Offset = &value, id, 0
+	}
+
+	for _, status := range splitQueryList(c.Query("status")) {
+		st := modelPostgres.AchievementStatus(status)
+		if !st.IsOverridable() {
+			return filter, 0, fmt.Errorf("status tidak dikenal: %s", status)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+	filter.StudentID = strings.TrimSpace(c.Query("studentId"))
+
+	if field := c.Query("dateField"); field != "" {
+		if !containsString(modelMongo.ListSortFields, field) {
+			return filter, 0, fmt.Errorf("dateField harus salah satu dari: %s", strings.Join(modelMongo.ListSortFields, ", "))
+		}
+		filter.DateField = field
+	}
+	if from := c.Query("dateFrom"); from != "" {
+		t, err := time.Parse(achievementtype.DateLayout, from)
+		if err != nil {
+			return filter, 0, errors.New("dateFrom harus berformat YYYY-MM-DD")
+		}
+		filter.DateFrom = &t
+	}
+	if to := c.Query("dateTo"); to != "" {
+		t, err := time.Parse(achievementtype.DateLayout, to)
+		if err != nil {
+			return filter, 0, errors.New("dateTo harus berformat YYYY-MM-DD")
+		}
+		end := t.AddDate(0, 0, 1)
+		filter.DateTo = &end
+	}
+	if filter.DateFrom != nil && filter.DateTo != nil && !filter.DateFrom.Before(*filter.DateTo) {
+		return filter, 0, errors.New("dateFrom tidak boleh setelah dateTo")
+	}
+
+	filter.AchievementType = strings.TrimSpace(c.Query("achievementType"))
+	filter.Tags = splitQueryList(c.Query("tags"))
+	for key, target := range map[string]**int{"minPoints": &filter.MinPoints, "maxPoints": &filter.MaxPoints} {
+		raw := c.Query(key)
+		if raw == "" { continue }
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return filter, 0, fmt.Errorf("%s harus berupa bilangan bulat", key)
+		}
+		*target = &n
+	}
+	if filter.MinPoints != nil && filter.MaxPoints != nil && *filter.MinPoints > *filter.MaxPoints {
+		return filter, 0, errors.New("minPoints tidak boleh lebih besar dari maxPoints")
+	}
+	return filter, page, nil
+}
+
+// encodeListCursor: Cursor = nilai kolom sort + id baris terakhir (keyset), dikodekan base64 URL-safe
+func encodeListCursor(view modelMongo.AchievementView, sort string) string {
+	value := view.CreatedAt
+	if sort == "updated_at" { value = view.UpdatedAt }
+	return base64.RawURLEncoding.EncodeToString([]byte(value.Format(time.RFC3339Nano) + "|" + view.ID))
+}
+
+func decodeListCursor(cursor string) (time.Time, string, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil { return time.Time{}, "", false }
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || !uuidPattern.MatchString(parts[1]) { return time.Time{}, "", false }
+	value, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil { return time.Time{}, "", false }
+	return value, parts[1], true
+}
+
+// splitQueryList memecah nilai query "a,b,c" dan membuang elemen kosong
+func splitQueryList(raw string) []string {
+	var list []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" { list = append(list, item) }
+	}
+	return list
+}
+
+func containsString(list []string, v string) bool {
+	for _, item := range list {
+		if item == v { return true }
+	}
+	return false
+}
+
+func derefString(v *string) string {
+	if v == nil { return "" }
+	return *v
+}
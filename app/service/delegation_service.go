@@ -0,0 +1,181 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/policy"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
+	"github.com/safrizal-hk/uas-gofiber/middleware"
+)
+
+type DelegationService struct {
+	DelegationRepo repo_postgre.DelegationRepository
+	Policy         *policy.Policy
+	Now            func() time.Time
+}
+
+func NewDelegationService(delegationRepo repo_postgre.DelegationRepository, achievementRepo repo_postgre.AchievementPGRepository) *DelegationService {
+	return &DelegationService{
+		DelegationRepo: delegationRepo,
+		Policy:         policy.New(achievementRepo),
+		Now:            time.Now,
+	}
+}
+
+// CreateDelegation godoc
+// @Summary      Buat Delegasi Verifikasi
+// @Description  Melimpahkan verifikasi mahasiswa bimbingan ke dosen lain selama jendela waktu tertentu (maks. 180 hari), misalnya saat cuti. Dosen Wali mendelegasikan bimbingannya sendiri; Admin wajib mengisi delegator_id. Satu delegator hanya boleh punya satu delegasi pada waktu yang sama.
+// @Tags         Delegations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body body      model_postgre.DelegationRequest true "Delegasi (starts_at/ends_at format RFC3339)"
+// @Success      201  {object}  map[string]interface{} "Created"
+// @Failure      400  {object}  map[string]interface{} "Bad Request"
+// @Failure      403  {object}  map[string]interface{} "Forbidden"
+// @Failure      409  {object}  map[string]interface{} "Bertumpang tindih dengan delegasi lain"
+// @Router       /delegations [post]
+func (s *DelegationService) CreateDelegation(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	scope, errPolicy := s.Policy.ScopeFor(profile)
+	if errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+
+	req := new(model_postgre.DelegationRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Input tidak valid (starts_at/ends_at harus format RFC3339)", "code": "400"})
+	}
+	req.DelegatorID = strings.TrimSpace(req.DelegatorID)
+	req.DelegateID = strings.TrimSpace(req.DelegateID)
+	req.Reason = strings.TrimSpace(req.Reason)
+
+	switch {
+	case scope.All:
+		if req.DelegatorID == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "delegator_id wajib diisi", "code": "400"})
+		}
+	case scope.LecturerID != "":
+		if req.DelegatorID != "" && req.DelegatorID != scope.LecturerID {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Dosen Wali hanya dapat mendelegasikan bimbingannya sendiri", "code": "403"})
+		}
+		req.DelegatorID = scope.LecturerID
+	default:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Hanya Admin dan Dosen Wali yang dapat membuat delegasi", "code": "403"})
+	}
+
+	if msg := s.validateDelegation(req); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": msg, "code": "400"})
+	}
+
+	delegation, err := s.DelegationRepo.CreateDelegation(req, profile.ID)
+	if err != nil {
+		if errors.Is(err, repo_postgre.ErrDelegationOverlap) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": "Delegator sudah memiliki delegasi lain pada rentang waktu tersebut. Cabut delegasi lama terlebih dahulu.", "code": "409"})
+		}
+		if strings.Contains(err.Error(), "violates foreign key constraint") || strings.Contains(err.Error(), "invalid input syntax for type uuid") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "delegator_id/delegate_id bukan ID dosen yang valid", "code": "400"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal membuat delegasi", "code": "500"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"status": "success", "message": "Delegasi verifikasi dibuat", "data": delegation})
+}
+
+// ListDelegations godoc
+// @Summary      List Delegasi Verifikasi
+// @Description  Admin melihat semua delegasi; Dosen Wali melihat delegasi di mana ia menjadi delegator atau delegate. Gunakan active=true untuk hanya delegasi yang sedang berlaku.
+// @Tags         Delegations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        active  query     bool  false  "Hanya delegasi yang sedang berlaku"
+// @Success      200  {object}  map[string]interface{} "List Delegasi"
+// @Failure      403  {object}  map[string]interface{} "Forbidden"
+// @Router       /delegations [get]
+func (s *DelegationService) ListDelegations(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	scope, errPolicy := s.Policy.ScopeFor(profile)
+	if errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+	if !scope.All && scope.LecturerID == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Hanya Admin dan Dosen Wali yang dapat melihat delegasi", "code": "403"})
+	}
+
+	delegations, err := s.DelegationRepo.ListDelegations(scope.LecturerID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil delegasi", "code": "500"})
+	}
+	if c.QueryBool("active", false) {
+		now := s.Now()
+		active := []model_postgre.VerificationDelegation{}
+		for _, d := range delegations {
+			if d.IsActive(now) { active = append(active, d) }
+		}
+		delegations = active
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "data": delegations})
+}
+
+// RevokeDelegation godoc
+// @Summary      Cabut Delegasi Verifikasi
+// @Description  Mengakhiri delegasi lebih awal. Hanya Admin atau dosen delegator.
+// @Tags         Delegations
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Delegation ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{} "Bukan delegator"
+// @Failure      404  {object}  map[string]interface{} "Tidak ditemukan"
+// @Failure      409  {object}  map[string]interface{} "Sudah dicabut"
+// @Router       /delegations/{id} [delete]
+func (s *DelegationService) RevokeDelegation(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	scope, errPolicy := s.Policy.ScopeFor(profile)
+	if errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+
+	delegation, err := s.DelegationRepo.GetDelegationByID(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Delegasi tidak ditemukan", "code": "404"})
+	}
+	if !scope.All && delegation.DelegatorID != scope.LecturerID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Hanya Admin atau dosen delegator yang dapat mencabut delegasi", "code": "403"})
+	}
+
+	revoked, err := s.DelegationRepo.RevokeDelegation(delegation.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": "Delegasi sudah dicabut", "code": "409"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mencabut delegasi", "code": "500"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "message": "Delegasi dicabut", "data": revoked})
+}
+
+func (s *DelegationService) validateDelegation(req *model_postgre.DelegationRequest) string {
+	if req.DelegateID == "" {
+		return "delegate_id wajib diisi"
+	}
+	if req.DelegateID == req.DelegatorID {
+		return "Delegate tidak boleh sama dengan delegator"
+	}
+	if req.StartsAt.IsZero() || req.EndsAt.IsZero() {
+		return "starts_at dan ends_at wajib diisi"
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		return "ends_at harus setelah starts_at"
+	}
+	if !req.EndsAt.After(s.Now()) {
+		return "ends_at sudah lewat"
+	}
+	if req.EndsAt.Sub(req.StartsAt) > time.Duration(model_postgre.MaxDelegationDays)*24*time.Hour {
+		return fmt.Sprintf("Delegasi maksimal %d hari", model_postgre.MaxDelegationDays)
+	}
+	return ""
+}
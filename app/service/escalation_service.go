@@ -0,0 +1,95 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/policy"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
+	"github.com/safrizal-hk/uas-gofiber/middleware"
+)
+
+type EscalationService struct {
+	EscalationRepo repo_postgre.EscalationRepository
+	SettingRepo    repo_postgre.SettingRepository
+	Policy         *policy.Policy
+	Now            func() time.Time
+}
+
+func NewEscalationService(escalationRepo repo_postgre.EscalationRepository, achievementRepo repo_postgre.AchievementPGRepository, settingRepo repo_postgre.SettingRepository) *EscalationService {
+	return &EscalationService{
+		EscalationRepo: escalationRepo,
+		SettingRepo:    settingRepo,
+		Policy:         policy.New(achievementRepo),
+		Now:            time.Now,
+	}
+}
+
+// ListOverdue godoc
+// @Summary      Prestasi Melewati SLA Verifikasi
+// @Description  Daftar prestasi Submitted yang tertahan di tahap verifikasi aktif lebih lama dari verification_sla_days, dikelompokkan per Dosen Wali. Admin melihat semua (opsional filter advisor_id), Dosen Wali hanya mahasiswa bimbingannya. overdue_days = jumlah hari melewati SLA.
+// @Tags         Achievements
+// @Produce      json
+// @Security     BearerAuth
+// @Param        advisor_id  query     string  false  "Filter Lecturer ID (khusus Admin)"
+// @Success      200  {object}  map[string]interface{} "Overdue per Dosen Wali"
+// @Failure      403  {object}  map[string]interface{} "Forbidden"
+// @Failure      500  {object}  map[string]interface{} "Internal Server Error"
+// @Router       /achievements/overdue [get]
+func (s *EscalationService) ListOverdue(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+
+	scope, errPolicy := s.Policy.ScopeFor(profile)
+	if errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
+	var lecturerID string
+	switch {
+	case scope.All:
+		lecturerID = c.Query("advisor_id")
+	case scope.LecturerID != "":
+		lecturerID = scope.LecturerID
+	default:
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Hanya Admin dan Dosen Wali yang dapat melihat prestasi overdue", "code": "403"})
+	}
+
+	days, err := s.SettingRepo.GetIntSetting(model_postgre.SettingVerificationSLADays, model_postgre.DefaultVerificationSLADays)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal membaca pengaturan SLA", "code": "500"})
+	}
+	if days < 1 { days = model_postgre.DefaultVerificationSLADays }
+
+	now := s.Now()
+	items, err := s.EscalationRepo.GetOverdueReferences(now.AddDate(0, 0, -days), lecturerID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil prestasi overdue", "code": "500"})
+	}
+
+	groups := []model_postgre.AdvisorOverdue{}
+	index := map[string]int{}
+	for _, item := range items {
+		item.OverdueDays = int(now.Sub(item.StageEnteredAt).Hours()/24) - days
+		key := derefString(item.AdvisorID)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, model_postgre.AdvisorOverdue{AdvisorID: item.AdvisorID, AdvisorName: item.AdvisorName, Items: []model_postgre.OverdueAchievement{}})
+		}
+		groups[i].Items = append(groups[i].Items, item)
+		groups[i].Total++
+	}
+	// Dosen dengan tunggakan terbanyak ditampilkan lebih dulu; item di tiap grup tetap urut dari yang terlama
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Total > groups[j].Total })
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"sla_days": days,
+			"total":    len(items),
+			"advisors": groups,
+		},
+	})
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/safrizal-hk/uas-gofiber/app/achievementtype"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/outbox"
+	"github.com/safrizal-hk/uas-gofiber/app/points"
+	"github.com/safrizal-hk/uas-gofiber/app/readmodel"
+	repo_mongo "github.com/safrizal-hk/uas-gofiber/app/repository/mongo"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PointRuleService struct {
+	PointRepo       repo_postgre.PointRuleRepository
+	AchievementRepo repo_postgre.AchievementPGRepository
+	MongoRepo       repo_mongo.AchievementMongoRepository
+	Outbox          *outbox.Dispatcher
+	Types           *achievementtype.Registry
+}
+
+func NewPointRuleService(pointRepo repo_postgre.PointRuleRepository, achievementRepo repo_postgre.AchievementPGRepository, mongoRepo repo_mongo.AchievementMongoRepository, outboxRepo repo_postgre.OutboxRepository, viewRepo repo_mongo.AchievementViewRepository) *PointRuleService {
+	return &PointRuleService{
+		PointRepo:       pointRepo,
+		AchievementRepo: achievementRepo,
+		MongoRepo:       mongoRepo,
+		Outbox:          outbox.NewDispatcher(outboxRepo, mongoRepo, readmodel.NewProjector(achievementRepo, mongoRepo, viewRepo)),
+		Types:           achievementtype.NewRegistry(achievementtype.DefaultTypes()),
+	}
+}
+
+// ListPointRules godoc
+// @Summary      List Aturan Poin (Admin)
+// @Description  Mendapatkan semua aturan poin. Level/rank kosong berarti berlaku untuk semua nilai; aturan paling spesifik yang dipakai.
+// @Tags         Admin - Point Rules
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{} "List Aturan"
+// @Failure      500  {object}  map[string]interface{} "Internal Server Error"
+// @Router       /point-rules [get]
+func (s *PointRuleService) ListPointRules(c *fiber.Ctx) error {
+	rules, err := s.PointRepo.GetAllRules()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil aturan poin", "code": "500"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "data": rules})
+}
+
+// CreatePointRule godoc
+// @Summary      Buat Aturan Poin (Admin)
+// @Description  Menambah aturan poin untuk kombinasi tipe/level/rank. team_percent = persentase poin per anggota bila prestasi tim (default 100).
+// @Tags         Admin - Point Rules
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body body      model_postgre.PointRuleInput true "Aturan Poin"
+// @Success      201  {object}  map[string]interface{} "Created"
+// @Failure      400  {object}  map[string]interface{} "Bad Request"
+// @Failure      409  {object}  map[string]interface{} "Aturan untuk kombinasi ini sudah ada"
+// @Router       /point-rules [post]
+func (s *PointRuleService) CreatePointRule(c *fiber.Ctx) error {
+	req := new(model_postgre.PointRuleInput)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Input tidak valid", "code": "400"})
+	}
+	if msg := s.validateRule(req); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": msg, "code": "400"})
+	}
+
+	rule, err := s.PointRepo.CreateRule(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": "Aturan untuk kombinasi tipe/level/rank ini sudah ada", "code": "409"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal membuat aturan poin", "code": "500"})
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"status": "success", "message": "Aturan poin dibuat. Jalankan recalculate untuk menerapkan ke prestasi yang belum diverifikasi.", "data": rule})
+}
+
+// UpdatePointRule godoc
+// @Summary      Update Aturan Poin (Admin)
+// @Tags         Admin - Point Rules
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string                       true "Rule ID"
+// @Param        body body      model_postgre.PointRuleInput true "Aturan Poin"
+// @Success      200  {object}  map[string]interface{} "Updated"
+// @Failure      400  {object}  map[string]interface{} "Bad Request"
+// @Failure      404  {object}  map[string]interface{} "Not Found"
+// @Router       /point-rules/{id} [put]
+func (s *PointRuleService) UpdatePointRule(c *fiber.Ctx) error {
+	req := new(model_postgre.PointRuleInput)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Input tidak valid", "code": "400"})
+	}
+	if msg := s.validateRule(req); msg != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": msg, "code": "400"})
+	}
+
+	rule, err := s.PointRepo.UpdateRule(c.Params("id"), req)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Aturan poin tidak ditemukan", "code": "404"})
+		}
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"message": "Aturan untuk kombinasi tipe/level/rank ini sudah ada", "code": "409"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal update aturan poin", "code": "500"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "message": "Aturan poin diperbarui", "data": rule})
+}
+
+// DeletePointRule godoc
+// @Summary      Hapus Aturan Poin (Admin)
+// @Tags         Admin - Point Rules
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Rule ID"
+// @Success      200  {object}  map[string]interface{} "Deleted"
+// @Failure      404  {object}  map[string]interface{} "Not Found"
+// @Router       /point-rules/{id} [delete]
+func (s *PointRuleService) DeletePointRule(c *fiber.Ctx) error {
+	err := s.PointRepo.DeleteRule(c.Params("id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Aturan poin tidak ditemukan", "code": "404"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menghapus aturan poin", "code": "500"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "message": "Aturan poin dihapus"})
+}
+
+// RecalculatePoints godoc
+// @Summary      Hitung Ulang Poin (Admin)
+// @Description  Menerapkan aturan poin terbaru ke semua prestasi yang belum diverifikasi (poin prestasi verified sudah dibekukan). Mengembalikan daftar perubahan poin. Gunakan dry_run=true untuk pratinjau tanpa menyimpan.
+// @Tags         Admin - Point Rules
+// @Produce      json
+// @Security     BearerAuth
+// @Param        dry_run  query     bool  false  "Hanya hitung, tidak menyimpan"
+// @Success      200  {object}  map[string]interface{} "Daftar Delta"
+// @Failure      500  {object}  map[string]interface{} "Internal Server Error"
+// @Router       /point-rules/recalculate [post]
+func (s *PointRuleService) RecalculatePoints(c *fiber.Ctx) error {
+	dryRun := c.QueryBool("dry_run", false)
+
+	rules, err := s.PointRepo.GetAllRules()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil aturan poin", "code": "500"})
+	}
+	references, err := s.AchievementRepo.GetAllAchievementReferences()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil data prestasi", "code": "500"})
+	}
+
+	refByMongoID := make(map[string]model_postgre.AchievementReference)
+	var mongoIDs []primitive.ObjectID
+	for _, ref := range references {
+		if ref.Status == model_postgre.StatusVerified { continue }
+		oid, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+		if err != nil { continue }
+		refByMongoID[ref.MongoAchievementID] = ref
+		mongoIDs = append(mongoIDs, oid)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	deltas := []model_postgre.PointDelta{}
+	if len(mongoIDs) > 0 {
+		docs, err := s.MongoRepo.GetDetailsByIDs(ctx, mongoIDs)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil detail prestasi", "code": "500"})
+		}
+		for _, doc := range docs {
+			if doc.PointsFrozenAt != nil { continue }
+			result := points.Calculate(rules, doc.AchievementType, doc.Details)
+			if result.Points == doc.Points { continue }
+
+			ref := refByMongoID[doc.ID.Hex()]
+			if !dryRun {
+				if _, err := s.MongoRepo.UpdatePoints(ctx, doc.ID, result.Points); err != nil {
+					return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menyimpan poin baru: " + err.Error(), "code": "500"})
+				}
+				// Read model ikut diperbarui; bila gagal event tetap tercatat untuk worker outbox
+				if _, err := s.Outbox.Refresh(ctx, doc.ID.Hex()); err != nil {
+					log.Printf("refresh read model prestasi %s tertunda: %v", doc.ID.Hex(), err)
+				}
+			}
+			deltas = append(deltas, model_postgre.PointDelta{
+				AchievementID: ref.ID,
+				MongoID:       doc.ID.Hex(),
+				Title:         doc.Title,
+				Status:        string(ref.Status),
+				OldPoints:     doc.Points,
+				NewPoints:     result.Points,
+				Delta:         result.Points - doc.Points,
+			})
+		}
+	}
+
+	message := "Poin berhasil dihitung ulang."
+	if dryRun {
+		message = "Pratinjau hitung ulang (dry run), tidak ada perubahan disimpan."
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": message,
+		"data": fiber.Map{
+			"dry_run": dryRun,
+			"scanned": len(mongoIDs),
+			"changed": len(deltas),
+			"deltas":  deltas,
+		},
+	})
+}
+
+// validateRule: Tipe harus terdaftar di registry, level mengikuti enum competitionLevel
+func (s *PointRuleService) validateRule(req *model_postgre.PointRuleInput) string {
+	req.AchievementType = strings.TrimSpace(req.AchievementType)
+	if _, ok := s.Types.Get(req.AchievementType); !ok {
+		return "achievement_type tidak dikenal"
+	}
+	if req.Level != nil && *req.Level != "" {
+		valid := false
+		for _, level := range achievementtype.CompetitionLevels {
+			if level == *req.Level { valid = true }
+		}
+		if !valid {
+			return "level harus salah satu dari: " + strings.Join(achievementtype.CompetitionLevels, ", ")
+		}
+	}
+	// String kosong diperlakukan sebagai wildcard
+	if req.Level != nil && *req.Level == "" { req.Level = nil }
+	if req.Rank != nil && *req.Rank == "" { req.Rank = nil }
+	if req.Points < 0 {
+		return "points tidak boleh negatif"
+	}
+	if req.TeamPercent != nil && (*req.TeamPercent < 0 || *req.TeamPercent > 100) {
+		return "team_percent harus di antara 0 dan 100"
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/safrizal-hk/uas-gofiber/app/reconcile"
+	repo_mongo "github.com/safrizal-hk/uas-gofiber/app/repository/mongo"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
+	"github.com/safrizal-hk/uas-gofiber/middleware"
+)
+
+type ReconcileService struct {
+	Reconciler *reconcile.Reconciler
+}
+
+func NewReconcileService(achievementRepo repo_postgre.AchievementPGRepository, mongoRepo repo_mongo.AchievementMongoRepository, outboxRepo repo_postgre.OutboxRepository) *ReconcileService {
+	return &ReconcileService{Reconciler: reconcile.New(achievementRepo, mongoRepo, outboxRepo)}
+}
+
+// ReconcileAchievements godoc
+// @Summary      Rekonsiliasi PostgreSQL & MongoDB (Admin)
+// @Description  Memindai achievement_references dan dokumen prestasi MongoDB lalu melaporkan: mongo_achievement_id rusak, referensi tanpa dokumen, dokumen tanpa referensi, serta status deleted yang tidak sesuai deletedAt. Default dry run (hanya laporan). Dengan dry_run=false: referensi rusak/tanpa dokumen dipindah ke trash, dokumen yatim dihapus permanen, dan deletedAt dokumen disesuaikan dengan status PostgreSQL. Data dengan event outbox tertunda atau yang baru berubah dilewati (skipped).
+// @Tags         Admin - Maintenance
+// @Produce      json
+// @Security     BearerAuth
+// @Param        dry_run  query     bool  false  "Hanya laporan tanpa perbaikan (default true)"
+// @Success      200  {object}  map[string]interface{} "Laporan Rekonsiliasi"
+// @Failure      500  {object}  map[string]interface{} "Internal Server Error"
+// @Router       /reconcile [post]
+func (s *ReconcileService) ReconcileAchievements(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	dryRun := c.QueryBool("dry_run", true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	report, err := s.Reconciler.Run(ctx, dryRun, profile.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Rekonsiliasi gagal: " + err.Error(), "code": "500"})
+	}
+
+	message := "Rekonsiliasi selesai."
+	if dryRun {
+		message = "Laporan rekonsiliasi (dry run), tidak ada perubahan disimpan."
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "message": message, "data": report})
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 	"github.com/gofiber/fiber/v2"
 	
+	"github.com/safrizal-hk/uas-gofiber/app/policy"
 	repoMongo "github.com/safrizal-hk/uas-gofiber/app/repository/mongo"
 	repoPostgres "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
 	"github.com/safrizal-hk/uas-gofiber/middleware"
@@ -14,12 +15,14 @@ import (
 type ReportService struct {
 	MongoRepo repoMongo.ReportMongoRepository
 	PgRepo    repoPostgres.ReportPGRepository
+	Policy    *policy.Policy
 }
 
 func NewReportService(mongoRepo repoMongo.ReportMongoRepository, pgRepo repoPostgres.ReportPGRepository) *ReportService {
 	return &ReportService{
 		MongoRepo: mongoRepo,
 		PgRepo:    pgRepo,
+		Policy:    policy.New(pgRepo),
 	}
 }
 
@@ -39,46 +42,23 @@ func (s *ReportService) GetStatistics(c *fiber.Ctx) error {
     ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
     defer cancel()
 
-    var studentIDs []string
-
-    switch profile.Role {
-    case "Admin":
-        // Admin: studentIDs kosong = Ambil Semua Data di Mongo (tidak difilter by studentId)
-        studentIDs = []string{} 
-
-    case "Mahasiswa":
-        studentID, err := s.PgRepo.FindStudentIdByUserID(profile.ID)
-        if err != nil || studentID == "" {
-            return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Akun tidak terhubung ke data Mahasiswa.", "code": "403"})
-        }
-        studentIDs = append(studentIDs, studentID)
-
-    case "Dosen Wali":
-        // --- [LOGIKA BARU DOSEN WALI] ---
-        // 1. Cari ID Dosen
-        lecturerID, err := s.PgRepo.FindLecturerIdByUserID(profile.ID)
-        if err != nil || lecturerID == "" {
-             return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Akun tidak terhubung ke data Dosen Wali.", "code": "403"})
-        }
-
-        // 2. Ambil list ID mahasiswa bimbingan
-        adviseeIDs, err := s.PgRepo.GetAdviseeStudentIDs(lecturerID)
-        if err != nil {
-            return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil data bimbingan.", "code": "500"})
-        }
+    scope, errPolicy := s.Policy.ScopeFor(profile)
+    if errPolicy != nil {
+        return policy.Respond(c, errPolicy)
+    }
 
-        // Jika tidak punya bimbingan, kembalikan data kosong (bukan error)
-        if len(adviseeIDs) == 0 {
-             return c.Status(fiber.StatusOK).JSON(fiber.Map{
+    // Admin: studentIDs kosong = Ambil Semua Data di Mongo (tidak difilter by studentId)
+    studentIDs := []string{}
+    if !scope.All {
+        // Jika Dosen Wali tidak punya bimbingan, kembalikan data kosong (bukan error)
+        if len(scope.StudentIDs) == 0 {
+            return c.Status(fiber.StatusOK).JSON(fiber.Map{
                 "status": "success",
                 "message": "Belum ada mahasiswa bimbingan.",
                 "data": fiber.Map{"totalByTypeAndLevel": []interface{}{}},
             })
         }
-        studentIDs = adviseeIDs
-
-    default:
-        return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Role tidak diizinkan mengakses statistik.", "code": "403"})
+        studentIDs = scope.StudentIDs
     }
 
     // Panggil Mongo Agregasi (Sama untuk semua role, bedanya cuma di filter studentIDs)
@@ -98,7 +78,7 @@ func (s *ReportService) GetStatistics(c *fiber.Ctx) error {
 
 // GetStudentReport godoc
 // @Summary      Laporan Detail Mahasiswa
-// @Description  Melihat daftar lengkap prestasi milik satu mahasiswa spesifik (Admin/Dosen Wali/Mahasiswa ybs).
+// @Description  Melihat daftar lengkap prestasi milik satu mahasiswa spesifik (Admin/Dosen Wali pembimbing/Mahasiswa ybs), termasuk prestasi tim sebagai anggota. Dibaca dari read model achievement_views.
 // @Tags         Reports
 // @Accept       json
 // @Produce      json
@@ -112,22 +92,22 @@ func (s *ReportService) GetStudentReport(c *fiber.Ctx) error {
 	profile := middleware.GetUserProfileFromContext(c)
 	studentID := c.Params("id") // UUID Mahasiswa yang diminta
 
-	if profile.Role == "Mahasiswa" {
-		myStudentID, _ := s.PgRepo.FindStudentIdByUserID(profile.ID)
-		if myStudentID != studentID {
-			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"message": "Anda tidak berhak melihat laporan ini.", "code": "403"})
-		}
+	if errPolicy := s.Policy.CanAccessStudent(profile, studentID); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
 	}
 
-	references, err := s.PgRepo.GetStudentAchievementReferences(studentID)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	achievements, err := s.MongoRepo.GetStudentAchievementViews(ctx, studentID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil data referensi prestasi.", "code": "500"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil data prestasi mahasiswa.", "code": "500"})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status": "success",
 		"message": "Laporan mahasiswa berhasil dimuat.",
-		"total_prestasi": len(references),
-		"data": references, 
+		"total_prestasi": len(achievements),
+		"data": achievements,
 	})
 }
\ No newline at end of file
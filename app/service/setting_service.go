@@ -0,0 +1,120 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
+	"github.com/safrizal-hk/uas-gofiber/middleware"
+)
+
+// settingRange: Batas nilai integer untuk pengaturan yang boleh diubah lewat API
+type settingRange struct {
+	Min, Max int
+	UserID   bool // Nilai berupa UUID user (boleh kosong), bukan bilangan bulat
+}
+
+var editableSettings = map[string]settingRange{
+	model_postgre.SettingTrashRetentionDays:    {Min: 1, Max: 3650},
+	model_postgre.SettingVerificationSLADays:   {Min: 1, Max: 365},
+	model_postgre.SettingSLAFallbackVerifierID: {UserID: true},
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type SettingService struct {
+	SettingRepo repo_postgre.SettingRepository
+}
+
+func NewSettingService(settingRepo repo_postgre.SettingRepository) *SettingService {
+	return &SettingService{SettingRepo: settingRepo}
+}
+
+// ListSettings godoc
+// @Summary      List Pengaturan Sistem (Admin)
+// @Description  Mendapatkan semua pengaturan sistem (key-value), misalnya trash_retention_days.
+// @Tags         Admin - Settings
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{} "List Pengaturan"
+// @Failure      500  {object}  map[string]interface{} "Internal Server Error"
+// @Router       /settings [get]
+func (s *SettingService) ListSettings(c *fiber.Ctx) error {
+	settings, err := s.SettingRepo.ListSettings()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil pengaturan", "code": "500"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "data": settings})
+}
+
+// UpdateSetting godoc
+// @Summary      Update Pengaturan Sistem (Admin)
+// @Description  Mengubah nilai satu pengaturan. trash_retention_days: jumlah hari (1-3650) prestasi disimpan di trash sebelum dihapus permanen. verification_sla_days: batas hari (1-365) prestasi menunggu verifikasi sebelum dieskalasi. sla_fallback_verifier_id: user ID penerima eskalasi (kosong = semua Admin).
+// @Tags         Admin - Settings
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        key  path      string                              true "Key Pengaturan"
+// @Param        body body      model_postgre.SettingUpdateRequest  true "Nilai Baru"
+// @Success      200  {object}  map[string]interface{} "Updated"
+// @Failure      400  {object}  map[string]interface{} "Bad Request"
+// @Failure      404  {object}  map[string]interface{} "Key tidak dikenal"
+// @Router       /settings/{key} [put]
+func (s *SettingService) UpdateSetting(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
+	key := c.Params("key")
+
+	limits, ok := editableSettings[key]
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Pengaturan tidak dikenal", "code": "404"})
+	}
+
+	req := new(model_postgre.SettingUpdateRequest)
+	if err := c.BodyParser(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": "Input tidak valid", "code": "400"})
+	}
+	value := strings.TrimSpace(req.Value)
+	if limits.UserID {
+		if value != "" && !uuidPattern.MatchString(value) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": fmt.Sprintf("%s harus berupa UUID user atau kosong", key), "code": "400"})
+		}
+	} else {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < limits.Min || n > limits.Max {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"message": fmt.Sprintf("%s harus bilangan bulat %d-%d", key, limits.Min, limits.Max), "code": "400"})
+		}
+		value = strconv.Itoa(n)
+	}
+
+	setting, err := s.SettingRepo.UpsertSetting(key, value, profile.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal menyimpan pengaturan", "code": "500"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "message": "Pengaturan diperbarui", "data": setting})
+}
+
+// GetSetting godoc
+// @Summary      Detail Pengaturan Sistem (Admin)
+// @Tags         Admin - Settings
+// @Produce      json
+// @Security     BearerAuth
+// @Param        key  path      string  true  "Key Pengaturan"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{} "Not Found"
+// @Router       /settings/{key} [get]
+func (s *SettingService) GetSetting(c *fiber.Ctx) error {
+	setting, err := s.SettingRepo.GetSetting(c.Params("key"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"message": "Pengaturan tidak ditemukan", "code": "404"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"message": "Gagal mengambil pengaturan", "code": "500"})
+	}
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "success", "data": setting})
+}
@@ -3,19 +3,22 @@ package service
 import (
 	"github.com/gofiber/fiber/v2"
 	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/policy"
 	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
-	// "github.com/safrizal-hk/uas-gofiber/middleware"
+	"github.com/safrizal-hk/uas-gofiber/middleware"
 )
 
 type StudentService struct {
 	StudentRepo repo_postgre.StudentRepository
 	AchievementRepo repo_postgre.AchievementPGRepository
+	Policy *policy.Policy
 }
 
 func NewStudentService(studentRepo repo_postgre.StudentRepository, achievementRepo repo_postgre.AchievementPGRepository) *StudentService {
 	return &StudentService{
 		StudentRepo: studentRepo,
 		AchievementRepo: achievementRepo,
+		Policy: policy.New(achievementRepo),
 	}
 }
 
@@ -93,10 +96,16 @@ func (s *StudentService) SetAdvisor(c *fiber.Ctx) error {
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Student ID (UUID)"
 // @Success      200  {object}  map[string]interface{} "List Prestasi"
+// @Failure      403  {object}  map[string]interface{} "Forbidden"
 // @Failure      500  {object}  map[string]interface{} "Internal Server Error"
 // @Router       /students/{id}/achievements [get]
 func (s *StudentService) GetStudentAchievements(c *fiber.Ctx) error {
+	profile := middleware.GetUserProfileFromContext(c)
 	studentID := c.Params("id")
+
+	if errPolicy := s.Policy.CanAccessStudent(profile, studentID); errPolicy != nil {
+		return policy.Respond(c, errPolicy)
+	}
     
 	achievements, err := s.AchievementRepo.GetAchievementsByStudentIDs([]string{studentID})
 	if err != nil {
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Local menyimpan objek sebagai file di Dir. URL dibentuk dari BaseURL (mis. "/uploads" atau
+// "https://api.kampus.ac.id/uploads") sehingga tidak terikat ke host tertentu.
+type Local struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewLocal(dir, baseURL string) *Local {
+	return &Local{Dir: dir, BaseURL: baseURL}
+}
+
+// Put menulis ke file sementara lalu rename, sehingga pembaca tidak pernah melihat file setengah jadi
+func (l *Local) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	target, err := l.path(key)
+	if err != nil { return err }
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil { return err }
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil { return err }
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil { return err }
+	if err := os.Chmod(tmp.Name(), 0644); err != nil { return err }
+	return os.Rename(tmp.Name(), target)
+}
+
+// Get membuka file. Content type ditebak dari ekstensi karena disk tidak menyimpan metadata.
+func (l *Local) Get(ctx context.Context, key string) (*Object, error) {
+	file, obj, err := l.open(key)
+	if err != nil { return nil, err }
+	obj.Body = file
+	return obj, nil
+}
+
+func (l *Local) GetRange(ctx context.Context, key string, offset, length int64) (*Object, error) {
+	file, obj, err := l.open(key)
+	if err != nil { return nil, err }
+	if offset < 0 || length < 0 || offset+length > obj.Size {
+		file.Close()
+		return nil, fmt.Errorf("rentang %d+%d di luar ukuran objek %d", offset, length, obj.Size)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	obj.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(file, length), file}
+	return obj, nil
+}
+
+func (l *Local) Stat(ctx context.Context, key string) (*Object, error) {
+	file, obj, err := l.open(key)
+	if err != nil { return nil, err }
+	file.Close()
+	return obj, nil
+}
+
+func (l *Local) open(key string) (*os.File, *Object, error) {
+	target, err := l.path(key)
+	if err != nil { return nil, nil, err }
+
+	file, err := os.Open(target)
+	if errors.Is(err, os.ErrNotExist) { return nil, nil, ErrNotFound }
+	if err != nil { return nil, nil, err }
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" { contentType = "application/octet-stream" }
+	return file, &Object{Size: info.Size(), ContentType: contentType, ModTime: info.ModTime()}, nil
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	target, err := l.path(key)
+	if err != nil { return err }
+	err = os.Remove(target)
+	if errors.Is(err, os.ErrNotExist) { return ErrNotFound }
+	return err
+}
+
+func (l *Local) URL(key string) string {
+	return joinURL(l.BaseURL, key)
+}
+
+func (l *Local) path(key string) (string, error) {
+	if !ValidKey(key) { return "", ErrInvalidKey }
+	return filepath.Join(l.Dir, filepath.FromSlash(key)), nil
+}
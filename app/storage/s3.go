@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Nilai x-amz-content-sha256 untuk body yang tidak ikut di-hash (upload streaming)
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// sha256 dari body kosong
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3 menyimpan objek di bucket S3-compatible (AWS S3, MinIO, dsb.) dengan request yang ditandatangani
+// AWS Signature V4. Bucket dialamatkan path-style (Endpoint/Bucket/key) agar bekerja dengan MinIO.
+type S3 struct {
+	Endpoint  string // mis. "https://s3.ap-southeast-1.amazonaws.com" atau "http://minio:9000"
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string // Base URL publik objek; kosong = Endpoint/Bucket
+	Client    *http.Client
+	Now       func() time.Time
+}
+
+func NewS3(endpoint, region, bucket, accessKey, secretKey, publicURL string) *S3 {
+	if region == "" { region = "us-east-1" }
+	return &S3{
+		Endpoint:  strings.TrimRight(endpoint, "/"),
+		Region:    region,
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		PublicURL: publicURL,
+		Client:    &http.Client{Timeout: 5 * time.Minute},
+		Now:       time.Now,
+	}
+}
+
+func (s *S3) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil { return err }
+	req.ContentLength = size
+	if contentType != "" { req.Header.Set("Content-Type", contentType) }
+	s.Sign(req, unsignedPayload)
+
+	resp, err := s.Client.Do(req)
+	if err != nil { return err }
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s.responseError(req, resp)
+	}
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (*Object, error) {
+	return s.fetch(ctx, http.MethodGet, key, "")
+}
+
+func (s *S3) GetRange(ctx context.Context, key string, offset, length int64) (*Object, error) {
+	if offset < 0 || length <= 0 {
+		return nil, fmt.Errorf("rentang %d+%d tidak valid", offset, length)
+	}
+	return s.fetch(ctx, http.MethodGet, key, fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+}
+
+func (s *S3) Stat(ctx context.Context, key string) (*Object, error) {
+	return s.fetch(ctx, http.MethodHead, key, "")
+}
+
+// fetch menjalankan GET/HEAD. Untuk GET dengan Range, ukuran objek utuh diambil dari Content-Range.
+func (s *S3) fetch(ctx context.Context, method, key, byteRange string) (*Object, error) {
+	req, err := s.newRequest(ctx, method, key, nil)
+	if err != nil { return nil, err }
+	if byteRange != "" { req.Header.Set("Range", byteRange) }
+	s.Sign(req, emptyPayloadHash)
+
+	resp, err := s.Client.Do(req)
+	if err != nil { return nil, err }
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound { return nil, ErrNotFound }
+		return nil, s.responseError(req, resp)
+	}
+
+	size := resp.ContentLength
+	if resp.StatusCode == http.StatusPartialContent {
+		contentRange := resp.Header.Get("Content-Range")
+		if slash := strings.LastIndex(contentRange, "/"); slash >= 0 {
+			size, _ = strconv.ParseInt(contentRange[slash+1:], 10, 64)
+		}
+	}
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	obj := &Object{Body: resp.Body, Size: size, ContentType: resp.Header.Get("Content-Type"), ModTime: modTime}
+	if method == http.MethodHead {
+		resp.Body.Close()
+		obj.Body = nil
+	}
+	return obj, nil
+}
+
+// Delete: S3 umumnya membalas 204 walaupun objek tidak ada, jadi ErrNotFound jarang dikembalikan
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil { return err }
+	s.Sign(req, emptyPayloadHash)
+
+	resp, err := s.Client.Do(req)
+	if err != nil { return err }
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound { return ErrNotFound }
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s.responseError(req, resp)
+	}
+	return nil
+}
+
+func (s *S3) URL(key string) string {
+	if s.PublicURL != "" { return joinURL(s.PublicURL, key) }
+	return joinURL(s.Endpoint+"/"+s.Bucket, key)
+}
+
+func (s *S3) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	if !ValidKey(key) { return nil, ErrInvalidKey }
+	return http.NewRequestWithContext(ctx, method, s.Endpoint+"/"+uriEncode(s.Bucket+"/"+key, false), body)
+}
+
+func (s *S3) responseError(req *http.Request, resp *http.Response) error {
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("s3 %s %s: status %d: %s", req.Method, req.URL.Path, resp.StatusCode, strings.TrimSpace(string(detail)))
+}
+
+// Sign menambahkan header x-amz-date, x-amz-content-sha256 dan Authorization (AWS Signature V4).
+// Header yang ditandatangani: host, content-type, range dan semua x-amz-*.
+func (s *S3) Sign(req *http.Request, payloadHash string) {
+	now := s.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	if req.Host != "" { headers["host"] = req.Host }
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex(canonicalRequest)}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.SecretKey), day)
+	signingKey = hmacSHA256(signingKey, s.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.AccessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(values url.Values) string {
+	pairs := []string{}
+	for key, list := range values {
+		for _, value := range list {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode: Encoding URI versi SigV4, hanya karakter unreserved (A-Z a-z 0-9 - _ . ~) yang tidak di-escape
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("objek tidak ditemukan di storage")
+	ErrInvalidKey = errors.New("key objek tidak valid")
+)
+
+// Object: Isi satu objek beserta metadatanya. Body wajib ditutup pemanggil (nil pada Stat).
+// Size selalu ukuran objek utuh, juga pada GetRange.
+type Object struct {
+	Body        io.ReadCloser
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage: Tempat penyimpanan file lampiran. Key berupa path relatif dengan pemisah "/",
+// tanpa segmen kosong, "." atau "..".
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (*Object, error)
+	// GetRange membaca length byte mulai dari offset; rentang harus berada di dalam objek
+	GetRange(ctx context.Context, key string, offset, length int64) (*Object, error)
+	Stat(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// ValidKey menolak key yang bisa keluar dari direktori/bucket (path absolut, "..", dsb.)
+func ValidKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// joinURL menggabungkan base URL dengan key; tiap segmen key di-escape
+func joinURL(base, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.TrimRight(base, "/") + "/" + path.Join(segments...)
+}
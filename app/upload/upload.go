@@ -0,0 +1,196 @@
+package upload
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// Alasan penolakan, dipakai frontend untuk memilih pesan/ikon
+const (
+	ReasonEmpty           = "empty_file"
+	ReasonTooLarge        = "file_too_large"
+	ReasonUnsupported     = "unsupported_type"
+	ReasonContentMismatch = "content_mismatch"
+)
+
+// Panjang maksimal nama file (tanpa ekstensi) pada key storage
+const maxBaseNameLength = 80
+
+// Rule: Satu jenis file yang diterima. MIME dicocokkan dengan hasil sniffing isi file,
+// bukan header Content-Type dari client.
+type Rule struct {
+	MIME       string   `json:"mime"`
+	Label      string   `json:"label"`
+	Extensions []string `json:"extensions"`
+	MaxSize    int64    `json:"max_size"`
+}
+
+// Rejection: Alasan file ditolak. Status = kode HTTP yang dikirim ke client.
+type Rejection struct {
+	FileName string `json:"file_name"`
+	Reason   string `json:"reason"`
+	Message  string `json:"message"`
+	MaxSize  int64  `json:"max_size,omitempty"`
+	Status   int    `json:"-"`
+}
+
+// File: Hasil validasi yang aman dipakai untuk menyimpan file
+type File struct {
+	Header      *multipart.FileHeader
+	DisplayName string // Nama asli yang sudah dibersihkan, untuk ditampilkan/Content-Disposition
+	SafeName    string // Nama untuk key storage: hanya [a-z0-9._-]
+	ContentType string // Hasil sniffing
+}
+
+// DefaultRules: PDF maks. 10 MB, PNG/JPEG maks. 5 MB
+func DefaultRules() []Rule {
+	return []Rule{
+		{MIME: "application/pdf", Label: "PDF", Extensions: []string{".pdf"}, MaxSize: 10 << 20},
+		{MIME: "image/png", Label: "PNG", Extensions: []string{".png"}, MaxSize: 5 << 20},
+		{MIME: "image/jpeg", Label: "JPEG", Extensions: []string{".jpg", ".jpeg"}, MaxSize: 5 << 20},
+	}
+}
+
+// Validator memeriksa file unggahan terhadap allowlist ekstensi & MIME serta batas ukuran per jenis
+type Validator struct {
+	Rules []Rule
+}
+
+func NewValidator(rules []Rule) *Validator {
+	return &Validator{Rules: rules}
+}
+
+// MaxSize: Batas ukuran terbesar di antara semua jenis
+func (v *Validator) MaxSize() int64 {
+	var max int64
+	for _, rule := range v.Rules {
+		if rule.MaxSize > max { max = rule.MaxSize }
+	}
+	return max
+}
+
+// BodyLimit: Batas body request untuk BodyLimit server, cukup untuk maxFiles file berukuran
+// maksimal ditambah 1 MB untuk field form lain
+func (v *Validator) BodyLimit(maxFiles int) int {
+	return int(v.MaxSize())*maxFiles + 1<<20
+}
+
+// BodyTooLarge: Penolakan untuk request yang melebihi BodyLimit. Request ditolak server sebelum
+// handler berjalan sehingga nama file tidak diketahui.
+func (v *Validator) BodyTooLarge() *Rejection {
+	return &Rejection{
+		Reason:  ReasonTooLarge,
+		Message: "Ukuran file maksimal " + formatSize(v.MaxSize()),
+		MaxSize: v.MaxSize(),
+		Status:  http.StatusRequestEntityTooLarge,
+	}
+}
+
+// Validate: Ekstensi harus ada di allowlist, isi file (512 byte pertama) harus cocok dengan
+// jenis ekstensi tersebut, dan ukuran tidak melebihi batas jenisnya.
+func (v *Validator) Validate(header *multipart.FileHeader) (*File, *Rejection) {
+	display := DisplayName(header.Filename)
+	reject := func(status int, reason, message string) *Rejection {
+		return &Rejection{FileName: display, Reason: reason, Message: message, Status: status}
+	}
+
+	ext := strings.ToLower(path.Ext(display))
+	rule := v.ruleForExtension(ext)
+	if rule == nil {
+		return nil, reject(http.StatusUnsupportedMediaType, ReasonUnsupported, "Jenis file tidak didukung. Gunakan: "+v.allowedList())
+	}
+	if header.Size == 0 {
+		return nil, reject(http.StatusBadRequest, ReasonEmpty, "File kosong")
+	}
+	if header.Size > rule.MaxSize {
+		rejection := reject(http.StatusRequestEntityTooLarge, ReasonTooLarge, fmt.Sprintf("Ukuran file %s maksimal %s", rule.Label, formatSize(rule.MaxSize)))
+		rejection.MaxSize = rule.MaxSize
+		return nil, rejection
+	}
+
+	sniffed, err := sniff(header)
+	if err != nil {
+		return nil, reject(http.StatusBadRequest, ReasonContentMismatch, "File tidak dapat dibaca")
+	}
+	if sniffed != rule.MIME {
+		return nil, reject(http.StatusUnsupportedMediaType, ReasonContentMismatch, fmt.Sprintf("Isi file bukan %s yang valid", rule.Label))
+	}
+
+	return &File{Header: header, DisplayName: display, SafeName: SafeName(display), ContentType: rule.MIME}, nil
+}
+
+func (v *Validator) ruleForExtension(ext string) *Rule {
+	for i := range v.Rules {
+		for _, allowed := range v.Rules[i].Extensions {
+			if ext == allowed { return &v.Rules[i] }
+		}
+	}
+	return nil
+}
+
+func (v *Validator) allowedList() string {
+	labels := []string{}
+	for _, rule := range v.Rules {
+		labels = append(labels, fmt.Sprintf("%s (%s, maks. %s)", rule.Label, strings.Join(rule.Extensions, "/"), formatSize(rule.MaxSize)))
+	}
+	return strings.Join(labels, ", ")
+}
+
+func sniff(header *multipart.FileHeader) (string, error) {
+	file, err := header.Open()
+	if err != nil { return "", err }
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF { return "", err }
+	contentType := http.DetectContentType(head[:n])
+	if i := strings.Index(contentType, ";"); i >= 0 { contentType = contentType[:i] }
+	return contentType, nil
+}
+
+// DisplayName: Nama file tanpa path (pemisah / maupun \) dan tanpa karakter kontrol
+func DisplayName(name string) string {
+	name = strings.ToValidUTF8(name, "")
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 { name = name[i+1:] }
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) { return -1 }
+		return r
+	}, name)
+	return strings.TrimSpace(name)
+}
+
+// SafeName: Nama untuk key storage. Karakter selain huruf/angka/./_/- diganti "-", ekstensi
+// dikecilkan, dan nama dasar dipotong agar key tidak terlalu panjang.
+func SafeName(name string) string {
+	name = DisplayName(name)
+	ext := strings.ToLower(path.Ext(name))
+	base := strings.ToLower(strings.TrimSuffix(name, path.Ext(name)))
+
+	var b strings.Builder
+	dash := false
+	for _, r := range base {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	safe := strings.Trim(b.String(), "-_")
+	if len(safe) > maxBaseNameLength { safe = strings.TrimRight(safe[:maxBaseNameLength], "-_") }
+	if safe == "" { safe = "file" }
+	return safe + ext
+}
+
+func formatSize(size int64) string {
+	if size >= 1<<20 && size%(1<<20) == 0 { return fmt.Sprintf("%d MB", size>>20) }
+	if size >= 1<<10 && size%(1<<10) == 0 { return fmt.Sprintf("%d KB", size>>10) }
+	return fmt.Sprintf("%d byte", size)
+}
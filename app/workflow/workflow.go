@@ -0,0 +1,165 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+)
+
+// Stage: Satu tahap verifikasi beserta syarat siapa yang boleh memprosesnya
+type Stage struct {
+	Code       string   `json:"code"`
+	Name       string   `json:"name"`
+	Permission string   `json:"permission"`
+	Roles      []string `json:"roles"`
+}
+
+// Rule: Aturan routing. Field kosong berarti "cocok dengan apa pun".
+type Rule struct {
+	AchievementTypes  []string `json:"achievementTypes"`
+	CompetitionLevels []string `json:"competitionLevels"`
+}
+
+// Definition: Alur verifikasi berurutan. Definition tanpa Match menjadi alur default.
+type Definition struct {
+	Code   string  `json:"code"`
+	Name   string  `json:"name"`
+	Match  *Rule   `json:"match,omitempty"`
+	Stages []Stage `json:"stages"`
+}
+
+type Engine struct {
+	definitions []Definition
+	fallback    Definition
+}
+
+// DefaultDefinitions: Perilaku lama (satu tahap oleh Dosen Wali) bila tidak ada file konfigurasi
+func DefaultDefinitions() []Definition {
+	return []Definition{{
+		Code: "default",
+		Name: "Verifikasi Dosen Wali",
+		Stages: []Stage{
+			{Code: "advisor", Name: "Verifikasi Dosen Wali", Permission: "achievement:verify", Roles: []string{"Dosen Wali"}},
+		},
+	}}
+}
+
+// LoadFile membaca daftar Definition dari file JSON ({"workflows": [...]})
+func LoadFile(path string) ([]Definition, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil { return nil, err }
+
+	var file struct {
+		Workflows []Definition `json:"workflows"`
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("format konfigurasi workflow tidak valid: %w", err)
+	}
+	return file.Workflows, nil
+}
+
+func NewEngine(definitions []Definition) (*Engine, error) {
+	engine := &Engine{}
+	seen := map[string]bool{}
+
+	for _, def := range definitions {
+		if def.Code == "" { return nil, errors.New("workflow tanpa code") }
+		if seen[def.Code] { return nil, fmt.Errorf("workflow %q didefinisikan lebih dari sekali", def.Code) }
+		seen[def.Code] = true
+
+		if len(def.Stages) == 0 { return nil, fmt.Errorf("workflow %q tidak memiliki tahap", def.Code) }
+		stageSeen := map[string]bool{}
+		for _, st := range def.Stages {
+			if st.Code == "" || stageSeen[st.Code] {
+				return nil, fmt.Errorf("workflow %q: code tahap kosong atau duplikat", def.Code)
+			}
+			stageSeen[st.Code] = true
+		}
+
+		if def.Match == nil {
+			if engine.fallback.Code != "" {
+				return nil, errors.New("hanya boleh ada satu workflow default (tanpa match)")
+			}
+			engine.fallback = def
+			continue
+		}
+		engine.definitions = append(engine.definitions, def)
+	}
+
+	if engine.fallback.Code == "" {
+		engine.fallback = DefaultDefinitions()[0]
+	}
+	return engine, nil
+}
+
+// Resolve memilih workflow pertama yang cocok (urutan sesuai konfigurasi), atau default
+func (e *Engine) Resolve(achievementType string, details map[string]interface{}) Definition {
+	level, _ := details["competitionLevel"].(string)
+	for _, def := range e.definitions {
+		if def.Match.matches(achievementType, level) { return def }
+	}
+	return e.fallback
+}
+
+// Get mengambil workflow berdasarkan code yang tersimpan di referensi. Code kosong atau
+// yang sudah dihapus dari konfigurasi jatuh ke workflow default.
+func (e *Engine) Get(code string) Definition {
+	for _, def := range e.definitions {
+		if def.Code == code { return def }
+	}
+	return e.fallback
+}
+
+func (r *Rule) matches(achievementType, level string) bool {
+	return containsFold(r.AchievementTypes, achievementType) && containsFold(r.CompetitionLevels, level)
+}
+
+func containsFold(list []string, value string) bool {
+	if len(list) == 0 { return true }
+	for _, v := range list {
+		if strings.EqualFold(v, value) { return true }
+	}
+	return false
+}
+
+func (d Definition) FirstStage() Stage {
+	return d.Stages[0]
+}
+
+// CurrentStage mengembalikan tahap dengan code tersebut. Code kosong (data sebelum ada
+// workflow) dianggap masih di tahap pertama.
+func (d Definition) CurrentStage(code string) (Stage, bool) {
+	if code == "" { return d.FirstStage(), true }
+	for _, st := range d.Stages {
+		if st.Code == code { return st, true }
+	}
+	return Stage{}, false
+}
+
+func (d Definition) NextStage(code string) (Stage, bool) {
+	for i, st := range d.Stages {
+		if st.Code == code && i+1 < len(d.Stages) { return d.Stages[i+1], true }
+	}
+	return Stage{}, false
+}
+
+// Allows: User boleh memproses tahap ini jika role-nya terdaftar (bila Roles diisi)
+// dan memiliki permission tahap (bila Permission diisi)
+func (s Stage) Allows(profile model_postgre.UserProfile) bool {
+	if len(s.Roles) > 0 {
+		roleOK := false
+		for _, role := range s.Roles {
+			if role == profile.Role { roleOK = true; break }
+		}
+		if !roleOK { return false }
+	}
+	if s.Permission == "" { return true }
+	for _, perm := range profile.Permissions {
+		if perm == s.Permission { return true }
+	}
+	return false
+}
@@ -22,8 +22,14 @@ func LoadEnv() {
 }
 
 func NewDB() *Database {
+	pgDB := database.ConnectPostgreSQL()
+	database.MigratePostgreSQL(pgDB)
+
+	mongoDB := database.ConnectMongoDB()
+	database.MigrateMongoDB(mongoDB)
+
 	return &Database{
-		PgDB:    database.ConnectPostgreSQL(),
-		MongoDB: database.ConnectMongoDB(),
+		PgDB:    pgDB,
+		MongoDB: mongoDB,
 	}
 }
\ No newline at end of file
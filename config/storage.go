@@ -0,0 +1,37 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/safrizal-hk/uas-gofiber/app/storage"
+)
+
+// LoadStorage memilih backend lampiran dari STORAGE_DRIVER: "local" (default) atau "s3".
+//   - local: STORAGE_LOCAL_DIR (default ./uploads), STORAGE_PUBLIC_URL (default /uploads)
+//   - s3: S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY, STORAGE_PUBLIC_URL (opsional)
+func LoadStorage() storage.Storage {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "./uploads"
+		}
+		baseURL := os.Getenv("STORAGE_PUBLIC_URL")
+		if baseURL == "" {
+			baseURL = "/uploads"
+		}
+		return storage.NewLocal(dir, baseURL)
+
+	case "s3":
+		endpoint, bucket := os.Getenv("S3_ENDPOINT"), os.Getenv("S3_BUCKET")
+		if endpoint == "" || bucket == "" {
+			log.Fatal("STORAGE_DRIVER=s3 membutuhkan S3_ENDPOINT dan S3_BUCKET")
+		}
+		return storage.NewS3(endpoint, os.Getenv("S3_REGION"), bucket, os.Getenv("S3_ACCESS_KEY"), os.Getenv("S3_SECRET_KEY"), os.Getenv("STORAGE_PUBLIC_URL"))
+
+	default:
+		log.Fatalf("STORAGE_DRIVER tidak dikenal: %s (pilihan: local, s3)", driver)
+		return nil
+	}
+}
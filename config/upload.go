@@ -0,0 +1,29 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/safrizal-hk/uas-gofiber/app/upload"
+)
+
+// LoadUploadValidator membaca batas ukuran per jenis file dari UPLOAD_MAX_<LABEL>_MB
+// (mis. UPLOAD_MAX_PDF_MB=20). Jenis yang tidak diatur memakai batas bawaan.
+func LoadUploadValidator() *upload.Validator {
+	rules := upload.DefaultRules()
+	for i := range rules {
+		key := "UPLOAD_MAX_" + strings.ToUpper(rules[i].Label) + "_MB"
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		mb, err := strconv.Atoi(value)
+		if err != nil || mb <= 0 {
+			log.Fatalf("%s harus bilangan bulat positif (MB), didapat %q", key, value)
+		}
+		rules[i].MaxSize = int64(mb) << 20
+	}
+	return upload.NewValidator(rules)
+}
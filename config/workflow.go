@@ -0,0 +1,31 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"github.com/safrizal-hk/uas-gofiber/app/workflow"
+)
+
+// LoadWorkflowEngine membaca alur verifikasi dari WORKFLOW_CONFIG (default: config/workflow.json).
+// Jika file tidak ada, dipakai alur satu tahap Dosen Wali seperti sebelumnya.
+func LoadWorkflowEngine() *workflow.Engine {
+	path := os.Getenv("WORKFLOW_CONFIG")
+	if path == "" {
+		path = "config/workflow.json"
+	}
+
+	definitions, err := workflow.LoadFile(path)
+	if os.IsNotExist(err) {
+		log.Println("Konfigurasi workflow tidak ditemukan, memakai alur default.")
+		definitions = workflow.DefaultDefinitions()
+	} else if err != nil {
+		log.Fatal("Gagal membaca konfigurasi workflow: ", err)
+	}
+
+	engine, err := workflow.NewEngine(definitions)
+	if err != nil {
+		log.Fatal("Konfigurasi workflow tidak valid: ", err)
+	}
+	return engine
+}
@@ -0,0 +1,194 @@
+package database
+
+import (
+	"database/sql"
+	"log"
+)
+
+// pgMigrations dijalankan berurutan saat startup. Setiap statement harus idempotent
+// (IF NOT EXISTS) karena dieksekusi ulang setiap kali server dinyalakan.
+var pgMigrations = []string{
+	`CREATE TABLE IF NOT EXISTS achievement_status_history (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		achievement_ref_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+		from_status VARCHAR(30),
+		to_status VARCHAR(30) NOT NULL,
+		actor_id UUID REFERENCES users(id),
+		note TEXT,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_achievement_status_history_ref
+		ON achievement_status_history (achievement_ref_id, created_at)`,
+
+	// Siklus revisi: jumlah permintaan revisi dan catatan revisi terakhir
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS revision_count INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS revision_note TEXT`,
+	// Jika kolom status memakai tipe ENUM, tambahkan nilai baru. Untuk VARCHAR blok ini tidak melakukan apa-apa.
+	`DO $$
+	DECLARE status_type regtype;
+	BEGIN
+		SELECT atttypid::regtype INTO status_type FROM pg_attribute
+		WHERE attrelid = 'achievement_references'::regclass AND attname = 'status';
+		IF EXISTS (SELECT 1 FROM pg_type WHERE oid = status_type AND typtype = 'e') THEN
+			EXECUTE format('ALTER TYPE %s ADD VALUE IF NOT EXISTS %L', status_type, 'revision_requested');
+		END IF;
+	END $$`,
+
+	// Workflow verifikasi bertahap: alur yang dipakai dan tahap yang sedang berjalan
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS workflow_code VARCHAR(50)`,
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS current_stage VARCHAR(50)`,
+
+	// Aturan poin prestasi. level/rank NULL = berlaku untuk semua nilai (wildcard)
+	`CREATE TABLE IF NOT EXISTS point_rules (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		achievement_type VARCHAR(50) NOT NULL,
+		level VARCHAR(30),
+		rank VARCHAR(30),
+		points INT NOT NULL CHECK (points >= 0),
+		team_percent INT NOT NULL DEFAULT 100 CHECK (team_percent BETWEEN 0 AND 100),
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS uq_point_rules_match
+		ON point_rules (achievement_type, COALESCE(level, ''), COALESCE(rank, ''))`,
+	// Aturan awal hanya diisi saat tabel masih kosong, sehingga perubahan admin tidak tertimpa
+	`INSERT INTO point_rules (achievement_type, level, rank, points, team_percent)
+	SELECT v.achievement_type, v.level, v.rank, v.points, v.team_percent FROM (VALUES
+		('competition', 'international', 'first', 100, 60), ('competition', 'international', 'second', 90, 60),
+		('competition', 'international', 'third', 80, 60), ('competition', 'international', NULL, 50, 60),
+		('competition', 'national', 'first', 75, 60), ('competition', 'national', 'second', 65, 60),
+		('competition', 'national', 'third', 55, 60), ('competition', 'national', NULL, 30, 60),
+		('competition', 'regional', 'first', 40, 60), ('competition', 'regional', 'second', 35, 60),
+		('competition', 'regional', 'third', 30, 60), ('competition', 'regional', NULL, 15, 60),
+		('competition', 'local', NULL, 10, 60),
+		('publication', NULL, NULL, 50, 100),
+		('certification', NULL, NULL, 20, 100),
+		('organization', NULL, NULL, 15, 100),
+		('academic', NULL, NULL, 20, 100),
+		('other', NULL, NULL, 5, 100)
+	) AS v(achievement_type, level, rank, points, team_percent)
+	WHERE NOT EXISTS (SELECT 1 FROM point_rules)`,
+
+	// Prestasi tim: setiap anggota punya referensi sendiri yang menunjuk dokumen Mongo yang sama.
+	// co_member = TRUE untuk anggota yang ditautkan (bukan pembuat) dan wajib konfirmasi partisipasi.
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS team_role VARCHAR(20)`,
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS co_member BOOLEAN NOT NULL DEFAULT FALSE`,
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS participation_confirmed_at TIMESTAMP`,
+	`CREATE INDEX IF NOT EXISTS idx_achievement_references_mongo_id
+		ON achievement_references (mongo_achievement_id)`,
+
+	// Trash bin: waktu penghapusan menentukan kapan prestasi di-purge permanen.
+	// Referensi yang terhapus sebelum kolom ini ada memakai updated_at sebagai waktu hapus.
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP`,
+	`UPDATE achievement_references SET deleted_at = updated_at WHERE status = 'deleted' AND deleted_at IS NULL`,
+
+	// Pengaturan sistem yang dapat diubah Admin (key-value)
+	`CREATE TABLE IF NOT EXISTS system_settings (
+		key VARCHAR(100) PRIMARY KEY,
+		value TEXT NOT NULL,
+		updated_by UUID REFERENCES users(id),
+		updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`,
+	`INSERT INTO system_settings (key, value) VALUES ('trash_retention_days', '30') ON CONFLICT (key) DO NOTHING`,
+
+	// Override status oleh Admin ditandai di riwayat agar bisa diaudit terpisah dari alur normal
+	`ALTER TABLE achievement_status_history ADD COLUMN IF NOT EXISTS is_override BOOLEAN NOT NULL DEFAULT FALSE`,
+
+	// SLA verifikasi: prestasi Submitted yang melewati batas dieskalasi ke verifikator cadangan.
+	// Satu eskalasi per siklus submit (submitted_at), setiap eskalasi dicatat di achievement_escalations.
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMP`,
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS escalated_to UUID REFERENCES users(id)`,
+	`CREATE TABLE IF NOT EXISTS achievement_escalations (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		achievement_ref_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+		advisor_id UUID REFERENCES lecturers(id),
+		escalated_to UUID REFERENCES users(id),
+		submitted_at TIMESTAMP NOT NULL,
+		sla_days INT NOT NULL,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		UNIQUE (achievement_ref_id, submitted_at)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_achievement_references_submitted
+		ON achievement_references (submitted_at) WHERE status = 'submitted'`,
+	`INSERT INTO system_settings (key, value) VALUES ('verification_sla_days', '14'), ('sla_fallback_verifier_id', '')
+		ON CONFLICT (key) DO NOTHING`,
+	// Eskalasi hanya berlaku pada tahap verifikasi saat eskalasi terjadi. Eskalasi lama yang masih
+	// berjalan diasumsikan untuk tahap aktifnya.
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS escalated_stage VARCHAR(50)`,
+	`UPDATE achievement_references SET escalated_stage = current_stage
+		WHERE escalated_at IS NOT NULL AND escalated_stage IS NULL AND status = 'submitted'`,
+	// SLA dihitung per tahap: stage_entered_at = waktu prestasi masuk tahap verifikasi aktif, sehingga
+	// tahap berikutnya mendapat jatah SLA penuh dan dapat dieskalasi sekali lagi. Data lama memakai submitted_at.
+	`ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS stage_entered_at TIMESTAMP`,
+	`UPDATE achievement_references SET stage_entered_at = submitted_at
+		WHERE stage_entered_at IS NULL AND submitted_at IS NOT NULL AND status = 'submitted'`,
+	`CREATE INDEX IF NOT EXISTS idx_achievement_references_stage_entered
+		ON achievement_references (stage_entered_at) WHERE status = 'submitted'`,
+	`ALTER TABLE achievement_escalations ADD COLUMN IF NOT EXISTS stage_entered_at TIMESTAMP`,
+	`UPDATE achievement_escalations SET stage_entered_at = submitted_at WHERE stage_entered_at IS NULL`,
+	`DO $$
+	BEGIN
+		IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'achievement_escalations_achievement_ref_id_submitted_at_key') THEN
+			ALTER TABLE achievement_escalations DROP CONSTRAINT achievement_escalations_achievement_ref_id_submitted_at_key;
+		END IF;
+	END $$`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS uq_achievement_escalations_stage
+		ON achievement_escalations (achievement_ref_id, stage_entered_at)`,
+
+	// Delegasi verifikasi: selama jendela waktu [starts_at, ends_at) delegate dapat memverifikasi
+	// mahasiswa bimbingan delegator. Aksi yang dilakukan tercatat on_behalf_of dosen wali asli.
+	`CREATE TABLE IF NOT EXISTS verification_delegations (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		delegator_id UUID NOT NULL REFERENCES lecturers(id) ON DELETE CASCADE,
+		delegate_id UUID NOT NULL REFERENCES lecturers(id) ON DELETE CASCADE,
+		starts_at TIMESTAMP NOT NULL,
+		ends_at TIMESTAMP NOT NULL,
+		reason TEXT,
+		created_by UUID REFERENCES users(id),
+		revoked_at TIMESTAMP,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		CHECK (ends_at > starts_at),
+		CHECK (delegator_id <> delegate_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_verification_delegations_delegate
+		ON verification_delegations (delegate_id, ends_at) WHERE revoked_at IS NULL`,
+	`ALTER TABLE achievement_status_history ADD COLUMN IF NOT EXISTS on_behalf_of UUID REFERENCES users(id)`,
+	// Outbox: niat penulisan ke MongoDB dicatat dalam transaksi referensi, lalu diterapkan (dan diulang)
+	// oleh dispatcher sampai kedua database konvergen. aggregate_id = ID dokumen prestasi di Mongo,
+	// bertipe TEXT karena rekonsiliasi juga mencatat event untuk referensi dengan ID Mongo corrupt.
+	`CREATE TABLE IF NOT EXISTS achievement_outbox (
+		id BIGSERIAL PRIMARY KEY,
+		aggregate_id TEXT NOT NULL,
+		operation VARCHAR(30) NOT NULL,
+		payload JSONB NOT NULL DEFAULT '{}',
+		attempts INT NOT NULL DEFAULT 0,
+		last_error TEXT,
+		next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		locked_until TIMESTAMP,
+		processed_at TIMESTAMP,
+		created_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_achievement_outbox_pending
+		ON achievement_outbox (aggregate_id, id) WHERE processed_at IS NULL`,
+	// Tabel outbox lama memakai UUID. ALTER TYPE mengunci tabel (ACCESS EXCLUSIVE) sehingga hanya
+	// dijalankan selama kolom belum bertipe text.
+	`DO $$
+	BEGIN
+		IF EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'achievement_outbox'
+				AND column_name = 'aggregate_id' AND data_type <> 'text'
+		) THEN
+			ALTER TABLE achievement_outbox ALTER COLUMN aggregate_id TYPE TEXT;
+		END IF;
+	END $$`,
+}
+
+func MigratePostgreSQL(db *sql.DB) {
+	for _, stmt := range pgMigrations {
+		if _, err := db.Exec(stmt); err != nil {
+			log.Fatal("Gagal menjalankan migrasi PostgreSQL: ", err)
+		}
+	}
+	log.Println("Migrasi PostgreSQL selesai.")
+}
@@ -3,9 +3,14 @@ package database
 import (
 	"context"
 	"log"
+	"net/url"
 	"os"
+	"path"
+	"regexp"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -31,4 +36,159 @@ func ConnectMongoDB() *mongo.Database {
 
 	log.Println("Koneksi MongoDB berhasil.")
 	return client.Database(dbName)
-}
\ No newline at end of file
+}
+
+// MigrateMongoDB: Membuat index yang dibutuhkan aplikasi (idempoten, aman dijalankan setiap start)
+func MigrateMongoDB(db *mongo.Database) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	indexes := map[string][]mongo.IndexModel{
+		// Read model daftar, pencarian & laporan prestasi (GET /achievements, /achievements/search, /reports)
+		"achievement_views": {
+			{Keys: bson.D{{Key: "studentId", Value: 1}, {Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}},
+			{Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}}},
+			{Keys: bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "updatedAt", Value: -1}, {Key: "_id", Value: -1}}},
+			{Keys: bson.D{{Key: "mongoAchievementId", Value: 1}}},
+			{Keys: bson.D{{Key: "projectedAt", Value: 1}}},
+			{Keys: bson.D{{Key: "achievementType", Value: 1}, {Key: "points", Value: 1}}},
+			{Keys: bson.D{{Key: "tags", Value: 1}}},
+			// Pencarian teks. Stemming dimatikan karena data berbahasa Indonesia.
+			{
+				Keys: bson.D{{Key: "title", Value: "text"}, {Key: "description", Value: "text"}, {Key: "tags", Value: "text"}},
+				Options: options.Index().SetName("achievement_views_text").SetDefaultLanguage("none").
+					SetWeights(bson.D{{Key: "title", Value: 10}, {Key: "tags", Value: 5}, {Key: "description", Value: 1}}),
+			},
+		},
+		"achievement_revisions": {
+			{Keys: bson.D{{Key: "achievementId", Value: 1}, {Key: "version", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		"achievement_comments": {
+			{Keys: bson.D{{Key: "achievementId", Value: 1}, {Key: "createdAt", Value: 1}}},
+		},
+	}
+	for collection, models := range indexes {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			log.Fatalf("Gagal membuat index MongoDB (%s): %v", collection, err)
+		}
+	}
+	log.Println("Index MongoDB siap.")
+
+	if n, err := backfillAttachmentIDs(ctx, db.Collection("achievements")); err != nil {
+		log.Fatal("Gagal memberi ID lampiran lama: ", err)
+	} else if n > 0 {
+		log.Printf("ID lampiran diberikan pada %d dokumen prestasi.", n)
+	}
+	if n, err := backfillAttachmentKeys(ctx, db.Collection("achievements"), db.Collection("achievement_comments")); err != nil {
+		log.Fatal("Gagal mengisi storage key lampiran lama: ", err)
+	} else if n > 0 {
+		log.Printf("Storage key lampiran lama diisi pada %d dokumen.", n)
+	}
+}
+
+// backfillAttachmentIDs memberi ID pada lampiran yang diunggah sebelum lampiran punya ID.
+// Update bersyarat pada isi array lama sehingga aman bila beberapa instance start bersamaan.
+func backfillAttachmentIDs(ctx context.Context, collection *mongo.Collection) (int, error) {
+	filter := bson.M{"attachments": bson.M{"$elemMatch": bson.M{"id": bson.M{"$exists": false}}}}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"attachments": 1}))
+	if err != nil { return 0, err }
+	var docs []struct {
+		ID          primitive.ObjectID `bson:"_id"`
+		Attachments []bson.D           `bson:"attachments"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil { return 0, err }
+
+	updated := 0
+	for _, doc := range docs {
+		attachments := make([]bson.D, len(doc.Attachments))
+		for i, att := range doc.Attachments {
+			attachments[i] = att
+			if _, ok := att.Map()["id"]; !ok {
+				attachments[i] = append(bson.D{{Key: "id", Value: primitive.NewObjectID().Hex()}}, att...)
+			}
+		}
+		result, err := collection.UpdateOne(ctx,
+			bson.M{"_id": doc.ID, "attachments": doc.Attachments},
+			bson.M{"$set": bson.M{"attachments": attachments}})
+		if err != nil { return updated, err }
+		updated += int(result.ModifiedCount)
+	}
+	return updated, nil
+}
+
+// legacyUploadName: Nama file upload lama di root ./uploads, "<unix>-<nama asli>"
+var legacyUploadName = regexp.MustCompile(`^[0-9]+-[^/\\]+$`)
+
+// legacyObjectKey: Key storage dari FileUrl lampiran lama ("http://localhost:3000/uploads/<nama>").
+// Kosong bila FileUrl tidak berbentuk URL upload lama.
+func legacyObjectKey(fileUrl string) string {
+	u, err := url.Parse(fileUrl)
+	if err != nil { return "" }
+	dir, name := path.Split(u.Path)
+	if dir != "/uploads/" || !legacyUploadName.MatchString(name) { return "" }
+	return name
+}
+
+// backfillAttachmentKeys mengisi storageKey lampiran lama satu kali dari FileUrl-nya. Setelah itu
+// hanya storageKey yang dipakai untuk membaca/menghapus file. Key yang dirujuk lebih dari satu lampiran
+// (mis. FileUrl tiruan yang dikirim client lewat JSON) tidak diberikan ke siapa pun dan dicatat untuk
+// diperiksa manual. Lampiran yang tidak dapat dipetakan diberi storageKey kosong agar tidak dipindai ulang.
+func backfillAttachmentKeys(ctx context.Context, collections ...*mongo.Collection) (int, error) {
+	type legacyDoc struct {
+		Collection  *mongo.Collection  `bson:"-"`
+		ID          primitive.ObjectID `bson:"_id"`
+		Attachments []bson.D           `bson:"attachments"`
+	}
+	filter := bson.M{"attachments": bson.M{"$elemMatch": bson.M{"storageKey": bson.M{"$exists": false}}}}
+	docs := []legacyDoc{}
+	for _, collection := range collections {
+		cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"attachments": 1}))
+		if err != nil { return 0, err }
+		var found []legacyDoc
+		if err := cursor.All(ctx, &found); err != nil { return 0, err }
+		for _, doc := range found {
+			doc.Collection = collection
+			docs = append(docs, doc)
+		}
+	}
+
+	// Key yang sudah dimiliki lampiran baru juga dihitung agar tidak bisa diklaim lampiran lama
+	references := map[string]int{}
+	for _, collection := range collections {
+		keys, err := collection.Distinct(ctx, "attachments.storageKey", bson.M{"attachments.storageKey": bson.M{"$nin": bson.A{nil, ""}}})
+		if err != nil { return 0, err }
+		for _, key := range keys {
+			if k, ok := key.(string); ok && k != "" { references[k]++ }
+		}
+	}
+	for _, doc := range docs {
+		for _, att := range doc.Attachments {
+			if _, ok := att.Map()["storageKey"]; ok { continue }
+			fileUrl, _ := att.Map()["fileUrl"].(string)
+			if key := legacyObjectKey(fileUrl); key != "" { references[key]++ }
+		}
+	}
+
+	updated := 0
+	for _, doc := range docs {
+		attachments := make([]bson.D, len(doc.Attachments))
+		for i, att := range doc.Attachments {
+			attachments[i] = att
+			if _, ok := att.Map()["storageKey"]; ok { continue }
+			fileUrl, _ := att.Map()["fileUrl"].(string)
+			key := legacyObjectKey(fileUrl)
+			if key != "" && references[key] > 1 {
+				log.Printf("Lampiran %s/%s merujuk file %q yang juga dirujuk lampiran lain; storage key dikosongkan, periksa manual.", doc.Collection.Name(), doc.ID.Hex(), key)
+				key = ""
+			}
+			attachments[i] = append(append(bson.D{}, att...), bson.E{Key: "storageKey", Value: key})
+		}
+		result, err := doc.Collection.UpdateOne(ctx,
+			bson.M{"_id": doc.ID, "attachments": doc.Attachments},
+			bson.M{"$set": bson.M{"attachments": attachments}})
+		if err != nil { return updated, err }
+		updated += int(result.ModifiedCount)
+	}
+	return updated, nil
+}
@@ -19,6 +19,74 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/achievement-types": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan semua tipe prestasi beserta skema field details (wajib/opsional, enum, tanggal) untuk membangun form di frontend.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievement Types"
+                ],
+                "summary": "List Tipe Prestasi",
+                "responses": {
+                    "200": {
+                        "description": "List Tipe \u0026 Skema",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievement-types/{type}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan skema details untuk satu tipe prestasi.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievement Types"
+                ],
+                "summary": "Skema Tipe Prestasi",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Kode tipe (competition, publication, organization, certification, academic, other)",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Skema Tipe",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Tipe tidak dikenal",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
         "/achievements": {
             "get": {
                 "security": [
@@ -26,7 +94,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Melihat daftar prestasi yang difilter otomatis berdasarkan Role (Admin=All, Dosen=Bimbingan, Mahasiswa=Milik Sendiri)",
+                "description": "Melihat daftar prestasi yang difilter otomatis berdasarkan Role (Admin=All, Dosen=Bimbingan, Mahasiswa=Milik Sendiri). Dosen penerima delegasi aktif juga melihat antrean Submitted bimbingan delegator (ditandai on_behalf_of). Mendukung paginasi halaman (page/limit) atau cursor (next_cursor dari respons sebelumnya; hanya untuk sort created_at/updated_at). Dibaca dari read model achievement_views.",
                 "consumes": [
                     "application/json"
                 ],
@@ -37,6 +105,92 @@ const docTemplate = `{
                     "Achievements"
                 ],
                 "summary": "List Data Prestasi",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Nomor halaman (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah per halaman (default 20, maks. 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor halaman berikutnya (menggantikan page)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status, pisahkan dengan koma",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tipe prestasi",
+                        "name": "achievementType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tag, pisahkan dengan koma (harus memiliki semua)",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Student ID (UUID)",
+                        "name": "studentId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Kolom rentang tanggal: created_at (default), updated_at, submitted_at, verified_at",
+                        "name": "dateField",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal awal (YYYY-MM-DD)",
+                        "name": "dateFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tanggal akhir, inklusif (YYYY-MM-DD)",
+                        "name": "dateTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Poin minimal",
+                        "name": "minPoints",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Poin maksimal",
+                        "name": "maxPoints",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at (default), updated_at, submitted_at, verified_at",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "asc atau desc (default)",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "Data Prestasi",
@@ -45,6 +199,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "400": {
+                        "description": "Parameter tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
@@ -67,7 +228,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mahasiswa membuat draft laporan prestasi baru.",
+                "description": "Mahasiswa membuat draft laporan prestasi baru. Poin dihitung otomatis dari aturan poin (tipe, tingkat, peringkat, jumlah anggota tim). Untuk prestasi tim isi teamMembers; setiap anggota mendapat referensi tertaut dan harus mengonfirmasi partisipasi sebelum prestasi dapat disubmit. Referensi dan niat penulisan detail disimpan dalam satu transaksi PostgreSQL; synced=false berarti detail MongoDB masih menunggu worker outbox.",
                 "consumes": [
                     "application/json"
                 ],
@@ -98,7 +259,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Bad Request / details tidak sesuai skema (lihat field errors)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -114,14 +275,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/achievements/{id}": {
-            "get": {
+        "/achievements/bulk/reject": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Melihat detail lengkap satu prestasi (Gabungan Postgres \u0026 Mongo)",
+                "description": "Menolak banyak prestasi sekaligus dengan satu catatan penolakan bersama (wajib). Setiap ID diproses terpisah (partial success).",
                 "consumes": [
                     "application/json"
                 ],
@@ -131,47 +292,51 @@ const docTemplate = `{
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Detail Prestasi",
+                "summary": "Tolak Massal (Dosen/Verifikator Tahap)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Achievement ID (Postgres UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Daftar ID prestasi \u0026 catatan penolakan",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.BulkActionRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Hasil per ID",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Input tidak valid",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/achievements/bulk/verify": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update data prestasi (Hanya jika status Draft/Rejected).",
+                "description": "Menyetujui tahap verifikasi untuk banyak prestasi sekaligus. Setiap ID diproses terpisah (partial success); hasil per ID menyertakan alasan gagal: not_found, invalid_status, not_advisee, wrong_stage.",
                 "consumes": [
                     "application/json"
                 ],
@@ -181,79 +346,83 @@ const docTemplate = `{
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Update Prestasi",
+                "summary": "Verifikasi Massal (Dosen/Verifikator Tahap)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Achievement ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Data Update",
+                        "description": "Daftar ID prestasi \u0026 catatan opsional",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.AchievementInput"
+                            "$ref": "#/definitions/model.BulkActionRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Hasil per ID",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Status bukan Draft",
+                        "description": "Input tidak valid",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "403": {
-                        "description": "Bukan pemilik",
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/achievements/overdue": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Menghapus prestasi (Hanya jika status Draft).",
+                "description": "Daftar prestasi Submitted yang tertahan di tahap verifikasi aktif lebih lama dari verification_sla_days, dikelompokkan per Dosen Wali. Admin melihat semua (opsional filter advisor_id), Dosen Wali hanya mahasiswa bimbingannya. overdue_days = jumlah hari melewati SLA.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Hapus Prestasi (Soft Delete)",
+                "summary": "Prestasi Melewati SLA Verifikasi",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Achievement ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter Lecturer ID (khusus Admin)",
+                        "name": "advisor_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Overdue per Dosen Wali",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -262,40 +431,60 @@ const docTemplate = `{
                 }
             }
         },
-        "/achievements/{id}/attachments": {
-            "post": {
+        "/achievements/search": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengunggah file lampiran prestasi.",
-                "consumes": [
-                    "multipart/form-data"
+                "description": "Pencarian teks pada judul, deskripsi dan tag prestasi, diurutkan berdasarkan relevansi (score). Cakupan data sama dengan List Data Prestasi sesuai Role; dibaca dari read model achievement_views.",
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Upload Attachment",
+                "summary": "Cari Prestasi (Full-text)",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Achievement ID",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Kata kunci",
+                        "name": "q",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "File Lampiran",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "type": "integer",
+                        "description": "Jumlah hasil (default 20, maks. 100)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Hasil Pencarian",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Parameter tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -304,27 +493,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/achievements/{id}/history": {
+        "/achievements/trash": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Melihat log perubahan status prestasi.",
+                "description": "Daftar prestasi yang dihapus (Mahasiswa: milik sendiri, Admin: semua) beserta waktu purge permanen sesuai pengaturan trash_retention_days.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Lihat Riwayat Status",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Achievement ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "Trash Prestasi",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -332,41 +515,42 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/achievements/{id}/reject": {
-            "post": {
+        "/achievements/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengubah status menjadi Rejected dengan catatan.",
+                "description": "Melihat detail lengkap satu prestasi (Gabungan Postgres \u0026 Mongo) beserta thread komentar",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Tolak Prestasi (Dosen)",
+                "summary": "Detail Prestasi",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Achievement ID",
+                        "description": "Achievement ID (Postgres UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Rejection Note: {'rejection_note': '...'}",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
                     }
                 ],
                 "responses": {
@@ -376,22 +560,107 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/achievements/{id}/submit": {
-            "post": {
+                    },
+                    "403": {
+                        "description": "Bukan pemilik/dosen wali",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengubah status Draft menjadi Submitted.",
+                "description": "Update data prestasi (Hanya jika status Draft/Rejected/Revision Requested).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Submit untuk Verifikasi",
+                "summary": "Update Prestasi",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Data Update",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AchievementInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Status bukan Draft / details tidak sesuai skema",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Bukan pemilik",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Prestasi masih disinkronkan ke MongoDB",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Memindahkan prestasi ke trash (Hanya jika status Draft). Dapat di-restore sampai masa retensi trash habis.",
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Hapus Prestasi (Soft Delete)",
                 "parameters": [
                     {
                         "type": "string",
@@ -419,18 +688,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/achievements/{id}/verify": {
+        "/achievements/{id}/attachments": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengubah status menjadi Verified.",
+                "description": "Mengunggah file lampiran prestasi. Kategori dipakai untuk memeriksa syarat bukti saat submit (mis. sertifikat \u0026 foto untuk kompetisi). Hanya pemilik saat status draft, rejected atau revision_requested. Hanya PDF, PNG dan JPEG; jenis file diperiksa dari isinya, bukan header Content-Type. Penolakan dikirim di errors[] dengan reason: empty_file, file_too_large, unsupported_type, content_mismatch.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Verifikasi Prestasi (Dosen)",
+                "summary": "Upload Attachment",
                 "parameters": [
                     {
                         "type": "string",
@@ -438,6 +710,19 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File Lampiran (PDF maks. 10 MB, PNG/JPEG maks. 5 MB secara default)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Kategori: certificate, photo, document, other (default other)",
+                        "name": "category",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
@@ -447,104 +732,1658 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "400": {
+                        "description": "File kosong / kategori tidak valid / status tidak dapat diubah",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Prestasi masih disinkronkan ke MongoDB",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "Ukuran file melebihi batas jenisnya",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "415": {
+                        "description": "Jenis file tidak didukung atau isi tidak cocok dengan ekstensi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/auth/login": {
-            "post": {
-                "description": "Otentikasi user menggunakan email/username dan password untuk mendapatkan JWT Token.",
-                "consumes": [
-                    "application/json"
+        "/achievements/{id}/attachments/{attachmentId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Mengalirkan file lampiran prestasi setelah memeriksa akses (pemilik, dosen wali, Admin). Mendukung header Range (satu rentang) untuk unduhan sebagian, mis. PDF besar. download=true memaksa Content-Disposition attachment.",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Achievements"
                 ],
-                "summary": "Login Pengguna",
+                "summary": "Unduh Lampiran",
                 "parameters": [
                     {
-                        "description": "Login Payload",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Unduh sebagai file (default tampil inline)",
+                        "name": "download",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rentang byte, mis. bytes=0-1023",
+                        "name": "Range",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.LoginRequest"
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Bukan pemilik/dosen wali",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "416": {
+                        "description": "Range di luar ukuran file",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengganti file satu lampiran dengan ID dan posisi yang sama. File lama dihapus dari storage setelah data tersimpan. Kategori opsional (default kategori lama). Hanya pemilik saat status draft, rejected atau revision_requested.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Ganti Lampiran",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File pengganti (PDF maks. 10 MB, PNG/JPEG maks. 5 MB)",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "certificate | photo | document | other",
+                        "name": "category",
+                        "in": "formData"
+                    }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success Login",
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Status bukan draft/rejected",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Bukan pemilik",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Prestasi masih disinkronkan ke MongoDB",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "File terlalu besar",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "415": {
+                        "description": "Jenis file tidak didukung",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus satu lampiran beserta file-nya di storage. Hanya pemilik (pembuat untuk prestasi tim) saat status draft, rejected atau revision_requested.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Hapus Lampiran",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Status bukan draft/rejected",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Bukan pemilik",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Prestasi masih disinkronkan ke MongoDB",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/comments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan komentar antara mahasiswa dan verifikator, dikelompokkan per komentar utama beserta balasannya (urut waktu).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Thread Komentar Prestasi",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengirim komentar (atau balasan dengan parent_id) pada prestasi. Dapat dikirim sebagai JSON atau multipart form dengan lampiran pada key \"files\" (maks. 5 file, ukuran tiap file sesuai batas jenisnya; total request maks. 5x batas terbesar + 1 MB). Hanya pihak yang boleh melihat prestasi (pemilik/anggota tim, dosen wali/delegasi, Admin).",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Tambah Komentar Prestasi",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Isi komentar (wajib bila tanpa lampiran)",
+                        "name": "message",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID komentar yang dibalas",
+                        "name": "parent_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Lampiran komentar (PDF/PNG/JPEG, aturan sama dengan lampiran prestasi)",
+                        "name": "files",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "Ukuran file melebihi batas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/comments/{commentId}/attachments/{attachmentId}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengalirkan file lampiran komentar dengan aturan akses yang sama seperti membaca prestasi (pemilik/anggota tim, dosen wali/delegasi, penerima eskalasi, Admin). Mendukung header Range seperti unduh lampiran prestasi.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Unduh Lampiran Komentar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Unduh sebagai file (default tampil inline)",
+                        "name": "download",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rentang byte, mis. bytes=0-1023",
+                        "name": "Range",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Tidak berhak melihat prestasi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "416": {
+                        "description": "Range di luar ukuran file",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/confirm-participation": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Anggota prestasi tim mengonfirmasi keikutsertaannya. Prestasi tim baru dapat disubmit setelah semua anggota mengonfirmasi.",
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Konfirmasi Partisipasi Tim (Mahasiswa)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID (referensi milik anggota)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Melihat log perubahan status prestasi (urut dari yang paling lama), termasuk aktor dan catatan tiap perubahan.",
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Lihat Riwayat Status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/override-status": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Memindahkan prestasi ke status apa pun (draft, submitted, revision_requested, verified, rejected) di luar alur normal, misalnya untuk memperbaiki salah verifikasi. Alasan wajib (min. 10 karakter) dan dicatat di riwayat sebagai override. Mengubah prestasi Verified memengaruhi statistik \u0026 laporan sehingga wajib confirm=true.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Override Status Prestasi (Admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status tujuan \u0026 alasan",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.StatusOverrideRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Status/alasan tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Bukan Admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Perlu konfirmasi (prestasi Verified) / status sudah berubah",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengubah status menjadi Rejected dengan catatan. Penolakan bersifat final (gunakan request-revision untuk meminta perbaikan).",
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Tolak Prestasi (Dosen)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rejection Note: {'rejection_note': '...'}",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/request-revision": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengembalikan prestasi Submitted ke mahasiswa untuk diperbaiki (status Revision Requested). Mahasiswa dapat mengedit lalu submit ulang; jumlah siklus revisi dicatat.",
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Minta Revisi Prestasi (Dosen)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Catatan Revisi: {'revision_note': '...'}",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengembalikan prestasi yang dihapus ke status Draft (PostgreSQL \u0026 MongoDB). Mahasiswa pemilik atau Admin. synced=false berarti detail MongoDB masih menunggu worker outbox.",
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Restore Prestasi dari Trash",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan semua revisi konten (title, description, details, tags) dari yang terlama. Versi 1 = konten awal.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Riwayat Revisi Konten Prestasi",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/revisions/diff": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menampilkan perbedaan field per field (title, description, details.*, tags) antara dua versi. Default: versi terakhir dibandingkan dengan versi sebelumnya.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Bandingkan Dua Revisi",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Versi awal (default: to - 1)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Versi akhir (default: versi terakhir)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/submit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengubah status Draft (atau Revision Requested untuk pengajuan ulang) menjadi Submitted. Syarat bukti tipe prestasi (lihat /achievement-types) harus terpenuhi; bila belum, daftar unmet_requirements dikembalikan.",
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Submit untuk Verifikasi",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menyetujui tahap verifikasi yang sedang berjalan. Jika masih ada tahap berikutnya prestasi diteruskan (status tetap Submitted), jika tahap terakhir status menjadi Verified. Dosen penerima delegasi aktif dapat memproses bimbingan delegator; riwayat mencatat on_behalf_of.",
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Verifikasi Prestasi (Dosen/Verifikator Tahap)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Bukan verifikator tahap ini",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/withdraw": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengembalikan prestasi Submitted ke Draft agar bisa diperbaiki. Hanya pemilik dan hanya selama verifikator belum memproses (belum ada tahap yang disetujui/ditolak).",
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Tarik Kembali Pengajuan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Otentikasi user menggunakan email/username dan password untuk mendapatkan JWT Token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Login Pengguna",
+                "parameters": [
+                    {
+                        "description": "Login Payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success Login",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized (Salah Password/Tidak Aktif)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Melakukan logout (Client-side clear token).",
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan informasi profil user yang sedang login.",
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Get User Profile",
+                "responses": {
+                    "200": {
+                        "description": "User Profile",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Mendapatkan Access Token baru menggunakan Refresh Token yang valid.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Refresh Access Token",
+                "parameters": [
+                    {
+                        "description": "Refresh Token Payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token Baru",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Token Expired/Invalid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/delegations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin melihat semua delegasi; Dosen Wali melihat delegasi di mana ia menjadi delegator atau delegate. Gunakan active=true untuk hanya delegasi yang sedang berlaku.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Delegations"
+                ],
+                "summary": "List Delegasi Verifikasi",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Hanya delegasi yang sedang berlaku",
+                        "name": "active",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List Delegasi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Melimpahkan verifikasi mahasiswa bimbingan ke dosen lain selama jendela waktu tertentu (maks. 180 hari), misalnya saat cuti. Dosen Wali mendelegasikan bimbingannya sendiri; Admin wajib mengisi delegator_id. Satu delegator hanya boleh punya satu delegasi pada waktu yang sama.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Delegations"
+                ],
+                "summary": "Buat Delegasi Verifikasi",
+                "parameters": [
+                    {
+                        "description": "Delegasi (starts_at/ends_at format RFC3339)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.DelegationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Bertumpang tindih dengan delegasi lain",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/delegations/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengakhiri delegasi lebih awal. Hanya Admin atau dosen delegator.",
+                "tags": [
+                    "Delegations"
+                ],
+                "summary": "Cabut Delegasi Verifikasi",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Delegation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Bukan delegator",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Sudah dicabut",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/lecturers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan daftar semua dosen yang terdaftar di sistem.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lecturers"
+                ],
+                "summary": "List Semua Dosen",
+                "responses": {
+                    "200": {
+                        "description": "List Dosen",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/lecturers/{id}/advisees": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan daftar mahasiswa yang dibimbing oleh Dosen tertentu berdasarkan ID Dosen.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lecturers"
+                ],
+                "summary": "List Mahasiswa Bimbingan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lecturer ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List Mahasiswa Bimbingan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Dosen Tidak Ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/point-rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan semua aturan poin. Level/rank kosong berarti berlaku untuk semua nilai; aturan paling spesifik yang dipakai.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Point Rules"
+                ],
+                "summary": "List Aturan Poin (Admin)",
+                "responses": {
+                    "200": {
+                        "description": "List Aturan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menambah aturan poin untuk kombinasi tipe/level/rank. team_percent = persentase poin per anggota bila prestasi tim (default 100).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Point Rules"
+                ],
+                "summary": "Buat Aturan Poin (Admin)",
+                "parameters": [
+                    {
+                        "description": "Aturan Poin",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PointRuleInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Aturan untuk kombinasi ini sudah ada",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/point-rules/recalculate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menerapkan aturan poin terbaru ke semua prestasi yang belum diverifikasi (poin prestasi verified sudah dibekukan). Mengembalikan daftar perubahan poin. Gunakan dry_run=true untuk pratinjau tanpa menyimpan.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Point Rules"
+                ],
+                "summary": "Hitung Ulang Poin (Admin)",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Hanya hitung, tidak menyimpan",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daftar Delta",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/point-rules/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Point Rules"
+                ],
+                "summary": "Update Aturan Poin (Admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Aturan Poin",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.PointRuleInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized (Salah Password/Tidak Aktif)",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/auth/logout": {
-            "post": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Melakukan logout (Client-side clear token).",
                 "tags": [
-                    "Authentication"
+                    "Admin - Point Rules"
                 ],
-                "summary": "Logout",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/profile": {
-            "get": {
-                "security": [
+                "summary": "Hapus Aturan Poin (Admin)",
+                "parameters": [
                     {
-                        "BearerAuth": []
+                        "type": "string",
+                        "description": "Rule ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
-                "description": "Mendapatkan informasi profil user yang sedang login.",
-                "tags": [
-                    "Authentication"
-                ],
-                "summary": "Get User Profile",
                 "responses": {
                     "200": {
-                        "description": "User Profile",
+                        "description": "Deleted",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -556,58 +2395,43 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
-                    },
-                    "500": {
-                        "description": "Internal Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
                     }
                 }
             }
         },
-        "/auth/refresh": {
+        "/reconcile": {
             "post": {
-                "description": "Mendapatkan Access Token baru menggunakan Refresh Token yang valid.",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Memindai achievement_references dan dokumen prestasi MongoDB lalu melaporkan: mongo_achievement_id rusak, referensi tanpa dokumen, dokumen tanpa referensi, serta status deleted yang tidak sesuai deletedAt. Default dry run (hanya laporan). Dengan dry_run=false: referensi rusak/tanpa dokumen dipindah ke trash, dokumen yatim dihapus permanen, dan deletedAt dokumen disesuaikan dengan status PostgreSQL. Data dengan event outbox tertunda atau yang baru berubah dilewati (skipped).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Admin - Maintenance"
                 ],
-                "summary": "Refresh Access Token",
+                "summary": "Rekonsiliasi PostgreSQL \u0026 MongoDB (Admin)",
                 "parameters": [
                     {
-                        "description": "Refresh Token Payload",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.RefreshTokenRequest"
-                        }
+                        "type": "boolean",
+                        "description": "Hanya laporan tanpa perbaikan (default true)",
+                        "name": "dry_run",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Token Baru",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "Laporan Rekonsiliasi",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Token Expired/Invalid",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -616,14 +2440,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/lecturers": {
+        "/reports/statistics": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mendapatkan daftar semua dosen yang terdaftar di sistem.",
+                "description": "Mendapatkan statistik prestasi (Total per tipe \u0026 level) berdasarkan Role user (Admin: Semua, Mahasiswa: Pribadi, Dosen: Bimbingan).",
                 "consumes": [
                     "application/json"
                 ],
@@ -631,12 +2455,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Lecturers"
+                    "Reports"
                 ],
-                "summary": "List Semua Dosen",
+                "summary": "Dashboard Statistik",
                 "responses": {
                     "200": {
-                        "description": "List Dosen",
+                        "description": "Statistik Data",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -652,14 +2483,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/lecturers/{id}/advisees": {
+        "/reports/student/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mendapatkan daftar mahasiswa yang dibimbing oleh Dosen tertentu berdasarkan ID Dosen.",
+                "description": "Melihat daftar lengkap prestasi milik satu mahasiswa spesifik (Admin/Dosen Wali pembimbing/Mahasiswa ybs), termasuk prestasi tim sebagai anggota. Dibaca dari read model achievement_views.",
                 "consumes": [
                     "application/json"
                 ],
@@ -667,13 +2498,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Lecturers"
+                    "Reports"
                 ],
-                "summary": "List Mahasiswa Bimbingan",
+                "summary": "Laporan Detail Mahasiswa",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Lecturer ID (UUID)",
+                        "description": "Student ID (UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -681,14 +2512,14 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "List Mahasiswa Bimbingan",
+                        "description": "List Prestasi",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Dosen Tidak Ditemukan",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -704,34 +2535,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/reports/statistics": {
+        "/settings": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mendapatkan statistik prestasi (Total per tipe \u0026 level) berdasarkan Role user (Admin: Semua, Mahasiswa: Pribadi, Dosen: Bimbingan).",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mendapatkan semua pengaturan sistem (key-value), misalnya trash_retention_days.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Reports"
+                    "Admin - Settings"
                 ],
-                "summary": "Dashboard Statistik",
+                "summary": "List Pengaturan Sistem (Admin)",
                 "responses": {
                     "200": {
-                        "description": "Statistik Data",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
+                        "description": "List Pengaturan",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -747,14 +2568,53 @@ const docTemplate = `{
                 }
             }
         },
-        "/reports/student/{id}": {
+        "/settings/{key}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Melihat daftar lengkap prestasi milik satu mahasiswa spesifik (Admin/Dosen Wali/Mahasiswa ybs).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Settings"
+                ],
+                "summary": "Detail Pengaturan Sistem (Admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key Pengaturan",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengubah nilai satu pengaturan. trash_retention_days: jumlah hari (1-3650) prestasi disimpan di trash sebelum dihapus permanen. verification_sla_days: batas hari (1-365) prestasi menunggu verifikasi sebelum dieskalasi. sla_fallback_verifier_id: user ID penerima eskalasi (kosong = semua Admin).",
                 "consumes": [
                     "application/json"
                 ],
@@ -762,35 +2622,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Reports"
+                    "Admin - Settings"
                 ],
-                "summary": "Laporan Detail Mahasiswa",
+                "summary": "Update Pengaturan Sistem (Admin)",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Student ID (UUID)",
-                        "name": "id",
+                        "description": "Key Pengaturan",
+                        "name": "key",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Nilai Baru",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.SettingUpdateRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List Prestasi",
+                        "description": "Updated",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Key tidak dikenal",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -915,6 +2784,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1281,12 +3157,6 @@ const docTemplate = `{
                 "achievementType": {
                     "type": "string"
                 },
-                "attachments": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Attachment"
-                    }
-                },
                 "description": {
                     "type": "string"
                 },
@@ -1294,20 +3164,43 @@ const docTemplate = `{
                     "type": "object",
                     "additionalProperties": true
                 },
-                "points": {
-                    "type": "integer"
-                },
                 "tags": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
+                "teamMembers": {
+                    "description": "Opsional: prestasi tim (hanya dipakai saat pembuatan)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TeamMember"
+                    }
+                },
                 "title": {
                     "type": "string"
                 }
             }
         },
+        "model.AchievementStatus": {
+            "type": "string",
+            "enum": [
+                "draft",
+                "submitted",
+                "revision_requested",
+                "verified",
+                "rejected",
+                "deleted"
+            ],
+            "x-enum-varnames": [
+                "StatusDraft",
+                "StatusSubmitted",
+                "StatusRevisionRequested",
+                "StatusVerified",
+                "StatusRejected",
+                "StatusDeleted"
+            ]
+        },
         "model.AssignRoleRequest": {
             "type": "object",
             "required": [
@@ -1319,19 +3212,36 @@ const docTemplate = `{
                 }
             }
         },
-        "model.Attachment": {
+        "model.BulkActionRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DelegationRequest": {
             "type": "object",
             "properties": {
-                "file_name": {
+                "delegate_id": {
+                    "type": "string"
+                },
+                "delegator_id": {
                     "type": "string"
                 },
-                "file_type": {
+                "ends_at": {
                     "type": "string"
                 },
-                "file_url": {
+                "reason": {
                     "type": "string"
                 },
-                "uploaded_at": {
+                "starts_at": {
                     "type": "string"
                 }
             }
@@ -1351,6 +3261,29 @@ const docTemplate = `{
                 }
             }
         },
+        "model.PointRuleInput": {
+            "type": "object",
+            "required": [
+                "achievement_type"
+            ],
+            "properties": {
+                "achievement_type": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "string"
+                },
+                "team_percent": {
+                    "type": "integer"
+                }
+            }
+        },
         "model.RefreshTokenRequest": {
             "type": "object",
             "required": [
@@ -1373,6 +3306,39 @@ const docTemplate = `{
                 }
             }
         },
+        "model.SettingUpdateRequest": {
+            "type": "object",
+            "properties": {
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.StatusOverrideRequest": {
+            "type": "object",
+            "properties": {
+                "confirm": {
+                    "type": "boolean"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.AchievementStatus"
+                }
+            }
+        },
+        "model.TeamMember": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                },
+                "student_id": {
+                    "type": "string"
+                }
+            }
+        },
         "model.UserCreateRequest": {
             "type": "object",
             "required": [
@@ -1,11 +1,23 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"log"
 	"os"
+	"time"
 	
 	"github.com/gofiber/fiber/v2"
+	"github.com/safrizal-hk/uas-gofiber/app/job"
+	modelMongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	"github.com/safrizal-hk/uas-gofiber/app/outbox"
+	"github.com/safrizal-hk/uas-gofiber/app/readmodel"
+	"github.com/safrizal-hk/uas-gofiber/app/reconcile"
+	repo_mongo "github.com/safrizal-hk/uas-gofiber/app/repository/mongo"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
 	"github.com/safrizal-hk/uas-gofiber/config"
+	"github.com/safrizal-hk/uas-gofiber/middleware"
 	"github.com/safrizal-hk/uas-gofiber/route" 
 
 	_ "github.com/safrizal-hk/uas-gofiber/docs" 
@@ -34,13 +46,71 @@ func main() {
 	config.LoadEnv()
 
 	dbConn := config.NewDB()
+
+	// Subcommand: go run . reconcile [-apply] | go run . rebuild-views
+	if len(os.Args) > 1 {
+		var code int
+		switch os.Args[1] {
+		case "reconcile":
+			code = runReconcile(dbConn, os.Args[2:])
+		case "rebuild-views":
+			code = runRebuildViews(dbConn, os.Args[2:])
+		default:
+			log.Printf("subcommand tidak dikenal: %s", os.Args[1])
+			code = 2
+		}
+		dbConn.PgDB.Close()
+		os.Exit(code)
+	}
 	
 	defer dbConn.PgDB.Close()
 	
-	app := fiber.New() 
+	// Batas body cukup untuk komentar dengan lampiran terbanyak berukuran maksimal (ukuran tiap file
+	// tetap diperiksa validator); body yang lebih besar ditolak dengan format penolakan upload
+	uploadValidator := config.LoadUploadValidator()
+	app := fiber.New(fiber.Config{
+		BodyLimit:    uploadValidator.BodyLimit(modelMongo.MaxCommentAttachments),
+		ErrorHandler: middleware.ErrorHandler(uploadValidator),
+	})
 
 	route.RegisterAllRoutes(app, dbConn)
 
+	// Listing, pencarian & laporan membaca achievement_views; bangun dari data yang sudah ada bila masih
+	// kosong (deploy pertama). Rebuild penuh berikutnya lewat subcommand rebuild-views.
+	buildCtx, cancelBuild := context.WithTimeout(context.Background(), envDuration("VIEW_BUILD_TIMEOUT", 10*time.Minute))
+	if count, err := newProjector(dbConn).RebuildIfEmpty(buildCtx); err != nil {
+		log.Printf("build awal read model gagal (jalankan rebuild-views): %v", err)
+	} else if count > 0 {
+		log.Printf("build awal read model selesai: %d view ditulis", count)
+	}
+	cancelBuild()
+
+	// Background job: hapus permanen prestasi di trash yang melewati masa retensi
+	purger := job.NewTrashPurger(
+		repo_postgre.NewAchievementPGRepository(dbConn.PgDB),
+		repo_mongo.NewAchievementMongoRepository(dbConn.MongoDB),
+		repo_mongo.NewAchievementRevisionRepository(dbConn.MongoDB),
+		repo_mongo.NewAchievementCommentRepository(dbConn.MongoDB),
+		repo_postgre.NewSettingRepository(dbConn.PgDB),
+		config.LoadStorage(),
+	)
+	go purger.Start(context.Background(), envDuration("TRASH_PURGE_INTERVAL", time.Hour))
+
+	// Background job: eskalasi prestasi Submitted yang melewati SLA verifikasi
+	escalator := job.NewSLAEscalator(
+		repo_postgre.NewEscalationRepository(dbConn.PgDB),
+		repo_postgre.NewSettingRepository(dbConn.PgDB),
+	)
+	go escalator.Start(context.Background(), envDuration("SLA_CHECK_INTERVAL", time.Hour))
+
+	// Background job: terapkan ulang penulisan MongoDB yang tercatat di outbox tetapi belum berhasil
+	outboxWorker := job.NewOutboxWorker(outbox.NewDispatcher(
+		repo_postgre.NewOutboxRepository(dbConn.PgDB),
+		repo_mongo.NewAchievementMongoRepository(dbConn.MongoDB),
+		newProjector(dbConn),
+	))
+	go outboxWorker.Start(context.Background(), envDuration("OUTBOX_INTERVAL", 30*time.Second))
+
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
 	
 	port := os.Getenv("APP_PORT")
@@ -49,4 +119,73 @@ func main() {
 	}
 
 	log.Fatal(app.Listen(":" + port))
-}
\ No newline at end of file
+}
+
+// envDuration membaca durasi dari env (format time.ParseDuration, mis. "30m"), fallback bila kosong/invalid
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// runReconcile menjalankan rekonsiliasi PostgreSQL/MongoDB dari CLI dan mencetak laporan JSON ke stdout.
+// Tanpa -apply hanya laporan (dry run).
+func runReconcile(dbConn *config.Database, args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	apply := flags.Bool("apply", false, "terapkan perbaikan (default: dry run)")
+	timeout := flags.Duration("timeout", 10*time.Minute, "batas waktu pemindaian")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	reconciler := reconcile.New(
+		repo_postgre.NewAchievementPGRepository(dbConn.PgDB),
+		repo_mongo.NewAchievementMongoRepository(dbConn.MongoDB),
+		repo_postgre.NewOutboxRepository(dbConn.PgDB),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := reconciler.Run(ctx, !*apply, "")
+	if err != nil {
+		log.Printf("rekonsiliasi gagal: %v", err)
+		return 1
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runRebuildViews membangun ulang read model achievement_views dari PostgreSQL & MongoDB
+func runRebuildViews(dbConn *config.Database, args []string) int {
+	flags := flag.NewFlagSet("rebuild-views", flag.ContinueOnError)
+	timeout := flags.Duration("timeout", 10*time.Minute, "batas waktu rebuild")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	count, err := newProjector(dbConn).Rebuild(ctx)
+	if err != nil {
+		log.Printf("rebuild read model gagal: %v", err)
+		return 1
+	}
+	log.Printf("rebuild read model selesai: %d view ditulis", count)
+	return 0
+}
+
+func newProjector(dbConn *config.Database) *readmodel.Projector {
+	return readmodel.NewProjector(
+		repo_postgre.NewAchievementPGRepository(dbConn.PgDB),
+		repo_mongo.NewAchievementMongoRepository(dbConn.MongoDB),
+		repo_mongo.NewAchievementViewRepository(dbConn.MongoDB),
+	)
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/safrizal-hk/uas-gofiber/app/upload"
+)
+
+// ErrorHandler: Error handler aplikasi. Request yang melebihi BodyLimit ditolak server sebelum
+// handler upload berjalan, sehingga penolakannya dikirim di sini dengan format yang sama seperti
+// validasi upload (errors[] dengan reason file_too_large).
+func ErrorHandler(validator *upload.Validator) fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		if errors.Is(err, fiber.ErrRequestEntityTooLarge) {
+			rejection := validator.BodyTooLarge()
+			return c.Status(rejection.Status).JSON(fiber.Map{"message": rejection.Message, "code": strconv.Itoa(rejection.Status), "errors": []upload.Rejection{*rejection}})
+		}
+		return fiber.DefaultErrorHandler(c, err)
+	}
+}
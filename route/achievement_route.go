@@ -6,17 +6,40 @@ import (
 	"github.com/safrizal-hk/uas-gofiber/middleware"
 )
 
-func RegisterAchievementRoutes(v1 fiber.Router, achievementService *service.AchievementService) {
+func RegisterAchievementRoutes(v1 fiber.Router, achievementService *service.AchievementService, escalationService *service.EscalationService) {
 	protected := v1.Group("/achievements", middleware.AuthRequired) 
 	
 	protected.Get("/", middleware.RBACRequired("achievement:read"), achievementService.ListAllAchievements)
+	// Rute statis /bulk/*, /trash, /search dan /overdue harus didaftarkan sebelum /:id/* agar "bulk" tidak terbaca sebagai ID
+	protected.Post("/bulk/verify", middleware.RBACRequired("achievement:verify"), achievementService.BulkVerifyPrestasi)
+	protected.Post("/bulk/reject", middleware.RBACRequired("achievement:verify"), achievementService.BulkRejectPrestasi)
+	protected.Get("/trash", middleware.RBACRequired("achievement:read"), achievementService.ListTrash)
+	protected.Get("/search", middleware.RBACRequired("achievement:read"), achievementService.SearchAchievements)
+	protected.Get("/overdue", middleware.RBACRequired("achievement:verify"), escalationService.ListOverdue)
 	protected.Get("/:id", middleware.RBACRequired("achievement:read"), achievementService.GetAchievementDetail)
 	protected.Post("/", middleware.RBACRequired("achievement:create"), achievementService.SubmitPrestasi)
 	protected.Put("/:id", middleware.RBACRequired("achievement:update"), achievementService.UpdatePrestasi)
 	protected.Delete("/:id", middleware.RBACRequired("achievement:delete"), achievementService.DeletePrestasi)
+	protected.Post("/:id/restore", middleware.RBACRequired("achievement:delete"), achievementService.RestorePrestasi)
 	protected.Post("/:id/submit", middleware.RBACRequired("achievement:update"), achievementService.SubmitForVerification)
+	protected.Post("/:id/withdraw", middleware.RBACRequired("achievement:update"), achievementService.WithdrawPrestasi)
+	protected.Post("/:id/confirm-participation", middleware.RBACRequired("achievement:update"), achievementService.ConfirmParticipation)
 	protected.Post("/:id/verify", middleware.RBACRequired("achievement:verify"), achievementService.VerifyPrestasi)
 	protected.Post("/:id/reject", middleware.RBACRequired("achievement:verify"), achievementService.RejectPrestasi)
+	protected.Post("/:id/request-revision", middleware.RBACRequired("achievement:verify"), achievementService.RequestRevisionPrestasi)
+	protected.Post("/:id/override-status", middleware.RBACRequired("user:manage"), achievementService.OverrideStatus)
 	protected.Get("/:id/history", middleware.RBACRequired("achievement:read"), achievementService.GetHistory)
+	protected.Get("/:id/revisions", middleware.RBACRequired("achievement:read"), achievementService.ListRevisions)
+	protected.Get("/:id/revisions/diff", middleware.RBACRequired("achievement:read"), achievementService.DiffRevisions)
+	protected.Get("/:id/comments", middleware.RBACRequired("achievement:read"), achievementService.ListComments)
+	protected.Post("/:id/comments", middleware.RBACRequired("achievement:read"), achievementService.AddComment)
+	protected.Get("/:id/comments/:commentId/attachments/:attachmentId", middleware.RBACRequired("achievement:read"), achievementService.DownloadCommentAttachment)
 	protected.Post("/:id/attachments", middleware.RBACRequired("achievement:update"), achievementService.AddAttachment)
-}
\ No newline at end of file
+	protected.Get("/:id/attachments/:attachmentId", middleware.RBACRequired("achievement:read"), achievementService.DownloadAttachment)
+	protected.Put("/:id/attachments/:attachmentId", middleware.RBACRequired("achievement:update"), achievementService.ReplaceAttachment)
+	protected.Delete("/:id/attachments/:attachmentId", middleware.RBACRequired("achievement:update"), achievementService.DeleteAttachment)
+
+	types := v1.Group("/achievement-types", middleware.AuthRequired)
+	types.Get("/", achievementService.ListAchievementTypes)
+	types.Get("/:type", achievementService.GetAchievementType)
+}
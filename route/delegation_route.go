@@ -0,0 +1,18 @@
+package route
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/safrizal-hk/uas-gofiber/app/service"
+	"github.com/safrizal-hk/uas-gofiber/middleware"
+)
+
+func RegisterDelegationRoutes(v1 fiber.Router, delegationService *service.DelegationService) {
+
+	const verifyPerm = "achievement:verify"
+
+	delegationRoute := v1.Group("/delegations", middleware.AuthRequired)
+
+	delegationRoute.Get("/", middleware.RBACRequired(verifyPerm), delegationService.ListDelegations)
+	delegationRoute.Post("/", middleware.RBACRequired(verifyPerm), delegationService.CreateDelegation)
+	delegationRoute.Delete("/:id", middleware.RBACRequired(verifyPerm), delegationService.RevokeDelegation)
+}
@@ -15,23 +15,44 @@ func RegisterAllRoutes(app *fiber.App, dbConn *config.Database) {
 	authRepo := repo_postgre.NewAuthRepository(dbConn.PgDB) 
 	achievementPgRepo := repo_postgre.NewAchievementPGRepository(dbConn.PgDB)
 	achievementMongoRepo := repo_mongo.NewAchievementMongoRepository(dbConn.MongoDB)
+	achievementRevisionRepo := repo_mongo.NewAchievementRevisionRepository(dbConn.MongoDB)
+	achievementCommentRepo := repo_mongo.NewAchievementCommentRepository(dbConn.MongoDB)
 	reportPgRepo := repo_postgre.NewReportPGRepository(dbConn.PgDB)
 	reportMongoRepo := repo_mongo.NewReportMongoRepository(dbConn.MongoDB)
 	userRepo := repo_postgre.NewAdminManageUsersRepository(dbConn.PgDB)
 	studentRepo := repo_postgre.NewStudentRepository(dbConn.PgDB)
 	lecturerRepo := repo_postgre.NewLecturerRepository(dbConn.PgDB)
+	pointRuleRepo := repo_postgre.NewPointRuleRepository(dbConn.PgDB)
+	settingRepo := repo_postgre.NewSettingRepository(dbConn.PgDB)
+	escalationRepo := repo_postgre.NewEscalationRepository(dbConn.PgDB)
+	delegationRepo := repo_postgre.NewDelegationRepository(dbConn.PgDB)
+	outboxRepo := repo_postgre.NewOutboxRepository(dbConn.PgDB)
+	achievementViewRepo := repo_mongo.NewAchievementViewRepository(dbConn.MongoDB)
 
 	authService := service.NewAuthService(authRepo) 
-	achievementService := service.NewAchievementService(achievementMongoRepo, achievementPgRepo) 
+	workflowEngine := config.LoadWorkflowEngine()
+	attachmentStorage := config.LoadStorage()
+	uploadValidator := config.LoadUploadValidator()
+
+	achievementService := service.NewAchievementService(achievementMongoRepo, achievementPgRepo, pointRuleRepo, achievementRevisionRepo, achievementCommentRepo, settingRepo, outboxRepo, achievementViewRepo, attachmentStorage, uploadValidator, workflowEngine) 
 	userService := service.NewAdminManageUsersService(userRepo) 
 	reportService := service.NewReportService(reportMongoRepo, reportPgRepo)
 	studentService := service.NewStudentService(studentRepo, achievementPgRepo) 
 	lecturerService := service.NewLecturerService(lecturerRepo)
+	pointRuleService := service.NewPointRuleService(pointRuleRepo, achievementPgRepo, achievementMongoRepo, outboxRepo, achievementViewRepo)
+	settingService := service.NewSettingService(settingRepo)
+	escalationService := service.NewEscalationService(escalationRepo, achievementPgRepo, settingRepo)
+	delegationService := service.NewDelegationService(delegationRepo, achievementPgRepo)
+	reconcileService := service.NewReconcileService(achievementPgRepo, achievementMongoRepo, outboxRepo)
 	
 	RegisterAuthRoutes(v1, authService) 
-	RegisterAchievementRoutes(v1, achievementService)
+	RegisterAchievementRoutes(v1, achievementService, escalationService)
 	RegisterUserRoutes(v1, userService) 
 	RegisterReportRoutes(v1, reportService)
 	RegisterStudentRoutes(v1, studentService)
 	RegisterLecturerRoutes(v1, lecturerService)
+	RegisterPointRuleRoutes(v1, pointRuleService)
+	RegisterSettingRoutes(v1, settingService)
+	RegisterDelegationRoutes(v1, delegationService)
+	RegisterReconcileRoutes(v1, reconcileService)
 }
\ No newline at end of file
@@ -0,0 +1,20 @@
+package route
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/safrizal-hk/uas-gofiber/app/service"
+	"github.com/safrizal-hk/uas-gofiber/middleware"
+)
+
+func RegisterPointRuleRoutes(v1 fiber.Router, pointRuleService *service.PointRuleService) {
+
+	const managePerm = "user:manage"
+
+	pointRoute := v1.Group("/point-rules", middleware.AuthRequired)
+
+	pointRoute.Get("/", middleware.RBACRequired(managePerm), pointRuleService.ListPointRules)
+	pointRoute.Post("/", middleware.RBACRequired(managePerm), pointRuleService.CreatePointRule)
+	pointRoute.Post("/recalculate", middleware.RBACRequired(managePerm), pointRuleService.RecalculatePoints)
+	pointRoute.Put("/:id", middleware.RBACRequired(managePerm), pointRuleService.UpdatePointRule)
+	pointRoute.Delete("/:id", middleware.RBACRequired(managePerm), pointRuleService.DeletePointRule)
+}
@@ -0,0 +1,14 @@
+package route
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/safrizal-hk/uas-gofiber/app/service"
+	"github.com/safrizal-hk/uas-gofiber/middleware"
+)
+
+func RegisterReconcileRoutes(v1 fiber.Router, reconcileService *service.ReconcileService) {
+
+	const managePerm = "user:manage"
+
+	v1.Post("/reconcile", middleware.AuthRequired, middleware.RBACRequired(managePerm), reconcileService.ReconcileAchievements)
+}
@@ -0,0 +1,18 @@
+package route
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/safrizal-hk/uas-gofiber/app/service"
+	"github.com/safrizal-hk/uas-gofiber/middleware"
+)
+
+func RegisterSettingRoutes(v1 fiber.Router, settingService *service.SettingService) {
+
+	const managePerm = "user:manage"
+
+	settingRoute := v1.Group("/settings", middleware.AuthRequired)
+
+	settingRoute.Get("/", middleware.RBACRequired(managePerm), settingService.ListSettings)
+	settingRoute.Get("/:key", middleware.RBACRequired(managePerm), settingService.GetSetting)
+	settingRoute.Put("/:key", middleware.RBACRequired(managePerm), settingService.UpdateSetting)
+}
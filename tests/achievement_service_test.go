@@ -4,10 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -15,7 +22,12 @@ import (
 
 	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
 	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
 	"github.com/safrizal-hk/uas-gofiber/app/service"
+	"github.com/safrizal-hk/uas-gofiber/app/storage"
+	"github.com/safrizal-hk/uas-gofiber/app/upload"
+	"github.com/safrizal-hk/uas-gofiber/app/workflow"
+	"github.com/safrizal-hk/uas-gofiber/middleware"
 )
 
 // MOCK REPOSITORIES (Dynamic Function Fields)
@@ -27,9 +39,24 @@ type MockAchievementMongoRepo struct {
 	SoftDeleteFunc      func(ctx context.Context, id primitive.ObjectID) error
 	DeleteByIDFunc      func(ctx context.Context, id primitive.ObjectID) error
 	AddAttachmentFunc   func(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) error
+	RemoveAttachmentFunc  func(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) (bool, error)
+	ReplaceAttachmentFunc func(ctx context.Context, id primitive.ObjectID, old model_mongo.Attachment, replacement model_mongo.Attachment) (bool, error)
+	UpdatePointsFunc    func(ctx context.Context, id primitive.ObjectID, points int) (bool, error)
+	FreezePointsFunc    func(ctx context.Context, id primitive.ObjectID, points int) error
+	RestoreFunc         func(ctx context.Context, id primitive.ObjectID) error
+	UnfreezePointsFunc  func(ctx context.Context, id primitive.ObjectID) error
+	GetDeletedDetailsByIDsFunc func(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error)
+	ListSyncStatesFunc  func(ctx context.Context) ([]model_mongo.AchievementSyncState, error)
 	
 	GetAchievementStatisticsFunc func(ctx context.Context, studentIDs []string) ([]interface{}, error) 
 	GetStudentAchievementDetailsFunc func(ctx context.Context, studentIDHex string) ([]interface{}, error)
+
+	// Views: read model achievement_views yang diperbarui dispatcher outbox
+	Views *MockAchievementViewRepo
+	// Storage: tempat file lampiran (default direktori sementara per test)
+	Storage storage.Storage
+	// Uploads: validator upload sekaligus sumber BodyLimit server (default upload.DefaultRules)
+	Uploads *upload.Validator
 }
 
 func (m *MockAchievementMongoRepo) GetDetailsByIDs(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error) {
@@ -56,36 +83,96 @@ func (m *MockAchievementMongoRepo) DeleteByID(ctx context.Context, id primitive.
 	if m.DeleteByIDFunc == nil { return nil }
 	return m.DeleteByIDFunc(ctx, id)
 }
+func (m *MockAchievementMongoRepo) ListSyncStates(ctx context.Context) ([]model_mongo.AchievementSyncState, error) {
+	if m.ListSyncStatesFunc == nil { return nil, nil }
+	return m.ListSyncStatesFunc(ctx)
+}
 func (m *MockAchievementMongoRepo) AddAttachment(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) error {
 	if m.AddAttachmentFunc == nil { return nil }
 	return m.AddAttachmentFunc(ctx, id, attachment)
 }
+func (m *MockAchievementMongoRepo) RemoveAttachment(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) (bool, error) {
+	if m.RemoveAttachmentFunc == nil { return true, nil }
+	return m.RemoveAttachmentFunc(ctx, id, attachment)
+}
+func (m *MockAchievementMongoRepo) ReplaceAttachment(ctx context.Context, id primitive.ObjectID, old model_mongo.Attachment, replacement model_mongo.Attachment) (bool, error) {
+	if m.ReplaceAttachmentFunc == nil { return true, nil }
+	return m.ReplaceAttachmentFunc(ctx, id, old, replacement)
+}
+func (m *MockAchievementMongoRepo) UpdatePoints(ctx context.Context, id primitive.ObjectID, points int) (bool, error) {
+	if m.UpdatePointsFunc == nil { return true, nil }
+	return m.UpdatePointsFunc(ctx, id, points)
+}
+func (m *MockAchievementMongoRepo) FreezePoints(ctx context.Context, id primitive.ObjectID, points int) error {
+	if m.FreezePointsFunc == nil { return nil }
+	return m.FreezePointsFunc(ctx, id, points)
+}
+func (m *MockAchievementMongoRepo) UnfreezePoints(ctx context.Context, id primitive.ObjectID) error {
+	if m.UnfreezePointsFunc == nil { return nil }
+	return m.UnfreezePointsFunc(ctx, id)
+}
+func (m *MockAchievementMongoRepo) Restore(ctx context.Context, id primitive.ObjectID) error {
+	if m.RestoreFunc == nil { return nil }
+	return m.RestoreFunc(ctx, id)
+}
+func (m *MockAchievementMongoRepo) GetDeletedDetailsByIDs(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error) {
+	if m.GetDeletedDetailsByIDsFunc == nil { return nil, nil }
+	return m.GetDeletedDetailsByIDsFunc(ctx, ids)
+}
 func (m *MockAchievementMongoRepo) GetAchievementStatistics(ctx context.Context, studentIDs []string) ([]interface{}, error) { return nil, nil } // Sesuaikan return type dg interface Anda (misal []bson.M)
 func (m *MockAchievementMongoRepo) GetStudentAchievementDetails(ctx context.Context, studentIDHex string) ([]interface{}, error) { return nil, nil }
 
 type MockAchievementPGRepo struct {
 	GetAllAchievementReferencesFunc func() ([]model_postgre.AchievementReference, error)
-	GetMyAchievementsFunc           func(studentID string) ([]model_postgre.AchievementReference, error)
+	GetAllReferencesWithDeletedFunc func() ([]model_postgre.AchievementReference, error)
+	TrashReferenceFunc              func(id, actorID, note string) (*model_postgre.AchievementReference, error)
+	GetReferencesByMongoIDFunc      func(mongoID string) ([]model_postgre.AchievementReference, error)
 	GetAchievementsByStudentIDsFunc func(ids []string) ([]model_postgre.AchievementReference, error)
 	GetReferenceByIDFunc            func(id string) (*model_postgre.AchievementReference, error)
-	CreateReferenceFunc             func(ref *model_postgre.AchievementReference) (*model_postgre.AchievementReference, error)
-	SoftDeleteReferenceFunc         func(id, studentID string) (*model_postgre.AchievementReference, error)
-	UpdateStatusToSubmittedFunc     func(id string) (*model_postgre.AchievementReference, error)
-	VerifyAchievementFunc           func(id, lecturerID string) (*model_postgre.AchievementReference, error)
-	RejectAchievementFunc           func(id, lecturerID, note string) (*model_postgre.AchievementReference, error)
+	GetReferencesByIDsFunc          func(ids []string) ([]model_postgre.AchievementReference, error)
+	CreateReferenceFunc             func(ref *model_postgre.AchievementReference, actorID string) (*model_postgre.AchievementReference, error)
+	SoftDeleteReferenceFunc         func(id, studentID, actorID string) (*model_postgre.AchievementReference, error)
+	UpdateStatusToSubmittedFunc     func(id, actorID, workflowCode, firstStage string) (*model_postgre.AchievementReference, error)
+	WithdrawSubmissionFunc          func(id, studentID, actorID string) (*model_postgre.AchievementReference, error)
+	AdvanceStageFunc                func(id, actorID, onBehalfOf, fromStage, toStage, note string) (*model_postgre.AchievementReference, error)
+	VerifyAchievementFunc           func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error)
+	RejectAchievementFunc           func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error)
+	RequestRevisionFunc             func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error)
+	GetStatusHistoryFunc            func(id string) ([]model_postgre.AchievementStatusHistory, error)
+	CreateTeamReferencesFunc        func(owner *model_postgre.AchievementReference, members []model_postgre.AchievementReference, actorID string) (*model_postgre.AchievementReference, error)
+	GetTeamReferencesFunc           func(mongoID string) ([]model_postgre.AchievementReference, error)
+	ConfirmParticipationFunc        func(id, studentID string) (*model_postgre.AchievementReference, error)
+	FindExistingStudentIDsFunc      func(ids []string) ([]string, error)
+	RestoreReferenceFunc            func(id, studentID, actorID string) (*model_postgre.AchievementReference, error)
+	GetDeletedReferencesFunc        func(studentID string) ([]model_postgre.AchievementReference, error)
+	GetPurgeableReferencesFunc      func(deletedBefore time.Time) ([]model_postgre.AchievementReference, error)
+	PurgeReferencesFunc             func(mongoID string) (int64, error)
+	OverrideStatusFunc              func(id string, to model_postgre.AchievementStatus, actorID, reason, workflowCode, firstStage string, allowFromVerified bool) (*model_postgre.AchievementReference, error)
 	
 	FindStudentIdByUserIDFunc       func(userID string) (string, error)
 	FindLecturerIdByUserIDFunc      func(userID string) (string, error)
 	GetAdviseeStudentIDsFunc        func(lecturerID string) ([]string, error)
+	GetDelegatedAdviseesFunc        func(lecturerID string) ([]model_postgre.DelegatedAdvisee, error)
+
+	// Outbox menerima event yang "dicatat dalam transaksi" oleh method create/soft delete/restore
+	Outbox *MockOutboxRepo
 }
 
 func (m *MockAchievementPGRepo) GetAllAchievementReferences() ([]model_postgre.AchievementReference, error) {
 	if m.GetAllAchievementReferencesFunc == nil { return nil, nil }
 	return m.GetAllAchievementReferencesFunc()
 }
-func (m *MockAchievementPGRepo) GetMyAchievements(studentID string) ([]model_postgre.AchievementReference, error) {
-	if m.GetMyAchievementsFunc == nil { return nil, nil }
-	return m.GetMyAchievementsFunc(studentID)
+func (m *MockAchievementPGRepo) GetAllReferencesWithDeleted() ([]model_postgre.AchievementReference, error) {
+	if m.GetAllReferencesWithDeletedFunc == nil { return nil, nil }
+	return m.GetAllReferencesWithDeletedFunc()
+}
+func (m *MockAchievementPGRepo) TrashReference(id, actorID, note string) (*model_postgre.AchievementReference, error) {
+	if m.TrashReferenceFunc == nil { return nil, nil }
+	return m.TrashReferenceFunc(id, actorID, note)
+}
+func (m *MockAchievementPGRepo) GetReferencesByMongoID(mongoID string) ([]model_postgre.AchievementReference, error) {
+	if m.GetReferencesByMongoIDFunc == nil { return nil, nil }
+	return m.GetReferencesByMongoIDFunc(mongoID)
 }
 func (m *MockAchievementPGRepo) GetAchievementsByStudentIDs(ids []string) ([]model_postgre.AchievementReference, error) {
 	if m.GetAchievementsByStudentIDsFunc == nil { return nil, nil }
@@ -95,25 +182,93 @@ func (m *MockAchievementPGRepo) GetReferenceByID(id string) (*model_postgre.Achi
 	if m.GetReferenceByIDFunc == nil { return nil, nil }
 	return m.GetReferenceByIDFunc(id)
 }
-func (m *MockAchievementPGRepo) CreateReference(ref *model_postgre.AchievementReference) (*model_postgre.AchievementReference, error) {
+func (m *MockAchievementPGRepo) GetReferencesByIDs(ids []string) ([]model_postgre.AchievementReference, error) {
+	if m.GetReferencesByIDsFunc == nil { return nil, nil }
+	return m.GetReferencesByIDsFunc(ids)
+}
+func (m *MockAchievementPGRepo) CreateReference(ref *model_postgre.AchievementReference, actorID string, event *model_postgre.OutboxEvent) (*model_postgre.AchievementReference, error) {
 	if m.CreateReferenceFunc == nil { return nil, nil }
-	return m.CreateReferenceFunc(ref)
+	created, err := m.CreateReferenceFunc(ref, actorID)
+	if err == nil { m.Outbox.record(event) }
+	return created, err
 }
-func (m *MockAchievementPGRepo) SoftDeleteReference(id, studentID string) (*model_postgre.AchievementReference, error) {
+func (m *MockAchievementPGRepo) SoftDeleteReference(id, studentID, actorID string) (*model_postgre.AchievementReference, error) {
 	if m.SoftDeleteReferenceFunc == nil { return nil, nil }
-	return m.SoftDeleteReferenceFunc(id, studentID)
+	ref, err := m.SoftDeleteReferenceFunc(id, studentID, actorID)
+	if err == nil && ref != nil {
+		m.Outbox.record(&model_postgre.OutboxEvent{AggregateID: ref.MongoAchievementID, Operation: model_postgre.OutboxSoftDelete})
+	}
+	return ref, err
 }
-func (m *MockAchievementPGRepo) UpdateStatusToSubmitted(id string) (*model_postgre.AchievementReference, error) {
+func (m *MockAchievementPGRepo) UpdateStatusToSubmitted(id, actorID, workflowCode, firstStage string) (*model_postgre.AchievementReference, error) {
 	if m.UpdateStatusToSubmittedFunc == nil { return nil, nil }
-	return m.UpdateStatusToSubmittedFunc(id)
+	return m.UpdateStatusToSubmittedFunc(id, actorID, workflowCode, firstStage)
 }
-func (m *MockAchievementPGRepo) VerifyAchievement(id, lecturerID string) (*model_postgre.AchievementReference, error) {
+func (m *MockAchievementPGRepo) AdvanceStage(id, actorID, onBehalfOf, fromStage, toStage, note string) (*model_postgre.AchievementReference, error) {
+	if m.AdvanceStageFunc == nil { return nil, nil }
+	return m.AdvanceStageFunc(id, actorID, onBehalfOf, fromStage, toStage, note)
+}
+func (m *MockAchievementPGRepo) VerifyAchievement(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
 	if m.VerifyAchievementFunc == nil { return nil, nil }
-	return m.VerifyAchievementFunc(id, lecturerID)
+	return m.VerifyAchievementFunc(id, lecturerID, onBehalfOf, stage, note)
 }
-func (m *MockAchievementPGRepo) RejectAchievement(id, lecturerID, note string) (*model_postgre.AchievementReference, error) {
+func (m *MockAchievementPGRepo) RejectAchievement(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
 	if m.RejectAchievementFunc == nil { return nil, nil }
-	return m.RejectAchievementFunc(id, lecturerID, note)
+	return m.RejectAchievementFunc(id, lecturerID, onBehalfOf, stage, note)
+}
+func (m *MockAchievementPGRepo) RequestRevision(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
+	if m.RequestRevisionFunc == nil { return nil, nil }
+	return m.RequestRevisionFunc(id, lecturerID, onBehalfOf, stage, note)
+}
+func (m *MockAchievementPGRepo) GetStatusHistory(id string) ([]model_postgre.AchievementStatusHistory, error) {
+	if m.GetStatusHistoryFunc == nil { return nil, nil }
+	return m.GetStatusHistoryFunc(id)
+}
+func (m *MockAchievementPGRepo) CreateTeamReferences(owner *model_postgre.AchievementReference, members []model_postgre.AchievementReference, actorID string, event *model_postgre.OutboxEvent) (*model_postgre.AchievementReference, error) {
+	if m.CreateTeamReferencesFunc == nil { return nil, nil }
+	created, err := m.CreateTeamReferencesFunc(owner, members, actorID)
+	if err == nil { m.Outbox.record(event) }
+	return created, err
+}
+func (m *MockAchievementPGRepo) GetTeamReferences(mongoID string) ([]model_postgre.AchievementReference, error) {
+	if m.GetTeamReferencesFunc == nil { return nil, nil }
+	return m.GetTeamReferencesFunc(mongoID)
+}
+func (m *MockAchievementPGRepo) ConfirmParticipation(id, studentID string) (*model_postgre.AchievementReference, error) {
+	if m.ConfirmParticipationFunc == nil { return nil, nil }
+	return m.ConfirmParticipationFunc(id, studentID)
+}
+func (m *MockAchievementPGRepo) FindExistingStudentIDs(ids []string) ([]string, error) {
+	if m.FindExistingStudentIDsFunc == nil { return ids, nil }
+	return m.FindExistingStudentIDsFunc(ids)
+}
+func (m *MockAchievementPGRepo) WithdrawSubmission(id, studentID, actorID string) (*model_postgre.AchievementReference, error) {
+	if m.WithdrawSubmissionFunc == nil { return nil, nil }
+	return m.WithdrawSubmissionFunc(id, studentID, actorID)
+}
+func (m *MockAchievementPGRepo) OverrideStatus(id string, to model_postgre.AchievementStatus, actorID, reason, workflowCode, firstStage string, allowFromVerified bool) (*model_postgre.AchievementReference, error) {
+	if m.OverrideStatusFunc == nil { return nil, nil }
+	return m.OverrideStatusFunc(id, to, actorID, reason, workflowCode, firstStage, allowFromVerified)
+}
+func (m *MockAchievementPGRepo) RestoreReference(id, studentID, actorID string) (*model_postgre.AchievementReference, error) {
+	if m.RestoreReferenceFunc == nil { return nil, nil }
+	ref, err := m.RestoreReferenceFunc(id, studentID, actorID)
+	if err == nil && ref != nil {
+		m.Outbox.record(&model_postgre.OutboxEvent{AggregateID: ref.MongoAchievementID, Operation: model_postgre.OutboxRestore})
+	}
+	return ref, err
+}
+func (m *MockAchievementPGRepo) GetDeletedReferences(studentID string) ([]model_postgre.AchievementReference, error) {
+	if m.GetDeletedReferencesFunc == nil { return nil, nil }
+	return m.GetDeletedReferencesFunc(studentID)
+}
+func (m *MockAchievementPGRepo) GetPurgeableReferences(deletedBefore time.Time) ([]model_postgre.AchievementReference, error) {
+	if m.GetPurgeableReferencesFunc == nil { return nil, nil }
+	return m.GetPurgeableReferencesFunc(deletedBefore)
+}
+func (m *MockAchievementPGRepo) PurgeReferences(mongoID string) (int64, error) {
+	if m.PurgeReferencesFunc == nil { return 0, nil }
+	return m.PurgeReferencesFunc(mongoID)
 }
 func (m *MockAchievementPGRepo) FindStudentIdByUserID(userID string) (string, error) {
 	if m.FindStudentIdByUserIDFunc == nil { return "", nil }
@@ -127,60 +282,203 @@ func (m *MockAchievementPGRepo) GetAdviseeStudentIDs(lecturerID string) ([]strin
 	if m.GetAdviseeStudentIDsFunc == nil { return nil, nil }
 	return m.GetAdviseeStudentIDsFunc(lecturerID)
 }
+func (m *MockAchievementPGRepo) GetDelegatedAdvisees(lecturerID string) ([]model_postgre.DelegatedAdvisee, error) {
+	if m.GetDelegatedAdviseesFunc == nil { return nil, nil }
+	return m.GetDelegatedAdviseesFunc(lecturerID)
+}
 func (m *MockAchievementPGRepo) GetAchievementsByStudentIDsAndStatus(ids []string, status string) ([]model_postgre.AchievementReference, error) { return nil, nil }
 
+// MockRevisionRepo: Penyimpanan revisi in-memory, nomor versi mengikuti urutan insert per prestasi
+type MockRevisionRepo struct {
+	Revisions []model_mongo.AchievementRevision
+}
+
+func (m *MockRevisionRepo) Create(ctx context.Context, rev *model_mongo.AchievementRevision) (*model_mongo.AchievementRevision, error) {
+	latest, _ := m.LatestVersion(ctx, rev.AchievementID)
+	rev.Version = latest + 1
+	rev.CreatedAt = time.Now()
+	m.Revisions = append(m.Revisions, *rev)
+	return rev, nil
+}
+func (m *MockRevisionRepo) ListByAchievement(ctx context.Context, id primitive.ObjectID) ([]model_mongo.AchievementRevision, error) {
+	list := []model_mongo.AchievementRevision{}
+	for _, rev := range m.Revisions {
+		if rev.AchievementID == id { list = append(list, rev) }
+	}
+	return list, nil
+}
+func (m *MockRevisionRepo) GetByVersion(ctx context.Context, id primitive.ObjectID, version int) (*model_mongo.AchievementRevision, error) {
+	for i := range m.Revisions {
+		if m.Revisions[i].AchievementID == id && m.Revisions[i].Version == version { return &m.Revisions[i], nil }
+	}
+	return nil, errors.New("not found")
+}
+func (m *MockRevisionRepo) LatestVersion(ctx context.Context, id primitive.ObjectID) (int, error) {
+	latest := 0
+	for _, rev := range m.Revisions {
+		if rev.AchievementID == id && rev.Version > latest { latest = rev.Version }
+	}
+	return latest, nil
+}
+
+func (m *MockRevisionRepo) DeleteByAchievement(ctx context.Context, id primitive.ObjectID) error {
+	kept := []model_mongo.AchievementRevision{}
+	for _, rev := range m.Revisions {
+		if rev.AchievementID != id { kept = append(kept, rev) }
+	}
+	m.Revisions = kept
+	return nil
+}
+
+// MockCommentRepo: Penyimpanan komentar in-memory, urut sesuai insert
+type MockCommentRepo struct {
+	Comments  []model_mongo.AchievementComment
+	CreateErr error
+}
+
+func (m *MockCommentRepo) Create(ctx context.Context, comment *model_mongo.AchievementComment) (*model_mongo.AchievementComment, error) {
+	if m.CreateErr != nil { return nil, m.CreateErr }
+	if comment.ID.IsZero() { comment.ID = primitive.NewObjectID() }
+	comment.CreatedAt = time.Now()
+	m.Comments = append(m.Comments, *comment)
+	return comment, nil
+}
+func (m *MockCommentRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementComment, error) {
+	for i := range m.Comments {
+		if m.Comments[i].ID == id { return &m.Comments[i], nil }
+	}
+	return nil, errors.New("not found")
+}
+func (m *MockCommentRepo) ListByAchievement(ctx context.Context, id primitive.ObjectID) ([]model_mongo.AchievementComment, error) {
+	list := []model_mongo.AchievementComment{}
+	for _, comment := range m.Comments {
+		if comment.AchievementID == id { list = append(list, comment) }
+	}
+	return list, nil
+}
+func (m *MockCommentRepo) DeleteByAchievement(ctx context.Context, id primitive.ObjectID) error {
+	kept := []model_mongo.AchievementComment{}
+	for _, comment := range m.Comments {
+		if comment.AchievementID != id { kept = append(kept, comment) }
+	}
+	m.Comments = kept
+	return nil
+}
 
 // 2. SETUP HELPER
-func setupAchievementServiceTestApp(mockMongo *MockAchievementMongoRepo, mockPg *MockAchievementPGRepo) *fiber.App {
-	app := fiber.New()
-	svc := service.NewAchievementService(mockMongo, mockPg)
+func setupAchievementServiceTestApp(t *testing.T, mockMongo *MockAchievementMongoRepo, mockPg *MockAchievementPGRepo) *fiber.App {
+	engine, _ := workflow.NewEngine(workflow.DefaultDefinitions())
+	return setupAchievementServiceTestAppWithWorkflow(t, mockMongo, mockPg, engine)
+}
+
+func setupAchievementServiceTestAppWithWorkflow(t *testing.T, mockMongo *MockAchievementMongoRepo, mockPg *MockAchievementPGRepo, engine *workflow.Engine) *fiber.App {
+	return setupAchievementServiceTestAppWithRevisions(t, mockMongo, mockPg, &MockRevisionRepo{}, engine)
+}
+
+func setupAchievementServiceTestAppWithRevisions(t *testing.T, mockMongo *MockAchievementMongoRepo, mockPg *MockAchievementPGRepo, revisions *MockRevisionRepo, engine *workflow.Engine) *fiber.App {
+	return newAchievementServiceTestApp(t, mockMongo, mockPg, revisions, &MockCommentRepo{}, engine)
+}
+
+func setupAchievementServiceTestAppWithComments(t *testing.T, mockMongo *MockAchievementMongoRepo, mockPg *MockAchievementPGRepo, comments *MockCommentRepo) *fiber.App {
+	engine, _ := workflow.NewEngine(workflow.DefaultDefinitions())
+	return newAchievementServiceTestApp(t, mockMongo, mockPg, &MockRevisionRepo{}, comments, engine)
+}
+
+func newAchievementServiceTestApp(t *testing.T, mockMongo *MockAchievementMongoRepo, mockPg *MockAchievementPGRepo, revisions *MockRevisionRepo, comments *MockCommentRepo, engine *workflow.Engine) *fiber.App {
+	if mockPg.Outbox == nil { mockPg.Outbox = &MockOutboxRepo{} }
+	if mockMongo.Views == nil { mockMongo.Views = &MockAchievementViewRepo{} }
+	if mockMongo.Storage == nil { mockMongo.Storage = storage.NewLocal(t.TempDir(), "/uploads") }
+	if mockMongo.Uploads == nil { mockMongo.Uploads = upload.NewValidator(upload.DefaultRules()) }
+	// BodyLimit & ErrorHandler seperti main.go agar batas ukuran lampiran yang diuji, bukan batas bawaan Fiber
+	app := fiber.New(fiber.Config{BodyLimit: mockMongo.Uploads.BodyLimit(model_mongo.MaxCommentAttachments), ErrorHandler: middleware.ErrorHandler(mockMongo.Uploads), DisableStartupMessage: true})
+	svc := service.NewAchievementService(mockMongo, mockPg, &MockPointRuleRepo{Rules: defaultTestPointRules()}, revisions, comments, &MockSettingRepo{}, mockPg.Outbox, mockMongo.Views, mockMongo.Storage, mockMongo.Uploads, engine)
 
 	app.Use(func(c *fiber.Ctx) error {
 		role := c.Get("X-Test-Role")
 		id := c.Get("X-Test-ID")
 		
 		if role != "" {
-			c.Locals("userProfile", model_postgre.UserProfile{
+			profile := model_postgre.UserProfile{
 				ID:   id,
 				Role: role,
-			})
+			}
+			if perms := c.Get("X-Test-Permissions"); perms != "" {
+				profile.Permissions = strings.Split(perms, ",")
+			}
+			c.Locals("userProfile", profile)
 		}
 		return c.Next()
 	})
 
 	app.Get("/achievements", svc.ListAllAchievements)
+	app.Get("/achievements/trash", svc.ListTrash)
+	app.Get("/achievements/search", svc.SearchAchievements)
+	app.Post("/achievements/bulk/verify", svc.BulkVerifyPrestasi)
+	app.Post("/achievements/bulk/reject", svc.BulkRejectPrestasi)
 	app.Post("/achievements", svc.SubmitPrestasi)
 	app.Get("/achievements/:id", svc.GetAchievementDetail)
 	app.Put("/achievements/:id", svc.UpdatePrestasi)
 	app.Delete("/achievements/:id", svc.DeletePrestasi)
+	app.Post("/achievements/:id/restore", svc.RestorePrestasi)
 	app.Post("/achievements/:id/submit", svc.SubmitForVerification)
+	app.Post("/achievements/:id/withdraw", svc.WithdrawPrestasi)
+	app.Post("/achievements/:id/confirm-participation", svc.ConfirmParticipation)
 	app.Post("/achievements/:id/verify", svc.VerifyPrestasi)
 	app.Post("/achievements/:id/reject", svc.RejectPrestasi)
+	app.Post("/achievements/:id/request-revision", svc.RequestRevisionPrestasi)
+	app.Post("/achievements/:id/override-status", svc.OverrideStatus)
+	app.Get("/achievements/:id/history", svc.GetHistory)
+	app.Get("/achievements/:id/revisions", svc.ListRevisions)
+	app.Get("/achievements/:id/revisions/diff", svc.DiffRevisions)
+	app.Get("/achievements/:id/comments", svc.ListComments)
+	app.Post("/achievements/:id/comments", svc.AddComment)
 	app.Post("/achievements/:id/attachments", svc.AddAttachment)
+	app.Get("/achievements/:id/comments/:commentId/attachments/:attachmentId", svc.DownloadCommentAttachment)
+	app.Get("/achievements/:id/attachments/:attachmentId", svc.DownloadAttachment)
+	app.Put("/achievements/:id/attachments/:attachmentId", svc.ReplaceAttachment)
+	app.Delete("/achievements/:id/attachments/:attachmentId", svc.DeleteAttachment)
+	app.Get("/achievement-types", svc.ListAchievementTypes)
+	app.Get("/achievement-types/:type", svc.GetAchievementType)
 
 	return app
 }
 
 
+// competitionEvidence: Lampiran yang memenuhi syarat bukti tipe competition
+func competitionEvidence() []model_mongo.Attachment {
+	return []model_mongo.Attachment{
+		{FileName: "sertifikat.pdf", FileType: "application/pdf", Category: model_mongo.AttachmentCertificate, StorageKey: "att-1-sertifikat.pdf"},
+		{FileName: "foto.jpg", FileType: "image/jpeg", Category: model_mongo.AttachmentPhoto, StorageKey: "att-2-foto.jpg"},
+	}
+}
+
+func validCompetitionInput(title string) model_mongo.AchievementInput {
+	return model_mongo.AchievementInput{
+		AchievementType: "competition",
+		Title:           title,
+		Details: map[string]interface{}{
+			"competitionName":  "Gemastik",
+			"competitionLevel": "national",
+			"rank":             "first",
+			"eventDate":        "2024-10-12",
+		},
+	}
+}
+
 // UNIT TESTS
 func TestListAllAchievements_Mahasiswa_Success(t *testing.T) {
 	mockPg := &MockAchievementPGRepo{
 		FindStudentIdByUserIDFunc: func(userID string) (string, error) {
 			return "stu-123", nil
 		},
-		GetMyAchievementsFunc: func(studentID string) ([]model_postgre.AchievementReference, error) {
-			return []model_postgre.AchievementReference{
-				{ID: "ref-1", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"},
-			}, nil
-		},
 	}
-	mockMongo := &MockAchievementMongoRepo{
-		GetDetailsByIDsFunc: func(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error) {
-			oid, _ := primitive.ObjectIDFromHex("64b0f1a2e4b0a1a2b3c4d5e6")
-			return []model_mongo.AchievementMongo{{ID: oid, Title: "Juara 1"}}, nil
+	mockMongo := &MockAchievementMongoRepo{Views: &MockAchievementViewRepo{
+		ListFunc: func(filter model_mongo.AchievementViewFilter) ([]model_mongo.AchievementView, int, error) {
+			if !filter.IncludeDrafts || len(filter.StudentIDs) != 1 || filter.StudentIDs[0] != "stu-123" { return nil, 0, nil }
+			return []model_mongo.AchievementView{{ID: "ref-1", Title: "Juara 1"}}, 1, nil
 		},
-	}
-	app := setupAchievementServiceTestApp(mockMongo, mockPg)
+	}}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
 
 	req := httptest.NewRequest("GET", "/achievements", nil)
 	req.Header.Set("X-Test-Role", "Mahasiswa")
@@ -188,33 +486,184 @@ func TestListAllAchievements_Mahasiswa_Success(t *testing.T) {
 	resp, _ := app.Test(req)
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var result struct {
+		Total int                      `json:"total"`
+		Data  []map[string]interface{} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Equal(t, 1, result.Total)
+	if assert.Len(t, result.Data, 1) {
+		assert.Equal(t, "Juara 1", result.Data[0]["title"])
+	}
 }
 
 func TestListAllAchievements_Admin_Success(t *testing.T) {
-	mockPg := &MockAchievementPGRepo{
-		GetAllAchievementReferencesFunc: func() ([]model_postgre.AchievementReference, error) {
-			return []model_postgre.AchievementReference{{ID: "ref-1", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}}, nil
+	mockMongo := &MockAchievementMongoRepo{Views: &MockAchievementViewRepo{
+		ListFunc: func(filter model_mongo.AchievementViewFilter) ([]model_mongo.AchievementView, int, error) {
+			if !filter.All { return nil, 0, nil }
+			return []model_mongo.AchievementView{{ID: "ref-1", Title: "Juara 1"}}, 1, nil
 		},
-	}
-	mockMongo := &MockAchievementMongoRepo{
-		GetDetailsByIDsFunc: func(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error) {
-			oid, _ := primitive.ObjectIDFromHex("64b0f1a2e4b0a1a2b3c4d5e6")
-			return []model_mongo.AchievementMongo{{ID: oid, Title: "Juara 1"}}, nil
+	}}
+	app := setupAchievementServiceTestApp(t, mockMongo, &MockAchievementPGRepo{})
+
+	req := httptest.NewRequest("GET", "/achievements", nil)
+	req.Header.Set("X-Test-Role", "Admin")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestListAllAchievements_FiltersPushedDown(t *testing.T) {
+	var gotFilter model_mongo.AchievementViewFilter
+	mockMongo := &MockAchievementMongoRepo{Views: &MockAchievementViewRepo{
+		ListFunc: func(filter model_mongo.AchievementViewFilter) ([]model_mongo.AchievementView, int, error) {
+			gotFilter = filter
+			views := []model_mongo.AchievementView{}
+			for i := 0; i <= filter.Limit; i++ {
+				views = append(views, model_mongo.AchievementView{ID: fmt.Sprintf("ref-%d", i), Title: "Juara 1"})
+			}
+			return views, 7, nil
 		},
+	}}
+	app := setupAchievementServiceTestApp(t, mockMongo, &MockAchievementPGRepo{})
+
+	req := httptest.NewRequest("GET", "/achievements?page=2&limit=3&status=submitted,verified&achievementType=competition&tags=ai,web&minPoints=10&dateFrom=2024-01-01&dateTo=2024-01-31&sort=submitted_at&order=asc", nil)
+	req.Header.Set("X-Test-Role", "Admin")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Semua filter (status maupun konten) dijalankan dalam satu query read model
+	assert.Equal(t, "competition", gotFilter.AchievementType)
+	assert.Equal(t, []string{"ai", "web"}, gotFilter.Tags)
+	assert.Equal(t, 10, *gotFilter.MinPoints)
+	assert.Nil(t, gotFilter.MaxPoints)
+	assert.Equal(t, []string{"submitted", "verified"}, gotFilter.Statuses)
+	assert.Equal(t, 3, gotFilter.Limit)
+	assert.Equal(t, 3, gotFilter.Offset)
+	assert.Equal(t, "submitted_at", gotFilter.Sort)
+	assert.False(t, gotFilter.Desc)
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), *gotFilter.DateTo)
+
+	var result struct {
+		Total      int                    `json:"total"`
+		Data       []map[string]interface{} `json:"data"`
+		Pagination map[string]interface{} `json:"pagination"`
 	}
-	app := setupAchievementServiceTestApp(mockMongo, mockPg)
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Equal(t, 7, result.Total)
+	assert.Len(t, result.Data, 3)
+	assert.Equal(t, true, result.Pagination["has_next"])
+	assert.Equal(t, float64(3), result.Pagination["next_page"])
+	assert.Equal(t, float64(3), result.Pagination["total_pages"])
+	assert.Nil(t, result.Pagination["next_cursor"])
+}
 
-	req := httptest.NewRequest("GET", "/achievements", nil)
+func TestListAllAchievements_CursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	lastID := "3f2b8c1e-8a4d-4e4b-9a51-0c7d2f6e1a90"
+	var calls []model_mongo.AchievementViewFilter
+	mockMongo := &MockAchievementMongoRepo{Views: &MockAchievementViewRepo{
+		ListFunc: func(filter model_mongo.AchievementViewFilter) ([]model_mongo.AchievementView, int, error) {
+			calls = append(calls, filter)
+			return []model_mongo.AchievementView{
+				{ID: "a1e5c2d4-0000-4000-8000-000000000001", CreatedAt: created.Add(time.Hour)},
+				{ID: lastID, CreatedAt: created},
+				{ID: "a1e5c2d4-0000-4000-8000-000000000003", CreatedAt: created.Add(-time.Hour)},
+			}, 5, nil
+		},
+	}}
+	app := setupAchievementServiceTestApp(t, mockMongo, &MockAchievementPGRepo{})
+
+	req := httptest.NewRequest("GET", "/achievements?limit=2", nil)
 	req.Header.Set("X-Test-Role", "Admin")
 	resp, _ := app.Test(req)
+	var result struct {
+		Pagination map[string]interface{} `json:"pagination"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	cursor, _ := result.Pagination["next_cursor"].(string)
+	if !assert.NotEmpty(t, cursor) { return }
+
+	req = httptest.NewRequest("GET", "/achievements?limit=2&cursor="+cursor, nil)
+	req.Header.Set("X-Test-Role", "Admin")
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	if assert.Len(t, calls, 2) {
+		assert.True(t, created.Equal(*calls[1].CursorValue))
+		assert.Equal(t, lastID, calls[1].CursorID)
+		assert.Equal(t, 0, calls[1].Offset)
+	}
+}
+
+func TestListAllAchievements_InvalidQuery(t *testing.T) {
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, &MockAchievementPGRepo{})
+	for _, query := range []string{"limit=500", "page=0", "sort=title", "order=up", "status=unknown", "dateFrom=01-01-2024",
+		"minPoints=10&maxPoints=5", "sort=submitted_at&cursor=abc", "cursor=bm90LWEtY3Vyc29y"} {
+		req := httptest.NewRequest("GET", "/achievements?"+query, nil)
+		req.Header.Set("X-Test-Role", "Admin")
+		resp, _ := app.Test(req)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+}
+
+func TestSearchAchievements_ScopedAndRankedWithStatus(t *testing.T) {
+	var gotQuery string
+	var gotLimit int
+	var gotFilter model_mongo.AchievementViewFilter
+	mockPg := &MockAchievementPGRepo{
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-1", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-123"}, nil },
+	}
+	mockMongo := &MockAchievementMongoRepo{Views: &MockAchievementViewRepo{
+		SearchFunc: func(query string, filter model_mongo.AchievementViewFilter, limit int) ([]model_mongo.AchievementViewHit, error) {
+			gotQuery, gotFilter, gotLimit = query, filter, limit
+			return []model_mongo.AchievementViewHit{
+				{AchievementView: model_mongo.AchievementView{ID: "ref-best", StudentID: "stu-123", Status: "submitted", Title: "Juara 1 Gemastik"}, Score: 11.5},
+				{AchievementView: model_mongo.AchievementView{ID: "ref-other", StudentID: "stu-123", Status: "verified", Title: "Finalis", Tags: []string{"gemastik"}}, Score: 5},
+			}, nil
+		},
+	}}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
 
+	req := httptest.NewRequest("GET", "/achievements/search?q=Gemastik", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-dosen")
+	resp, _ := app.Test(req)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, "Gemastik", gotQuery)
+	assert.Equal(t, model_mongo.DefaultSearchLimit, gotLimit)
+	assert.Equal(t, []string{"stu-123"}, gotFilter.StudentIDs)
+	// Draft tidak terlihat oleh Dosen Wali
+	assert.False(t, gotFilter.IncludeDrafts)
+	assert.False(t, gotFilter.All)
+
+	var result struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if assert.Len(t, result.Data, 2) {
+		assert.Equal(t, "ref-best", result.Data[0]["id"])
+		assert.Equal(t, "submitted", result.Data[0]["status"])
+		assert.Equal(t, 11.5, result.Data[0]["score"])
+		assert.Equal(t, "ref-other", result.Data[1]["id"])
+	}
+}
+
+func TestSearchAchievements_RequiresQuery(t *testing.T) {
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, &MockAchievementPGRepo{})
+	for _, query := range []string{"", "q=%20%20", "q=a&limit=0", "q=" + strings.Repeat("a", model_mongo.MaxSearchQueryLength+1)} {
+		req := httptest.NewRequest("GET", "/achievements/search?"+query, nil)
+		req.Header.Set("X-Test-Role", "Admin")
+		resp, _ := app.Test(req)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
 }
 
 func TestSubmitPrestasi_Success(t *testing.T) {
 	mockPg := &MockAchievementPGRepo{
 		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
-		CreateReferenceFunc: func(ref *model_postgre.AchievementReference) (*model_postgre.AchievementReference, error) {
+		CreateReferenceFunc: func(ref *model_postgre.AchievementReference, actorID string) (*model_postgre.AchievementReference, error) {
 			ref.ID = "ref-new"
 			return ref, nil
 		},
@@ -225,9 +674,9 @@ func TestSubmitPrestasi_Success(t *testing.T) {
 			return achievement, nil
 		},
 	}
-	app := setupAchievementServiceTestApp(mockMongo, mockPg)
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
 
-	payload := model_mongo.AchievementInput{Title: "Juara 1 Lomba Coding"}
+	payload := validCompetitionInput("Juara 1 Lomba Coding")
 	body, _ := json.Marshal(payload)
 
 	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(body))
@@ -239,107 +688,2058 @@ func TestSubmitPrestasi_Success(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 }
 
-func TestSubmitPrestasi_Forbidden(t *testing.T) {
-	mockPg := &MockAchievementPGRepo{}
-	mockMongo := &MockAchievementMongoRepo{}
-	app := setupAchievementServiceTestApp(mockMongo, mockPg)
+// Lampiran hanya boleh berasal dari endpoint upload; daftar lampiran pada JSON pembuatan diabaikan
+func TestSubmitPrestasi_IgnoresClientAttachments(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		CreateReferenceFunc: func(ref *model_postgre.AchievementReference, actorID string) (*model_postgre.AchievementReference, error) {
+			ref.ID = "ref-new"
+			return ref, nil
+		},
+	}
+	var created *model_mongo.AchievementMongo
+	mockMongo := &MockAchievementMongoRepo{
+		CreateFunc: func(ctx context.Context, achievement *model_mongo.AchievementMongo) (*model_mongo.AchievementMongo, error) {
+			created = achievement
+			return achievement, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
 
-	req := httptest.NewRequest("POST", "/achievements", nil)
-	req.Header.Set("X-Test-Role", "Dosen Wali") // Salah Role
+	payload := map[string]interface{}{}
+	raw, _ := json.Marshal(validCompetitionInput("Juara 1 Lomba Coding"))
+	json.Unmarshal(raw, &payload)
+	payload["attachments"] = []map[string]interface{}{
+		{"id": "palsu", "category": "certificate", "file_url": "http://localhost:3000/uploads/1700000000-milik-orang-lain.pdf"},
+	}
+	body, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Mahasiswa")
 	resp, _ := app.Test(req)
 
-	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	if assert.NotNil(t, created) {
+		assert.Empty(t, created.Attachments)
+	}
 }
 
-func TestUpdatePrestasi_Success(t *testing.T) {
+func TestSubmitPrestasi_PointsComputedByServer(t *testing.T) {
+	var savedPoints int
 	mockPg := &MockAchievementPGRepo{
-		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
-			return &model_postgre.AchievementReference{
-				ID: "ref-1", StudentID: "stu-123", Status: "draft", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6",
-			}, nil
-		},
 		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		CreateReferenceFunc: func(ref *model_postgre.AchievementReference, actorID string) (*model_postgre.AchievementReference, error) {
+			ref.ID = "ref-new"
+			return ref, nil
+		},
 	}
 	mockMongo := &MockAchievementMongoRepo{
-		UpdateFunc: func(ctx context.Context, id primitive.ObjectID, data *model_mongo.AchievementInput) error {
-			return nil
+		CreateFunc: func(ctx context.Context, achievement *model_mongo.AchievementMongo) (*model_mongo.AchievementMongo, error) {
+			savedPoints = achievement.Points
+			achievement.ID = primitive.NewObjectID()
+			return achievement, nil
 		},
 	}
-	app := setupAchievementServiceTestApp(mockMongo, mockPg)
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
 
-	payload := model_mongo.AchievementInput{Title: "Update Judul"}
-	body, _ := json.Marshal(payload)
+	// Client mencoba mengirim poin sendiri; harus diabaikan
+	body, _ := json.Marshal(validCompetitionInput("Juara 1 Gemastik"))
+	body = bytes.Replace(body, []byte(`{`), []byte(`{"points":9999,`), 1)
 
-	req := httptest.NewRequest("PUT", "/achievements/ref-1", bytes.NewReader(body))
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-Test-Role", "Mahasiswa")
 	req.Header.Set("X-Test-ID", "user-mhs")
 	resp, _ := app.Test(req)
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, 75, savedPoints) // competition/national/first
 }
 
-func TestSubmitForVerification_Success(t *testing.T) {
+func TestVerifyPrestasi_FreezesPoints(t *testing.T) {
+	frozen := -1
 	mockPg := &MockAchievementPGRepo{
 		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
-			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "draft"}, nil
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "submitted"}, nil
 		},
-		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
-		UpdateStatusToSubmittedFunc: func(id string) (*model_postgre.AchievementReference, error) {
-			return &model_postgre.AchievementReference{Status: "submitted"}, nil
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-1", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-123"}, nil },
+		VerifyAchievementFunc: func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: id, Status: "verified", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
 		},
 	}
-	mockMongo := &MockAchievementMongoRepo{}
-	app := setupAchievementServiceTestApp(mockMongo, mockPg)
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			input := validCompetitionInput("Juara")
+			return &model_mongo.AchievementMongo{ID: id, AchievementType: input.AchievementType, Details: input.Details}, nil
+		},
+		FreezePointsFunc: func(ctx context.Context, id primitive.ObjectID, points int) error {
+			frozen = points
+			return nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
 
-	req := httptest.NewRequest("POST", "/achievements/ref-1/submit", nil)
-	req.Header.Set("X-Test-Role", "Mahasiswa")
-	req.Header.Set("X-Test-ID", "user-mhs")
+	req := httptest.NewRequest("POST", "/achievements/ref-1/verify", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-dosen")
+	req.Header.Set("X-Test-Permissions", "achievement:verify")
 	resp, _ := app.Test(req)
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 75, frozen)
 }
 
-func TestVerifyPrestasi_Success(t *testing.T) {
+func TestSubmitPrestasi_InvalidDetails(t *testing.T) {
+	created := false
 	mockPg := &MockAchievementPGRepo{
-		VerifyAchievementFunc: func(id, lecturerID string) (*model_postgre.AchievementReference, error) {
-			return &model_postgre.AchievementReference{Status: "verified"}, nil
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		CreateFunc: func(ctx context.Context, achievement *model_mongo.AchievementMongo) (*model_mongo.AchievementMongo, error) {
+			created = true
+			return achievement, nil
 		},
 	}
-	mockMongo := &MockAchievementMongoRepo{}
-	app := setupAchievementServiceTestApp(mockMongo, mockPg)
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
 
-	req := httptest.NewRequest("POST", "/achievements/ref-1/verify", nil)
-	req.Header.Set("X-Test-Role", "Dosen Wali")
-	req.Header.Set("X-Test-ID", "user-dosen")
+	payload := model_mongo.AchievementInput{
+		AchievementType: "competition",
+		Title:           "Juara Lomba",
+		Details:         map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "kampus", "eventDate": "12-10-2024"},
+	}
+	body, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
 	resp, _ := app.Test(req)
 
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.False(t, created)
+
+	var result struct {
+		Errors []struct {
+			Field string `json:"field"`
+		} `json:"errors"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	fields := []string{}
+	for _, e := range result.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.ElementsMatch(t, []string{"details.competitionLevel", "details.rank", "details.eventDate"}, fields)
+}
+
+func TestGetAchievementType_Schema(t *testing.T) {
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, &MockAchievementPGRepo{})
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievement-types/publication", nil))
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/achievement-types/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 }
 
-func TestAddAttachment_Success(t *testing.T) {
+func TestSubmitPrestasi_Forbidden(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{}
+	mockMongo := &MockAchievementMongoRepo{}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali") // Salah Role
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestSubmitPrestasi_Team_CreatesLinkedReferences(t *testing.T) {
+	var gotOwner *model_postgre.AchievementReference
+	var gotMembers []model_postgre.AchievementReference
+	var savedDoc *model_mongo.AchievementMongo
 	mockPg := &MockAchievementPGRepo{
-		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
-			return &model_postgre.AchievementReference{MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		CreateTeamReferencesFunc: func(owner *model_postgre.AchievementReference, members []model_postgre.AchievementReference, actorID string) (*model_postgre.AchievementReference, error) {
+			gotOwner, gotMembers = owner, members
+			owner.ID = "ref-new"
+			return owner, nil
 		},
 	}
 	mockMongo := &MockAchievementMongoRepo{
-		AddAttachmentFunc: func(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) error {
-			return nil
+		CreateFunc: func(ctx context.Context, achievement *model_mongo.AchievementMongo) (*model_mongo.AchievementMongo, error) {
+			achievement.ID = primitive.NewObjectID()
+			savedDoc = achievement
+			return achievement, nil
 		},
 	}
-	app := setupAchievementServiceTestApp(mockMongo, mockPg)
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
 
-	body := new(bytes.Buffer)
-	writer := multipart.NewWriter(body)
-	part, _ := writer.CreateFormFile("file", "sertifikat.pdf")
-	part.Write([]byte("dummy content"))
-	writer.Close()
+	payload := validCompetitionInput("Juara 1 Hackathon Tim")
+	payload.TeamMembers = []model_mongo.TeamMember{{StudentID: "stu-456"}, {StudentID: "stu-789", Role: "member"}}
+	body, _ := json.Marshal(payload)
 
-	req := httptest.NewRequest("POST", "/achievements/ref-1/attachments", body)
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
 	resp, _ := app.Test(req)
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
-}
\ No newline at end of file
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "captain", *gotOwner.TeamRole)
+	assert.Len(t, gotMembers, 2)
+	assert.Len(t, savedDoc.TeamMembers, 3)
+	assert.EqualValues(t, 3, savedDoc.Details["teamSize"]) // Lewat payload outbox: int32 sesuai BSON
+}
+
+func TestSubmitPrestasi_Team_UnknownMember(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		FindStudentIdByUserIDFunc:  func(userID string) (string, error) { return "stu-123", nil },
+		FindExistingStudentIDsFunc: func(ids []string) ([]string, error) { return []string{}, nil },
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	payload := validCompetitionInput("Juara 1 Hackathon Tim")
+	payload.TeamMembers = []model_mongo.TeamMember{{StudentID: "stu-unknown"}}
+	body, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSubmitForVerification_Team_PendingConfirmation(t *testing.T) {
+	captain, member := "captain", "member"
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "draft", TeamRole: &captain}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		GetTeamReferencesFunc: func(mongoID string) ([]model_postgre.AchievementReference, error) {
+			return []model_postgre.AchievementReference{
+				{ID: "ref-1", StudentID: "stu-123", Status: "draft", TeamRole: &captain},
+				{ID: "ref-2", StudentID: "stu-456", Status: "draft", TeamRole: &member, CoMember: true},
+			}, nil
+		},
+		UpdateStatusToSubmittedFunc: func(id, actorID, workflowCode, firstStage string) (*model_postgre.AchievementReference, error) {
+			t.Fatal("submit tidak boleh dijalankan sebelum semua anggota konfirmasi")
+			return nil, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/submit", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestConfirmParticipation_Success(t *testing.T) {
+	member := "member"
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-2", StudentID: "stu-456", Status: "draft", TeamRole: &member, CoMember: true}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-456", nil },
+		ConfirmParticipationFunc: func(id, studentID string) (*model_postgre.AchievementReference, error) {
+			now := time.Now()
+			return &model_postgre.AchievementReference{ID: id, StudentID: studentID, ParticipationConfirmedAt: &now}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-2/confirm-participation", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs2")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestUpdatePrestasi_CoMember_Forbidden(t *testing.T) {
+	member := "member"
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-2", StudentID: "stu-456", Status: "draft", TeamRole: &member, CoMember: true}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-456", nil },
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	body, _ := json.Marshal(validCompetitionInput("Ubah Judul"))
+	req := httptest.NewRequest("PUT", "/achievements/ref-2", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs2")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestUpdatePrestasi_Success(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{
+				ID: "ref-1", StudentID: "stu-123", Status: "draft", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6",
+			}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		UpdateFunc: func(ctx context.Context, id primitive.ObjectID, data *model_mongo.AchievementInput) error {
+			return nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	payload := validCompetitionInput("Update Judul")
+	body, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest("PUT", "/achievements/ref-1", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestSubmitForVerification_Success(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "draft"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		UpdateStatusToSubmittedFunc: func(id, actorID, workflowCode, firstStage string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{Status: "submitted", CurrentStage: &firstStage}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			return &model_mongo.AchievementMongo{ID: id, AchievementType: "competition", Attachments: competitionEvidence()}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/submit", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestWithdrawPrestasi_Success(t *testing.T) {
+	var gotStudentID string
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "submitted"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		WithdrawSubmissionFunc: func(id, studentID, actorID string) (*model_postgre.AchievementReference, error) {
+			gotStudentID = studentID
+			return &model_postgre.AchievementReference{ID: id, Status: "draft"}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/withdraw", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "stu-123", gotStudentID)
+}
+
+func TestWithdrawPrestasi_AfterVerifierAction(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "submitted"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		WithdrawSubmissionFunc: func(id, studentID, actorID string) (*model_postgre.AchievementReference, error) {
+			return nil, repo_postgre.ErrInvalidTransition
+		},
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/withdraw", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestWithdrawPrestasi_NotOwner_Forbidden(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-999", Status: "submitted"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/withdraw", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestSubmitForVerification_RejectedIsFinal(t *testing.T) {
+	loaded := false
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "rejected", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			loaded = true
+			return &model_mongo.AchievementMongo{ID: id}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/submit", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.False(t, loaded)
+}
+
+func TestSubmitForVerification_MissingEvidence(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "draft", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		UpdateStatusToSubmittedFunc: func(id, actorID, workflowCode, firstStage string) (*model_postgre.AchievementReference, error) {
+			t.Fatal("submit tidak boleh dijalankan bila bukti belum lengkap")
+			return nil, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			// Sertifikat ada (dikenali dari ekstensi), foto belum
+			return &model_mongo.AchievementMongo{ID: id, AchievementType: "competition", Attachments: []model_mongo.Attachment{
+				{FileName: "piagam.PDF", FileType: "application/octet-stream", Category: model_mongo.AttachmentCertificate, StorageKey: "att-1-piagam.pdf"},
+			}}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/submit", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var result struct {
+		Unmet []struct {
+			Code string `json:"code"`
+		} `json:"unmet_requirements"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if assert.Len(t, result.Unmet, 1) {
+		assert.Equal(t, "photo", result.Unmet[0].Code)
+	}
+}
+
+// Lampiran tanpa file di storage (mis. dibuat client lewat JSON) tidak memenuhi syarat bukti
+func TestSubmitForVerification_FabricatedAttachmentsAreNotEvidence(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "draft", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		UpdateStatusToSubmittedFunc: func(id, actorID, workflowCode, firstStage string) (*model_postgre.AchievementReference, error) {
+			t.Fatal("submit tidak boleh dijalankan dengan lampiran tanpa file")
+			return nil, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			return &model_mongo.AchievementMongo{ID: id, AchievementType: "competition", Attachments: []model_mongo.Attachment{
+				{Category: model_mongo.AttachmentCertificate, FileName: "sertifikat.pdf", FileType: "application/pdf", FileUrl: "x"},
+				{Category: model_mongo.AttachmentPhoto, FileName: "foto.jpg", FileType: "image/jpeg", FileUrl: "x"},
+			}}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/submit", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var result struct {
+		Unmet []struct {
+			Code string `json:"code"`
+		} `json:"unmet_requirements"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Len(t, result.Unmet, 2)
+}
+
+func TestSubmitForVerification_PublicationRequiresValidDOI(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "draft", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		UpdateStatusToSubmittedFunc: func(id, actorID, workflowCode, firstStage string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{Status: "submitted", CurrentStage: &firstStage}, nil
+		},
+	}
+	doi := "bukan-doi"
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			return &model_mongo.AchievementMongo{ID: id, AchievementType: "publication", Details: map[string]interface{}{"doi": doi}}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	submit := func() int {
+		req := httptest.NewRequest("POST", "/achievements/ref-1/submit", nil)
+		req.Header.Set("X-Test-Role", "Mahasiswa")
+		req.Header.Set("X-Test-ID", "user-mhs")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusBadRequest, submit())
+
+	doi = "https://doi.org/10.1109/ACCESS.2024.1234567"
+	assert.Equal(t, http.StatusOK, submit())
+}
+
+func TestVerifyPrestasi_Success(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "submitted"}, nil
+		},
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-1", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-123"}, nil },
+		VerifyAchievementFunc: func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{Status: "verified"}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/verify", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-dosen")
+	req.Header.Set("X-Test-Permissions", "achievement:verify")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestVerifyPrestasi_EscalatedToFallbackVerifier(t *testing.T) {
+	submittedAt := time.Now().AddDate(0, 0, -20)
+	escalatedAt := submittedAt.AddDate(0, 0, 14)
+	fallback := "user-kaprodi"
+	verified := false
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "submitted",
+				SubmittedAt: &submittedAt, EscalatedAt: &escalatedAt, EscalatedTo: &fallback}, nil
+		},
+		// Penerima eskalasi bukan dosen wali mahasiswa ini
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-lain", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-999"}, nil },
+		VerifyAchievementFunc: func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
+			verified = true
+			return &model_postgre.AchievementReference{Status: "verified"}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	verify := func(userID string) int {
+		req := httptest.NewRequest("POST", "/achievements/ref-1/verify", nil)
+		req.Header.Set("X-Test-Role", "Dosen Wali")
+		req.Header.Set("X-Test-ID", userID)
+		req.Header.Set("X-Test-Permissions", "achievement:verify")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusForbidden, verify("user-dosen-lain"))
+	assert.False(t, verified)
+	assert.Equal(t, http.StatusOK, verify(fallback))
+	assert.True(t, verified)
+}
+
+func TestVerifyPrestasi_EscalationBoundToEscalatedStage(t *testing.T) {
+	submittedAt := time.Now().AddDate(0, 0, -20)
+	escalatedAt := submittedAt.AddDate(0, 0, 14)
+	workflowCode, fallback := "high-level", "user-dosen-cadangan"
+	ref := &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "submitted",
+		WorkflowCode: &workflowCode, SubmittedAt: &submittedAt, EscalatedAt: &escalatedAt, EscalatedTo: &fallback}
+	processed := false
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			current := *ref
+			return &current, nil
+		},
+		// Verifikator cadangan bukan dosen wali mahasiswa ini
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-lain", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-999"}, nil },
+		AdvanceStageFunc: func(id, actorID, onBehalfOf, fromStage, toStage, note string) (*model_postgre.AchievementReference, error) {
+			processed = true
+			return &model_postgre.AchievementReference{Status: "submitted", CurrentStage: &toStage}, nil
+		},
+		VerifyAchievementFunc: func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
+			processed = true
+			return &model_postgre.AchievementReference{Status: "verified"}, nil
+		},
+	}
+	app := setupAchievementServiceTestAppWithWorkflow(t, &MockAchievementMongoRepo{}, mockPg, twoStageEngine())
+
+	verify := func(currentStage, escalatedStage string) int {
+		ref.CurrentStage, ref.EscalatedStage = &currentStage, &escalatedStage
+		processed = false
+		req := httptest.NewRequest("POST", "/achievements/ref-1/verify", nil)
+		req.Header.Set("X-Test-Role", "Dosen Wali")
+		req.Header.Set("X-Test-ID", fallback)
+		req.Header.Set("X-Test-Permissions", "achievement:verify")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	// Tahap Dosen Wali yang dieskalasi boleh diproses verifikator cadangan
+	assert.Equal(t, http.StatusOK, verify("advisor", "advisor"))
+	assert.True(t, processed)
+	// Setelah diteruskan, eskalasi tahap sebelumnya tidak berlaku di tahap fakultas
+	assert.Equal(t, http.StatusForbidden, verify("faculty", "advisor"))
+	assert.False(t, processed)
+	// Eskalasi di tahap fakultas tidak memberi Dosen Wali akses ke tahap khusus Admin
+	assert.Equal(t, http.StatusForbidden, verify("faculty", "faculty"))
+	assert.False(t, processed)
+}
+
+func TestVerifyPrestasi_DelegateActsOnBehalfOfAdvisor(t *testing.T) {
+	var gotActor, gotOnBehalf string
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-cuti", Status: "submitted"}, nil
+		},
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-delegate", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-123"}, nil },
+		GetDelegatedAdviseesFunc: func(lecturerID string) ([]model_postgre.DelegatedAdvisee, error) {
+			return []model_postgre.DelegatedAdvisee{{StudentID: "stu-cuti", DelegatorUserID: "user-dosen-cuti", DelegationID: "del-1"}}, nil
+		},
+		VerifyAchievementFunc: func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
+			gotActor, gotOnBehalf = lecturerID, onBehalfOf
+			return &model_postgre.AchievementReference{Status: "verified"}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/verify", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-delegate")
+	req.Header.Set("X-Test-Permissions", "achievement:verify")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "user-delegate", gotActor)
+	assert.Equal(t, "user-dosen-cuti", gotOnBehalf)
+}
+
+func TestListAllAchievements_DelegateSeesOnlyPendingQueue(t *testing.T) {
+	var gotFilter model_mongo.AchievementViewFilter
+	mockPg := &MockAchievementPGRepo{
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-delegate", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-own"}, nil },
+		GetDelegatedAdviseesFunc: func(lecturerID string) ([]model_postgre.DelegatedAdvisee, error) {
+			return []model_postgre.DelegatedAdvisee{{StudentID: "stu-cuti", DelegatorUserID: "user-dosen-cuti"}}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{Views: &MockAchievementViewRepo{
+		ListFunc: func(filter model_mongo.AchievementViewFilter) ([]model_mongo.AchievementView, int, error) {
+			gotFilter = filter
+			return []model_mongo.AchievementView{
+				{ID: "ref-own", StudentID: "stu-own", Status: "verified"},
+				{ID: "ref-pending", StudentID: "stu-cuti", Status: "submitted"},
+			}, 2, nil
+		},
+	}}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	req := httptest.NewRequest("GET", "/achievements", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-delegate")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"stu-own"}, gotFilter.StudentIDs)
+	assert.Equal(t, []string{"stu-cuti"}, gotFilter.PendingStudentIDs)
+	assert.False(t, gotFilter.IncludeDrafts)
+
+	var result struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if assert.Len(t, result.Data, 2) {
+		assert.Equal(t, "ref-own", result.Data[0]["id"])
+		assert.Nil(t, result.Data[0]["on_behalf_of"])
+		assert.Equal(t, "ref-pending", result.Data[1]["id"])
+		assert.Equal(t, "user-dosen-cuti", result.Data[1]["on_behalf_of"])
+	}
+}
+
+func twoStageEngine() *workflow.Engine {
+	engine, _ := workflow.NewEngine([]workflow.Definition{
+		{
+			Code:  "high-level",
+			Match: &workflow.Rule{AchievementTypes: []string{"competition"}, CompetitionLevels: []string{"national", "international"}},
+			Stages: []workflow.Stage{
+				{Code: "advisor", Name: "Dosen Wali", Permission: "achievement:verify", Roles: []string{"Dosen Wali"}},
+				{Code: "faculty", Name: "Fakultas", Permission: "achievement:verify", Roles: []string{"Admin"}},
+			},
+		},
+	})
+	return engine
+}
+
+func TestSubmitForVerification_RoutesByCompetitionLevel(t *testing.T) {
+	var gotWorkflow, gotStage string
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "draft", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		UpdateStatusToSubmittedFunc: func(id, actorID, workflowCode, firstStage string) (*model_postgre.AchievementReference, error) {
+			gotWorkflow, gotStage = workflowCode, firstStage
+			return &model_postgre.AchievementReference{Status: "submitted", CurrentStage: &firstStage}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			return &model_mongo.AchievementMongo{ID: id, AchievementType: "competition", Details: map[string]interface{}{"competitionLevel": "national"}, Attachments: competitionEvidence()}, nil
+		},
+	}
+	app := setupAchievementServiceTestAppWithWorkflow(t, mockMongo, mockPg, twoStageEngine())
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/submit", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "high-level", gotWorkflow)
+	assert.Equal(t, "advisor", gotStage)
+}
+
+func TestVerifyPrestasi_MultiStage_AdvancesToNextStage(t *testing.T) {
+	workflowCode, stage := "high-level", "advisor"
+	advanced, verified := false, false
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "submitted", WorkflowCode: &workflowCode, CurrentStage: &stage}, nil
+		},
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-1", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-123"}, nil },
+		AdvanceStageFunc: func(id, actorID, onBehalfOf, fromStage, toStage, note string) (*model_postgre.AchievementReference, error) {
+			advanced = fromStage == "advisor" && toStage == "faculty"
+			return &model_postgre.AchievementReference{Status: "submitted", CurrentStage: &toStage}, nil
+		},
+		VerifyAchievementFunc: func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
+			verified = true
+			return &model_postgre.AchievementReference{Status: "verified"}, nil
+		},
+	}
+	app := setupAchievementServiceTestAppWithWorkflow(t, &MockAchievementMongoRepo{}, mockPg, twoStageEngine())
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/verify", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-Permissions", "achievement:verify")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, advanced)
+	assert.False(t, verified)
+}
+
+func TestVerifyPrestasi_MultiStage_WrongStageVerifier(t *testing.T) {
+	workflowCode, stage := "high-level", "faculty"
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", Status: "submitted", WorkflowCode: &workflowCode, CurrentStage: &stage}, nil
+		},
+	}
+	app := setupAchievementServiceTestAppWithWorkflow(t, &MockAchievementMongoRepo{}, mockPg, twoStageEngine())
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/verify", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-Permissions", "achievement:verify")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestVerifyPrestasi_NotAdvisee_Forbidden(t *testing.T) {
+	verified := false
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-999", Status: "submitted"}, nil
+		},
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-1", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-123"}, nil },
+		VerifyAchievementFunc: func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
+			verified = true
+			return &model_postgre.AchievementReference{Status: "verified"}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/verify", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-dosen")
+	req.Header.Set("X-Test-Permissions", "achievement:verify")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.False(t, verified)
+}
+
+func TestGetAchievementDetail_OtherStudent_Forbidden(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-999", Status: "verified", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("GET", "/achievements/ref-1", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestGetHistory_AdvisorOfOtherStudent_Forbidden(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-999", Status: "submitted"}, nil
+		},
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-1", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-123"}, nil },
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("GET", "/achievements/ref-1/history", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-dosen")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestGetHistoryAndRevisions_EscalatedFallbackVerifier(t *testing.T) {
+	mongoID, _ := primitive.ObjectIDFromHex("64b0f1a2e4b0a1a2b3c4d5e6")
+	submittedAt := time.Now().AddDate(0, 0, -20)
+	escalatedAt := submittedAt.AddDate(0, 0, 14)
+	fallback := "user-dosen-cadangan"
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "submitted", MongoAchievementID: mongoID.Hex(),
+				SubmittedAt: &submittedAt, EscalatedAt: &escalatedAt, EscalatedTo: &fallback}, nil
+		},
+		// Penerima eskalasi bukan dosen wali mahasiswa ini
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-lain", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-999"}, nil },
+	}
+	revisions := &MockRevisionRepo{Revisions: []model_mongo.AchievementRevision{
+		{AchievementID: mongoID, Version: 1, Title: "Awal"},
+		{AchievementID: mongoID, Version: 2, Title: "Revisi"},
+	}}
+	engine, _ := workflow.NewEngine(workflow.DefaultDefinitions())
+	app := setupAchievementServiceTestAppWithRevisions(t, &MockAchievementMongoRepo{}, mockPg, revisions, engine)
+
+	get := func(path, userID string) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-Test-Role", "Dosen Wali")
+		req.Header.Set("X-Test-ID", userID)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+	for _, path := range []string{"/achievements/ref-1/history", "/achievements/ref-1/revisions", "/achievements/ref-1/revisions/diff"} {
+		assert.Equal(t, http.StatusOK, get(path, fallback), path)
+		assert.Equal(t, http.StatusForbidden, get(path, "user-dosen-lain"), path)
+	}
+}
+
+func TestBulkVerify_PartialSuccess(t *testing.T) {
+	var verifiedIDs []string
+	mockPg := &MockAchievementPGRepo{
+		GetReferencesByIDsFunc: func(ids []string) ([]model_postgre.AchievementReference, error) {
+			return []model_postgre.AchievementReference{
+				{ID: "ref-ok", StudentID: "stu-123", Status: "submitted"},
+				{ID: "ref-draft", StudentID: "stu-123", Status: "draft"},
+				{ID: "ref-other", StudentID: "stu-999", Status: "submitted"},
+			}, nil
+		},
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-1", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-123"}, nil },
+		VerifyAchievementFunc: func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
+			verifiedIDs = append(verifiedIDs, id)
+			return &model_postgre.AchievementReference{ID: id, Status: "verified"}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	body, _ := json.Marshal(model_postgre.BulkActionRequest{IDs: []string{"ref-ok", "ref-draft", "ref-other", "ref-missing", "ref-ok"}})
+	req := httptest.NewRequest("POST", "/achievements/bulk/verify", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-dosen")
+	req.Header.Set("X-Test-Permissions", "achievement:verify")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data struct {
+			Succeeded int                              `json:"succeeded"`
+			Failed    int                              `json:"failed"`
+			Results   []model_postgre.BulkActionResult `json:"results"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Equal(t, 1, result.Data.Succeeded)
+	assert.Equal(t, 3, result.Data.Failed)
+	assert.Equal(t, []string{"ref-ok"}, verifiedIDs)
+
+	reasons := map[string]string{}
+	for _, r := range result.Data.Results {
+		reasons[r.ID] = r.Reason
+	}
+	assert.Equal(t, "", reasons["ref-ok"])
+	assert.Equal(t, model_postgre.BulkReasonInvalidStatus, reasons["ref-draft"])
+	assert.Equal(t, model_postgre.BulkReasonNotAdvisee, reasons["ref-other"])
+	assert.Equal(t, model_postgre.BulkReasonNotFound, reasons["ref-missing"])
+}
+
+func TestBulkReject_RequiresNote(t *testing.T) {
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, &MockAchievementPGRepo{})
+
+	body, _ := json.Marshal(model_postgre.BulkActionRequest{IDs: []string{"ref-1"}})
+	req := httptest.NewRequest("POST", "/achievements/bulk/reject", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-Permissions", "achievement:verify")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestBulkReject_GuardsOnCurrentStage(t *testing.T) {
+	workflowCode, stage := "high-level", "advisor"
+	var gotStage string
+	mockPg := &MockAchievementPGRepo{
+		GetReferencesByIDsFunc: func(ids []string) ([]model_postgre.AchievementReference, error) {
+			return []model_postgre.AchievementReference{{ID: "ref-1", StudentID: "stu-123", Status: "submitted", WorkflowCode: &workflowCode, CurrentStage: &stage}}, nil
+		},
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-1", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-123"}, nil },
+		RejectAchievementFunc: func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
+			gotStage = stage
+			// Tahap sudah dipindahkan verifikator lain sebelum query berjalan
+			return nil, repo_postgre.ErrInvalidTransition
+		},
+	}
+	app := setupAchievementServiceTestAppWithWorkflow(t, &MockAchievementMongoRepo{}, mockPg, twoStageEngine())
+
+	body, _ := json.Marshal(model_postgre.BulkActionRequest{IDs: []string{"ref-1"}, Note: "Sertifikat tidak valid"})
+	req := httptest.NewRequest("POST", "/achievements/bulk/reject", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-dosen")
+	req.Header.Set("X-Test-Permissions", "achievement:verify")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "advisor", gotStage)
+
+	var result struct {
+		Data struct {
+			Results []model_postgre.BulkActionResult `json:"results"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Len(t, result.Data.Results, 1)
+	assert.Equal(t, model_postgre.BulkReasonInvalidStatus, result.Data.Results[0].Reason)
+}
+
+func TestUpdatePrestasi_StoresRevisionWithBaseline(t *testing.T) {
+	mongoID, _ := primitive.ObjectIDFromHex("64b0f1a2e4b0a1a2b3c4d5e6")
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "rejected", MongoAchievementID: mongoID.Hex()}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		// Dokumen lama (dibuat sebelum fitur revisi)
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			in := validCompetitionInput("Judul Lama")
+			return &model_mongo.AchievementMongo{ID: id, AchievementType: in.AchievementType, Title: in.Title, Details: in.Details}, nil
+		},
+	}
+	revisions := &MockRevisionRepo{}
+	engine, _ := workflow.NewEngine(workflow.DefaultDefinitions())
+	app := setupAchievementServiceTestAppWithRevisions(t, mockMongo, mockPg, revisions, engine)
+
+	payload := validCompetitionInput("Judul Baru")
+	payload.Details["rank"] = "second"
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("PUT", "/achievements/ref-1", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	if assert.Len(t, revisions.Revisions, 2) {
+		assert.Equal(t, "Judul Lama", revisions.Revisions[0].Title)
+		assert.Equal(t, "Judul Baru", revisions.Revisions[1].Title)
+		assert.Equal(t, "user-mhs", revisions.Revisions[1].EditedBy)
+	}
+
+	req = httptest.NewRequest("GET", "/achievements/ref-1/revisions/diff", nil)
+	req.Header.Set("X-Test-Role", "Admin")
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data struct {
+			Changes []model_mongo.RevisionChange `json:"changes"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	fields := []string{}
+	for _, change := range result.Data.Changes {
+		fields = append(fields, change.Field)
+	}
+	assert.Equal(t, []string{"title", "details.rank"}, fields)
+}
+
+func TestDiffRevisions_SingleRevision_BadRequest(t *testing.T) {
+	mongoID, _ := primitive.ObjectIDFromHex("64b0f1a2e4b0a1a2b3c4d5e6")
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", MongoAchievementID: mongoID.Hex()}, nil
+		},
+	}
+	revisions := &MockRevisionRepo{Revisions: []model_mongo.AchievementRevision{{AchievementID: mongoID, Version: 1, Title: "Awal"}}}
+	engine, _ := workflow.NewEngine(workflow.DefaultDefinitions())
+	app := setupAchievementServiceTestAppWithRevisions(t, &MockAchievementMongoRepo{}, mockPg, revisions, engine)
+
+	req := httptest.NewRequest("GET", "/achievements/ref-1/revisions/diff", nil)
+	req.Header.Set("X-Test-Role", "Admin")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestListTrash_Mahasiswa_OwnItemsWithPurgeDate(t *testing.T) {
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var gotStudentID string
+	mockPg := &MockAchievementPGRepo{
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		GetDeletedReferencesFunc: func(studentID string) ([]model_postgre.AchievementReference, error) {
+			gotStudentID = studentID
+			return []model_postgre.AchievementReference{
+				{ID: "ref-1", StudentID: "stu-123", Status: "deleted", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6", DeletedAt: &deletedAt},
+			}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		GetDeletedDetailsByIDsFunc: func(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error) {
+			return []model_mongo.AchievementMongo{{ID: ids[0], Title: "Juara Terhapus"}}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	req := httptest.NewRequest("GET", "/achievements/trash", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "stu-123", gotStudentID)
+
+	var result struct {
+		Data []struct {
+			Title   string    `json:"title"`
+			PurgeAt time.Time `json:"purge_at"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if assert.Len(t, result.Data, 1) {
+		assert.Equal(t, "Juara Terhapus", result.Data[0].Title)
+		assert.Equal(t, deletedAt.AddDate(0, 0, model_postgre.DefaultTrashRetentionDays), result.Data[0].PurgeAt)
+	}
+}
+
+func TestListTrash_DosenWali_Forbidden(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-1", nil },
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("GET", "/achievements/trash", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-dosen")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestRestorePrestasi_MongoFailure_LeavesPendingOutbox(t *testing.T) {
+	softDeleted := false
+	mockPg := &MockAchievementPGRepo{
+		RestoreReferenceFunc: func(id, studentID, actorID string) (*model_postgre.AchievementReference, error) {
+			assert.Equal(t, "", studentID) // Admin: tanpa filter pemilik
+			return &model_postgre.AchievementReference{ID: id, StudentID: "stu-123", Status: "draft", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		SoftDeleteReferenceFunc: func(id, studentID, actorID string) (*model_postgre.AchievementReference, error) {
+			softDeleted = true
+			return nil, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		RestoreFunc: func(ctx context.Context, id primitive.ObjectID) error { return errors.New("mongo down") },
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/restore", nil)
+	req.Header.Set("X-Test-Role", "Admin")
+	req.Header.Set("X-Test-ID", "user-admin")
+	resp, _ := app.Test(req)
+
+	// Restore PostgreSQL sudah commit bersama event outbox; tidak ada rollback, worker yang menyelesaikan
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var result struct { Synced bool `json:"synced"` }
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.False(t, result.Synced)
+	assert.False(t, softDeleted)
+	if assert.Len(t, mockPg.Outbox.Events, 1) {
+		event := mockPg.Outbox.Events[0]
+		assert.Equal(t, model_postgre.OutboxRestore, event.Operation)
+		assert.Equal(t, 1, event.Attempts)
+		assert.Nil(t, event.ProcessedAt)
+		assert.Equal(t, "mongo down", *event.LastError)
+	}
+}
+
+func TestOverrideStatus_FromVerified_RequiresConfirm(t *testing.T) {
+	called := false
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", Status: "verified", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		OverrideStatusFunc: func(id string, to model_postgre.AchievementStatus, actorID, reason, workflowCode, firstStage string, allowFromVerified bool) (*model_postgre.AchievementReference, error) {
+			called = true
+			return nil, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	body, _ := json.Marshal(model_postgre.StatusOverrideRequest{Status: "rejected", Reason: "Sertifikat terbukti palsu"})
+	req := httptest.NewRequest("POST", "/achievements/ref-1/override-status", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Admin")
+	req.Header.Set("X-Test-ID", "user-admin")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.False(t, called)
+}
+
+func TestOverrideStatus_FromVerified_Confirmed_UnfreezesPoints(t *testing.T) {
+	var gotReason string
+	var gotAllow bool
+	unfrozen := false
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", Status: "verified", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		OverrideStatusFunc: func(id string, to model_postgre.AchievementStatus, actorID, reason, workflowCode, firstStage string, allowFromVerified bool) (*model_postgre.AchievementReference, error) {
+			gotReason, gotAllow = reason, allowFromVerified
+			return &model_postgre.AchievementReference{ID: id, Status: to, MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		UnfreezePointsFunc: func(ctx context.Context, id primitive.ObjectID) error { unfrozen = true; return nil },
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	body, _ := json.Marshal(model_postgre.StatusOverrideRequest{Status: "rejected", Reason: "Sertifikat terbukti palsu", Confirm: true})
+	req := httptest.NewRequest("POST", "/achievements/ref-1/override-status", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Admin")
+	req.Header.Set("X-Test-ID", "user-admin")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Sertifikat terbukti palsu", gotReason)
+	assert.True(t, gotAllow)
+	assert.True(t, unfrozen)
+}
+
+func TestOverrideStatus_ValidationAndRole(t *testing.T) {
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, &MockAchievementPGRepo{})
+
+	cases := []struct {
+		role    string
+		payload model_postgre.StatusOverrideRequest
+		want    int
+	}{
+		{"Dosen Wali", model_postgre.StatusOverrideRequest{Status: "verified", Reason: "Salah tolak oleh dosen"}, http.StatusForbidden},
+		{"Admin", model_postgre.StatusOverrideRequest{Status: "verified", Reason: "singkat"}, http.StatusBadRequest},
+		{"Admin", model_postgre.StatusOverrideRequest{Status: "deleted", Reason: "Data duplikat dari mahasiswa"}, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		body, _ := json.Marshal(tc.payload)
+		req := httptest.NewRequest("POST", "/achievements/ref-1/override-status", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Test-Role", tc.role)
+		resp, _ := app.Test(req)
+		assert.Equal(t, tc.want, resp.StatusCode, tc.payload.Reason)
+	}
+}
+
+func TestUpdatePrestasi_RevisionRequested_Allowed(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{
+				ID: "ref-1", StudentID: "stu-123", Status: model_postgre.StatusRevisionRequested, MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6",
+			}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	body, _ := json.Marshal(validCompetitionInput("Perbaikan"))
+	req := httptest.NewRequest("PUT", "/achievements/ref-1", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestRequestRevision_Success(t *testing.T) {
+	var gotNote, gotStage string
+	stage := "advisor"
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "submitted", CurrentStage: &stage}, nil
+		},
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-1", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-123"}, nil },
+		RequestRevisionFunc: func(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) {
+			gotNote, gotStage = note, stage
+			return &model_postgre.AchievementReference{Status: model_postgre.StatusRevisionRequested, RevisionCount: 1}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	body, _ := json.Marshal(map[string]string{"revision_note": "Lampirkan sertifikat asli"})
+	req := httptest.NewRequest("POST", "/achievements/ref-1/request-revision", bytes.NewReader(body))
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-dosen")
+	req.Header.Set("X-Test-Permissions", "achievement:verify")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Lampirkan sertifikat asli", gotNote)
+	// Tahap yang dilihat verifikator dipakai sebagai guard di query
+	assert.Equal(t, "advisor", gotStage)
+}
+
+func TestRequestRevision_MissingNote(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "submitted"}, nil
+		},
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-1", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-123"}, nil },
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/request-revision", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-Permissions", "achievement:verify")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGetHistory_ReturnsStoredLog(t *testing.T) {
+	draft := model_postgre.StatusDraft
+	submitted := model_postgre.StatusSubmitted
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: "rejected"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		GetStatusHistoryFunc: func(id string) ([]model_postgre.AchievementStatusHistory, error) {
+			note := "Sertifikat buram"
+			return []model_postgre.AchievementStatusHistory{
+				{ID: "h-1", AchievementRefID: id, ToStatus: draft},
+				{ID: "h-2", AchievementRefID: id, FromStatus: &draft, ToStatus: submitted},
+				{ID: "h-3", AchievementRefID: id, FromStatus: &submitted, ToStatus: "rejected", Note: &note},
+			}, nil
+		},
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("GET", "/achievements/ref-1/history", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data []model_postgre.AchievementStatusHistory `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 3)
+	assert.Equal(t, "Sertifikat buram", *body.Data[2].Note)
+}
+
+func TestGetHistory_NotFound(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return nil, errors.New("not found")
+		},
+	}
+	app := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	req := httptest.NewRequest("GET", "/achievements/ref-x/history", nil)
+	req.Header.Set("X-Test-Role", "Admin")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestAddAttachment_Success(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{StudentID: "stu-123", Status: "draft", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+	}
+	var saved model_mongo.Attachment
+	store := storage.NewLocal(t.TempDir(), "/uploads")
+	mockMongo := &MockAchievementMongoRepo{
+		AddAttachmentFunc: func(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) error {
+			saved = attachment
+			return nil
+		},
+		Storage: store,
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "../Sertifikat Juara #1.PDF")
+	part.Write(testPDF)
+	writer.WriteField("category", "certificate")
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/attachments", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, saved.ID)
+	// URL lampiran menunjuk endpoint unduh, bukan file statis
+	assert.Equal(t, "/api/v1/achievements/ref-1/attachments/"+saved.ID, saved.FileUrl)
+	assert.Equal(t, saved.ID+"-sertifikat-juara-1.pdf", saved.StorageKey)
+	assert.Equal(t, "Sertifikat Juara #1.PDF", saved.FileName)
+	// Content type dari isi file, bukan header multipart (application/octet-stream)
+	assert.Equal(t, "application/pdf", saved.FileType)
+	obj, err := store.Stat(context.Background(), saved.StorageKey)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(len(testPDF)), obj.Size)
+	}
+}
+
+func TestAddAttachment_FailedSaveRemovesStoredFile(t *testing.T) {
+	mongoID := "64b0f1a2e4b0a1a2b3c4d5e6"
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{StudentID: "stu-123", Status: "draft", MongoAchievementID: mongoID}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+	}
+	dir := t.TempDir()
+	mockMongo := &MockAchievementMongoRepo{
+		AddAttachmentFunc: func(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) error {
+			return errors.New("mongo down")
+		},
+		Storage: storage.NewLocal(dir, "/uploads"),
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	post := func() *http.Response {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", "sertifikat.pdf")
+		part.Write(testPDF)
+		writer.Close()
+
+		req := httptest.NewRequest("POST", "/achievements/ref-1/attachments", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("X-Test-Role", "Mahasiswa")
+		resp, _ := app.Test(req)
+		return resp
+	}
+
+	resp := post()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	// Error asli MongoDB hanya dicatat di log, tidak dikirim ke client
+	var result map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Equal(t, map[string]interface{}{"message": "Gagal menyimpan lampiran ke database", "code": "500"}, result)
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// ID Mongo corrupt ditolak sebelum file disimpan
+	mongoID = "bukan-object-id"
+	assert.Equal(t, http.StatusInternalServerError, post().StatusCode)
+	entries, err = os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestAddAttachment_BodyOverServerLimitReturnsRejection(t *testing.T) {
+	saved := 0
+	mockMongo := &MockAchievementMongoRepo{
+		AddAttachmentFunc: func(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) error {
+			saved++
+			return nil
+		},
+		// Batas kecil agar body melebihi BodyLimit tanpa membuat file puluhan MB
+		Uploads: upload.NewValidator([]upload.Rule{{MIME: "application/pdf", Label: "PDF", Extensions: []string{".pdf"}, MaxSize: 1 << 10}}),
+	}
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{StudentID: "stu-123", Status: "draft", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	// app.Test tidak mengembalikan respons untuk request yang ditolak server sebelum handler berjalan
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) { return }
+	go app.Listener(ln)
+	t.Cleanup(func() { app.Shutdown() })
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", "besar.pdf")
+	part.Write(append(testPDF, make([]byte, 2<<20)...))
+	writer.Close()
+	req, _ := http.NewRequest("POST", "http://"+ln.Addr().String()+"/achievements/ref-1/attachments", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	resp, err := http.DefaultClient.Do(req)
+	if !assert.NoError(t, err) { return }
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+
+	var result struct {
+		Message string             `json:"message"`
+		Code    string             `json:"code"`
+		Errors  []upload.Rejection `json:"errors"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, "413", result.Code)
+	if assert.Len(t, result.Errors, 1) {
+		assert.Equal(t, upload.ReasonTooLarge, result.Errors[0].Reason)
+		assert.Equal(t, int64(1<<10), result.Errors[0].MaxSize)
+	}
+	assert.Equal(t, 0, saved)
+}
+
+// testPDF: Isi minimal yang dikenali sebagai PDF oleh content sniffing
+var testPDF = []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n%%EOF\n")
+
+func TestAddAttachment_RejectsInvalidFiles(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{StudentID: "stu-123", Status: "draft", MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+	}
+	saved := 0
+	mockMongo := &MockAchievementMongoRepo{
+		AddAttachmentFunc: func(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) error {
+			saved++
+			return nil
+		},
+		Storage: storage.NewLocal(t.TempDir(), "/uploads"),
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, mockPg)
+
+	cases := []struct {
+		name    string
+		content []byte
+		status  int
+		reason  string
+	}{
+		{"setup.exe", []byte("MZ\x90\x00"), http.StatusUnsupportedMediaType, upload.ReasonUnsupported},
+		{"sertifikat.pdf", []byte("MZ\x90\x00 bukan pdf"), http.StatusUnsupportedMediaType, upload.ReasonContentMismatch},
+		{"foto.jpg", testPDF, http.StatusUnsupportedMediaType, upload.ReasonContentMismatch},
+		{"kosong.pdf", []byte{}, http.StatusBadRequest, upload.ReasonEmpty},
+		{"besar.png", append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 5<<20)...), http.StatusRequestEntityTooLarge, upload.ReasonTooLarge},
+	}
+	for _, tc := range cases {
+		body := new(bytes.Buffer)
+		writer := multipart.NewWriter(body)
+		part, _ := writer.CreateFormFile("file", tc.name)
+		part.Write(tc.content)
+		writer.Close()
+
+		req := httptest.NewRequest("POST", "/achievements/ref-1/attachments", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("X-Test-Role", "Mahasiswa")
+		resp, _ := app.Test(req, -1)
+		assert.Equal(t, tc.status, resp.StatusCode, tc.name)
+
+		var result struct {
+			Errors []upload.Rejection `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		if assert.Len(t, result.Errors, 1, tc.name) {
+			assert.Equal(t, tc.reason, result.Errors[0].Reason, tc.name)
+			assert.Equal(t, tc.name, result.Errors[0].FileName)
+		}
+	}
+	assert.Equal(t, 0, saved)
+}
+
+// downloadTestApp: Prestasi milik stu-123 dengan satu lampiran PDF 10 byte di storage sementara
+func downloadTestApp(t *testing.T) *fiber.App {
+	store := storage.NewLocal(t.TempDir(), "/uploads")
+	assert.NoError(t, store.Put(context.Background(), "att-1-sertifikat.pdf", strings.NewReader("0123456789"), 10, "application/pdf"))
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			return &model_mongo.AchievementMongo{ID: id, Attachments: []model_mongo.Attachment{
+				{ID: "att-1", FileName: "sertifikat juara.pdf", FileType: "application/pdf", StorageKey: "att-1-sertifikat.pdf"},
+				{ID: "att-hilang", FileName: "foto.jpg", StorageKey: "att-hilang-foto.jpg"},
+			}}, nil
+		},
+		Storage: store,
+	}
+	return setupAchievementServiceTestApp(t, mockMongo, commentTestPG("submitted"))
+}
+
+func downloadAttachment(app *fiber.App, role, path, byteRange string) *http.Response {
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("X-Test-Role", role)
+	req.Header.Set("X-Test-ID", "user-"+role)
+	if byteRange != "" { req.Header.Set("Range", byteRange) }
+	resp, _ := app.Test(req)
+	return resp
+}
+
+func TestDownloadAttachment_StreamsFileWithHeaders(t *testing.T) {
+	app := downloadTestApp(t)
+
+	resp := downloadAttachment(app, "Mahasiswa", "/achievements/ref-1/attachments/att-1", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "0123456789", string(body))
+	assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+	assert.Equal(t, `inline; filename="sertifikat juara.pdf"`, resp.Header.Get("Content-Disposition"))
+	assert.Equal(t, "bytes", resp.Header.Get("Accept-Ranges"))
+	assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+
+	resp = downloadAttachment(app, "Dosen Wali", "/achievements/ref-1/attachments/att-1?download=true", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `attachment; filename="sertifikat juara.pdf"`, resp.Header.Get("Content-Disposition"))
+}
+
+func TestDownloadAttachment_RangeRequests(t *testing.T) {
+	app := downloadTestApp(t)
+	cases := []struct {
+		byteRange    string
+		status       int
+		contentRange string
+		body         string
+	}{
+		{"bytes=2-5", http.StatusPartialContent, "bytes 2-5/10", "2345"},
+		{"bytes=7-", http.StatusPartialContent, "bytes 7-9/10", "789"},
+		{"bytes=-3", http.StatusPartialContent, "bytes 7-9/10", "789"},
+		{"bytes=8-100", http.StatusPartialContent, "bytes 8-9/10", "89"},
+		{"bytes=0-1,4-5", http.StatusOK, "", "0123456789"},
+		{"bytes=10-", http.StatusRequestedRangeNotSatisfiable, "bytes */10", ""},
+	}
+	for _, tc := range cases {
+		resp := downloadAttachment(app, "Admin", "/achievements/ref-1/attachments/att-1", tc.byteRange)
+		assert.Equal(t, tc.status, resp.StatusCode, tc.byteRange)
+		assert.Equal(t, tc.contentRange, resp.Header.Get("Content-Range"), tc.byteRange)
+		if tc.status != http.StatusRequestedRangeNotSatisfiable {
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, tc.body, string(body), tc.byteRange)
+		}
+	}
+}
+
+func TestDownloadAttachment_AccessAndNotFound(t *testing.T) {
+	app := downloadTestApp(t)
+	mockPg := commentTestPG("submitted")
+	mockPg.FindStudentIdByUserIDFunc = func(userID string) (string, error) { return "stu-lain", nil }
+	otherStudent := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+
+	assert.Equal(t, http.StatusForbidden, downloadAttachment(otherStudent, "Mahasiswa", "/achievements/ref-1/attachments/att-1", "").StatusCode)
+	assert.Equal(t, http.StatusNotFound, downloadAttachment(app, "Admin", "/achievements/ref-1/attachments/tidak-ada", "").StatusCode)
+	// Metadata ada tetapi file sudah hilang dari storage
+	assert.Equal(t, http.StatusNotFound, downloadAttachment(app, "Admin", "/achievements/ref-1/attachments/att-hilang", "").StatusCode)
+}
+
+// Lampiran tanpa storage key yang ditulis server tidak pernah dibaca dari storage, walaupun
+// FileUrl-nya menunjuk file milik mahasiswa lain yang benar-benar ada
+func TestDownloadAttachment_IgnoresForgedFileURL(t *testing.T) {
+	store := storage.NewLocal(t.TempDir(), "/uploads")
+	assert.NoError(t, store.Put(context.Background(), "1700000000-milik-orang-lain.pdf", bytes.NewReader(testPDF), int64(len(testPDF)), "application/pdf"))
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			return &model_mongo.AchievementMongo{ID: id, Attachments: []model_mongo.Attachment{
+				{ID: "palsu", FileName: "bukti.pdf", FileUrl: "http://localhost:3000/uploads/1700000000-milik-orang-lain.pdf"},
+			}}, nil
+		},
+		Storage: store,
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, commentTestPG("draft"))
+
+	for _, id := range []string{"palsu", "1700000000-milik-orang-lain.pdf"} {
+		resp := downloadAttachment(app, "Mahasiswa", "/achievements/ref-1/attachments/"+id, "")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, id)
+	}
+}
+
+// attachmentEditFixture: Prestasi stu-123 berstatus status dengan lampiran att-1 di storage sementara.
+// Perubahan lampiran lewat repo dicatat di removed/replaced.
+type attachmentEditFixture struct {
+	app      *fiber.App
+	store    *storage.Local
+	removed  []model_mongo.Attachment
+	replaced []model_mongo.Attachment
+}
+
+func newAttachmentEditFixture(t *testing.T, status string) *attachmentEditFixture {
+	f := &attachmentEditFixture{store: storage.NewLocal(t.TempDir(), "/uploads")}
+	assert.NoError(t, f.store.Put(context.Background(), "att-1-sertifikat.pdf", bytes.NewReader(testPDF), int64(len(testPDF)), "application/pdf"))
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			return &model_mongo.AchievementMongo{ID: id, Attachments: []model_mongo.Attachment{
+				{ID: "att-1", FileName: "sertifikat.pdf", FileType: "application/pdf", Category: model_mongo.AttachmentCertificate, StorageKey: "att-1-sertifikat.pdf"},
+			}}, nil
+		},
+		RemoveAttachmentFunc: func(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) (bool, error) {
+			f.removed = append(f.removed, attachment)
+			return true, nil
+		},
+		ReplaceAttachmentFunc: func(ctx context.Context, id primitive.ObjectID, old model_mongo.Attachment, replacement model_mongo.Attachment) (bool, error) {
+			assert.Equal(t, "att-1", old.ID)
+			f.replaced = append(f.replaced, replacement)
+			return true, nil
+		},
+		Storage: f.store,
+	}
+	f.app = setupAchievementServiceTestApp(t, mockMongo, commentTestPG(status))
+	return f
+}
+
+func addAttachmentRequest(app *fiber.App, fileName string, content []byte) *http.Response {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", fileName)
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/attachments", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	resp, _ := app.Test(req)
+	return resp
+}
+
+func replaceAttachmentRequest(app *fiber.App, path, fileName string, content []byte) *http.Response {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("file", fileName)
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("PUT", path, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	resp, _ := app.Test(req)
+	return resp
+}
+
+func TestDeleteAttachment_RemovesMetadataAndFile(t *testing.T) {
+	f := newAttachmentEditFixture(t, "draft")
+
+	req := httptest.NewRequest("DELETE", "/achievements/ref-1/attachments/att-1", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	resp, _ := f.app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	if assert.Len(t, f.removed, 1) {
+		assert.Equal(t, "att-1", f.removed[0].ID)
+	}
+	_, err := f.store.Stat(context.Background(), "att-1-sertifikat.pdf")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestReplaceAttachment_KeepsIDAndSwapsFile(t *testing.T) {
+	f := newAttachmentEditFixture(t, "rejected")
+	jpeg := []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00")
+
+	resp := replaceAttachmentRequest(f.app, "/achievements/ref-1/attachments/att-1", "Sertifikat Baru.jpg", jpeg)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	if assert.Len(t, f.replaced, 1) {
+		replacement := f.replaced[0]
+		assert.Equal(t, "att-1", replacement.ID)
+		assert.Equal(t, "/api/v1/achievements/ref-1/attachments/att-1", replacement.FileUrl)
+		assert.Equal(t, "image/jpeg", replacement.FileType)
+		// Kategori lama dipakai bila tidak dikirim
+		assert.Equal(t, model_mongo.AttachmentCertificate, replacement.Category)
+		assert.NotEqual(t, "att-1-sertifikat.pdf", replacement.StorageKey)
+
+		obj, err := f.store.Stat(context.Background(), replacement.StorageKey)
+		if assert.NoError(t, err) {
+			assert.Equal(t, int64(len(jpeg)), obj.Size)
+		}
+	}
+	_, err := f.store.Stat(context.Background(), "att-1-sertifikat.pdf")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	// File pengganti yang tidak lolos validasi tidak mengubah apa pun
+	resp = replaceAttachmentRequest(f.app, "/achievements/ref-1/attachments/att-1", "setup.exe", []byte("MZ\x90\x00"))
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	assert.Len(t, f.replaced, 1)
+}
+
+// Lampiran yang FileUrl-nya menunjuk file lain tanpa storage key dari server: metadata boleh dihapus/diganti,
+// tetapi file yang ditunjuk tidak pernah ikut dihapus
+func TestAttachmentEdits_NeverDeleteForgedFileURL(t *testing.T) {
+	store := storage.NewLocal(t.TempDir(), "/uploads")
+	assert.NoError(t, store.Put(context.Background(), "1700000000-milik-orang-lain.pdf", bytes.NewReader(testPDF), int64(len(testPDF)), "application/pdf"))
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			return &model_mongo.AchievementMongo{ID: id, Attachments: []model_mongo.Attachment{
+				{ID: "palsu", FileName: "bukti.pdf", FileUrl: "http://localhost:3000/uploads/1700000000-milik-orang-lain.pdf"},
+			}}, nil
+		},
+		Storage: store,
+	}
+	app := setupAchievementServiceTestApp(t, mockMongo, commentTestPG("revision_requested"))
+
+	resp := replaceAttachmentRequest(app, "/achievements/ref-1/attachments/palsu", "baru.pdf", testPDF)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	req := httptest.NewRequest("DELETE", "/achievements/ref-1/attachments/palsu", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err := store.Stat(context.Background(), "1700000000-milik-orang-lain.pdf")
+	assert.NoError(t, err)
+}
+
+func TestAttachmentEdits_OnlyOwnerWhileEditable(t *testing.T) {
+	submitted := newAttachmentEditFixture(t, "submitted")
+	req := httptest.NewRequest("DELETE", "/achievements/ref-1/attachments/att-1", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	resp, _ := submitted.app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = replaceAttachmentRequest(submitted.app, "/achievements/ref-1/attachments/att-1", "baru.pdf", testPDF)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp = addAttachmentRequest(submitted.app, "tambahan.pdf", testPDF)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Empty(t, submitted.removed)
+	assert.Empty(t, submitted.replaced)
+	_, err := submitted.store.Stat(context.Background(), "att-1-sertifikat.pdf")
+	assert.NoError(t, err)
+	// Lampiran yang ditolak tidak meninggalkan file di storage
+	entries, err := os.ReadDir(submitted.store.Dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	draft := newAttachmentEditFixture(t, "draft")
+	req = httptest.NewRequest("DELETE", "/achievements/ref-1/attachments/tidak-ada", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	resp, _ = draft.app.Test(req)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	req = httptest.NewRequest("DELETE", "/achievements/ref-1/attachments/att-1", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	resp, _ = draft.app.Test(req)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	mockPg := commentTestPG("draft")
+	mockPg.FindStudentIdByUserIDFunc = func(userID string) (string, error) { return "stu-lain", nil }
+	otherStudent := setupAchievementServiceTestApp(t, &MockAchievementMongoRepo{}, mockPg)
+	req = httptest.NewRequest("DELETE", "/achievements/ref-1/attachments/att-1", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	resp, _ = otherStudent.Test(req)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Empty(t, draft.removed)
+}
+
+// commentTestPG: Prestasi milik stu-123 (dosen wali lec-1) yang sedang diverifikasi
+func commentTestPG(status string) *MockAchievementPGRepo {
+	return &MockAchievementPGRepo{
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-1", StudentID: "stu-123", Status: model_postgre.AchievementStatus(status), MongoAchievementID: "64b0f1a2e4b0a1a2b3c4d5e6"}, nil
+		},
+		FindStudentIdByUserIDFunc:  func(userID string) (string, error) { return "stu-123", nil },
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-1", nil },
+		GetAdviseeStudentIDsFunc:   func(lecturerID string) ([]string, error) { return []string{"stu-123"}, nil },
+	}
+}
+
+func postComment(app *fiber.App, role, userID string, payload map[string]interface{}) *http.Response {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/achievements/ref-1/comments", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", role)
+	req.Header.Set("X-Test-ID", userID)
+	resp, _ := app.Test(req)
+	return resp
+}
+
+func TestAddComment_AdvisorAndOwnerThread(t *testing.T) {
+	comments := &MockCommentRepo{}
+	app := setupAchievementServiceTestAppWithComments(t, &MockAchievementMongoRepo{}, commentTestPG("submitted"), comments)
+
+	resp := postComment(app, "Dosen Wali", "user-dosen", map[string]interface{}{"message": "Mohon lampirkan foto penyerahan hadiah"})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	if !assert.Len(t, comments.Comments, 1) { return }
+	root := comments.Comments[0]
+	assert.Equal(t, "Dosen Wali", root.AuthorRole)
+
+	resp = postComment(app, "Mahasiswa", "user-mhs", map[string]interface{}{"message": "Sudah saya unggah", "parent_id": root.ID.Hex()})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// Balasan atas balasan tetap menempel ke komentar utama
+	resp = postComment(app, "Dosen Wali", "user-dosen", map[string]interface{}{"message": "Terima kasih", "parent_id": comments.Comments[1].ID.Hex()})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, root.ID, *comments.Comments[2].ParentID)
+
+	req := httptest.NewRequest("GET", "/achievements/ref-1/comments", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data []model_mongo.CommentThread `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if assert.Len(t, result.Data, 1) {
+		assert.Len(t, result.Data[0].Replies, 2)
+	}
+}
+
+func TestAddComment_Validation(t *testing.T) {
+	otherAchievement := primitive.NewObjectID()
+	foreignParent := model_mongo.AchievementComment{ID: primitive.NewObjectID(), AchievementID: otherAchievement, Message: "lain"}
+	comments := &MockCommentRepo{Comments: []model_mongo.AchievementComment{foreignParent}}
+	app := setupAchievementServiceTestAppWithComments(t, &MockAchievementMongoRepo{}, commentTestPG("submitted"), comments)
+
+	resp := postComment(app, "Mahasiswa", "user-mhs", map[string]interface{}{"message": "   "})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = postComment(app, "Mahasiswa", "user-mhs", map[string]interface{}{"message": strings.Repeat("a", model_mongo.MaxCommentLength+1)})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = postComment(app, "Mahasiswa", "user-mhs", map[string]interface{}{"message": "balas", "parent_id": foreignParent.ID.Hex()})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Len(t, comments.Comments, 1)
+}
+
+func TestAddComment_WithAttachment(t *testing.T) {
+	comments := &MockCommentRepo{}
+	app := setupAchievementServiceTestAppWithComments(t, &MockAchievementMongoRepo{}, commentTestPG("submitted"), comments)
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, _ := writer.CreateFormFile("files", "foto.jpg")
+	part.Write([]byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"))
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/comments", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	if assert.Len(t, comments.Comments, 1) && assert.Len(t, comments.Comments[0].Attachments, 1) {
+		// URL lampiran komentar menunjuk endpoint unduh yang memeriksa akses, bukan file statis
+		comment := comments.Comments[0]
+		assert.Equal(t, "/api/v1/achievements/ref-1/comments/"+comment.ID.Hex()+"/attachments/"+comment.Attachments[0].ID, comment.Attachments[0].FileUrl)
+	}
+}
+
+func TestDownloadCommentAttachment_RequiresReadAccess(t *testing.T) {
+	mongoID, _ := primitive.ObjectIDFromHex("64b0f1a2e4b0a1a2b3c4d5e6")
+	store := storage.NewLocal(t.TempDir(), "/uploads")
+	assert.NoError(t, store.Put(context.Background(), "att-1-revisi.pdf", bytes.NewReader(testPDF), int64(len(testPDF)), "application/pdf"))
+	comment := model_mongo.AchievementComment{ID: primitive.NewObjectID(), AchievementID: mongoID, Attachments: []model_mongo.Attachment{
+		{ID: "att-1", FileName: "revisi.pdf", FileType: "application/pdf", StorageKey: "att-1-revisi.pdf"},
+	}}
+	otherAchievement := model_mongo.AchievementComment{ID: primitive.NewObjectID(), AchievementID: primitive.NewObjectID(), Attachments: comment.Attachments}
+	comments := &MockCommentRepo{Comments: []model_mongo.AchievementComment{comment, otherAchievement}}
+	app := setupAchievementServiceTestAppWithComments(t, &MockAchievementMongoRepo{Storage: store}, commentTestPG("submitted"), comments)
+
+	path := "/achievements/ref-1/comments/" + comment.ID.Hex() + "/attachments/att-1"
+	resp := downloadAttachment(app, "Dosen Wali", path, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, testPDF, body)
+	assert.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+
+	// Dosen wali mahasiswa lain tidak berhak melihat prestasi ini
+	mockPg := commentTestPG("submitted")
+	mockPg.GetAdviseeStudentIDsFunc = func(lecturerID string) ([]string, error) { return []string{"stu-999"}, nil }
+	otherAdvisor := setupAchievementServiceTestAppWithComments(t, &MockAchievementMongoRepo{Storage: store}, mockPg, comments)
+	assert.Equal(t, http.StatusForbidden, downloadAttachment(otherAdvisor, "Dosen Wali", path, "").StatusCode)
+
+	// Komentar prestasi lain tidak bisa diambil lewat prestasi yang boleh diakses
+	assert.Equal(t, http.StatusNotFound, downloadAttachment(app, "Dosen Wali", "/achievements/ref-1/comments/"+otherAchievement.ID.Hex()+"/attachments/att-1", "").StatusCode)
+	assert.Equal(t, http.StatusNotFound, downloadAttachment(app, "Dosen Wali", "/achievements/ref-1/comments/"+comment.ID.Hex()+"/attachments/tidak-ada", "").StatusCode)
+	assert.Equal(t, http.StatusNotFound, downloadAttachment(app, "Dosen Wali", "/achievements/ref-1/comments/bukan-id/attachments/att-1", "").StatusCode)
+}
+
+// Komentar dengan beberapa lampiran berukuran maksimal tidak ditolak batas body server
+func TestAddComment_SeveralMaxSizeAttachments(t *testing.T) {
+	comments := &MockCommentRepo{}
+	uploads := upload.NewValidator([]upload.Rule{{MIME: "application/pdf", Label: "PDF", Extensions: []string{".pdf"}, MaxSize: 1 << 20}})
+	app := setupAchievementServiceTestAppWithComments(t, &MockAchievementMongoRepo{Uploads: uploads}, commentTestPG("submitted"), comments)
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for _, name := range []string{"bukti-1.pdf", "bukti-2.pdf", "bukti-3.pdf"} {
+		part, _ := writer.CreateFormFile("files", name)
+		part.Write(append(testPDF, make([]byte, 1<<20-len(testPDF))...))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/comments", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, err := app.Test(req, -1)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+	if assert.Len(t, comments.Comments, 1) {
+		assert.Len(t, comments.Comments[0].Attachments, 3)
+	}
+}
+
+// File yang sudah tersimpan dihapus lagi bila komentarnya gagal disimpan
+func TestAddComment_FailedInsertRemovesUploadedFiles(t *testing.T) {
+	dir := t.TempDir()
+	comments := &MockCommentRepo{CreateErr: errors.New("mongo down")}
+	app := setupAchievementServiceTestAppWithComments(t, &MockAchievementMongoRepo{Storage: storage.NewLocal(dir, "/uploads")}, commentTestPG("submitted"), comments)
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for _, name := range []string{"foto-1.jpg", "foto-2.jpg"} {
+		part, _ := writer.CreateFormFile("files", name)
+		part.Write([]byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/achievements/ref-1/comments", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestAddComment_NotAdvisee_Forbidden(t *testing.T) {
+	comments := &MockCommentRepo{}
+	mockPg := commentTestPG("submitted")
+	mockPg.GetAdviseeStudentIDsFunc = func(lecturerID string) ([]string, error) { return []string{"stu-999"}, nil }
+	app := setupAchievementServiceTestAppWithComments(t, &MockAchievementMongoRepo{}, mockPg, comments)
+
+	resp := postComment(app, "Dosen Wali", "user-dosen", map[string]interface{}{"message": "Halo"})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Empty(t, comments.Comments)
+}
+
+func TestAddComment_DeletedAchievement(t *testing.T) {
+	comments := &MockCommentRepo{}
+	app := setupAchievementServiceTestAppWithComments(t, &MockAchievementMongoRepo{}, commentTestPG("deleted"), comments)
+
+	resp := postComment(app, "Mahasiswa", "user-mhs", map[string]interface{}{"message": "Halo"})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestGetAchievementDetail_IncludesComments(t *testing.T) {
+	mongoID, _ := primitive.ObjectIDFromHex("64b0f1a2e4b0a1a2b3c4d5e6")
+	comments := &MockCommentRepo{Comments: []model_mongo.AchievementComment{{ID: primitive.NewObjectID(), AchievementID: mongoID, Message: "Cek ulang tanggal"}}}
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			return &model_mongo.AchievementMongo{ID: id, Title: "Juara 1"}, nil
+		},
+	}
+	app := setupAchievementServiceTestAppWithComments(t, mockMongo, commentTestPG("submitted"), comments)
+
+	req := httptest.NewRequest("GET", "/achievements/ref-1", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data struct {
+			Comments []model_mongo.CommentThread `json:"comments"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	if assert.Len(t, result.Data.Comments, 1) {
+		assert.Equal(t, "Cek ulang tanggal", result.Data.Comments[0].Message)
+	}
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	repo_postgre "github.com/safrizal-hk/uas-gofiber/app/repository/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/service"
+)
+
+// MOCK REPOSITORY
+type MockDelegationRepo struct {
+	CreateDelegationFunc  func(req *model_postgre.DelegationRequest, actorID string) (*model_postgre.VerificationDelegation, error)
+	GetDelegationByIDFunc func(id string) (*model_postgre.VerificationDelegation, error)
+	ListDelegationsFunc   func(lecturerID string) ([]model_postgre.VerificationDelegation, error)
+	RevokeDelegationFunc  func(id string) (*model_postgre.VerificationDelegation, error)
+}
+
+func (m *MockDelegationRepo) CreateDelegation(req *model_postgre.DelegationRequest, actorID string) (*model_postgre.VerificationDelegation, error) {
+	if m.CreateDelegationFunc == nil { return &model_postgre.VerificationDelegation{}, nil }
+	return m.CreateDelegationFunc(req, actorID)
+}
+func (m *MockDelegationRepo) GetDelegationByID(id string) (*model_postgre.VerificationDelegation, error) {
+	if m.GetDelegationByIDFunc == nil { return nil, sql.ErrNoRows }
+	return m.GetDelegationByIDFunc(id)
+}
+func (m *MockDelegationRepo) ListDelegations(lecturerID string) ([]model_postgre.VerificationDelegation, error) {
+	if m.ListDelegationsFunc == nil { return nil, nil }
+	return m.ListDelegationsFunc(lecturerID)
+}
+func (m *MockDelegationRepo) RevokeDelegation(id string) (*model_postgre.VerificationDelegation, error) {
+	if m.RevokeDelegationFunc == nil { return nil, sql.ErrNoRows }
+	return m.RevokeDelegationFunc(id)
+}
+
+// SETUP HELPER
+func setupDelegationServiceTestApp(mockRepo *MockDelegationRepo, now time.Time) *fiber.App {
+	app := fiber.New()
+	mockPg := &MockAchievementPGRepo{
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-" + userID, nil },
+	}
+	svc := service.NewDelegationService(mockRepo, mockPg)
+	svc.Now = func() time.Time { return now }
+
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userProfile", model_postgre.UserProfile{ID: c.Get("X-Test-ID"), Role: c.Get("X-Test-Role")})
+		return c.Next()
+	})
+	app.Get("/delegations", svc.ListDelegations)
+	app.Post("/delegations", svc.CreateDelegation)
+	app.Delete("/delegations/:id", svc.RevokeDelegation)
+	return app
+}
+
+func postDelegation(app *fiber.App, role, userID string, body map[string]interface{}) *http.Response {
+	raw, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/delegations", bytes.NewReader(raw))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", role)
+	req.Header.Set("X-Test-ID", userID)
+	resp, _ := app.Test(req)
+	return resp
+}
+
+func TestCreateDelegation_LecturerDelegatesOwnAdvisees(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	var got *model_postgre.DelegationRequest
+	mockRepo := &MockDelegationRepo{
+		CreateDelegationFunc: func(req *model_postgre.DelegationRequest, actorID string) (*model_postgre.VerificationDelegation, error) {
+			got = req
+			return &model_postgre.VerificationDelegation{ID: "del-1", DelegatorID: req.DelegatorID, DelegateID: req.DelegateID}, nil
+		},
+	}
+	app := setupDelegationServiceTestApp(mockRepo, now)
+
+	resp := postDelegation(app, "Dosen Wali", "budi", map[string]interface{}{
+		"delegate_id": "lec-sari", "starts_at": now, "ends_at": now.AddDate(0, 0, 14), "reason": "Cuti",
+	})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "lec-budi", got.DelegatorID)
+
+	// Dosen tidak boleh mendelegasikan bimbingan dosen lain
+	resp = postDelegation(app, "Dosen Wali", "budi", map[string]interface{}{
+		"delegator_id": "lec-lain", "delegate_id": "lec-sari", "starts_at": now, "ends_at": now.AddDate(0, 0, 14),
+	})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestCreateDelegation_Validation(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo := &MockDelegationRepo{
+		CreateDelegationFunc: func(req *model_postgre.DelegationRequest, actorID string) (*model_postgre.VerificationDelegation, error) {
+			return nil, repo_postgre.ErrDelegationOverlap
+		},
+	}
+	app := setupDelegationServiceTestApp(mockRepo, now)
+
+	cases := []struct {
+		name string
+		body map[string]interface{}
+		want int
+	}{
+		{"admin tanpa delegator", map[string]interface{}{"delegate_id": "lec-sari", "starts_at": now, "ends_at": now.AddDate(0, 0, 1)}, http.StatusBadRequest},
+		{"delegate sama", map[string]interface{}{"delegator_id": "lec-sari", "delegate_id": "lec-sari", "starts_at": now, "ends_at": now.AddDate(0, 0, 1)}, http.StatusBadRequest},
+		{"jendela terbalik", map[string]interface{}{"delegator_id": "lec-budi", "delegate_id": "lec-sari", "starts_at": now, "ends_at": now.AddDate(0, 0, -1)}, http.StatusBadRequest},
+		{"terlalu panjang", map[string]interface{}{"delegator_id": "lec-budi", "delegate_id": "lec-sari", "starts_at": now, "ends_at": now.AddDate(1, 0, 0)}, http.StatusBadRequest},
+		{"tumpang tindih", map[string]interface{}{"delegator_id": "lec-budi", "delegate_id": "lec-sari", "starts_at": now, "ends_at": now.AddDate(0, 0, 7)}, http.StatusConflict},
+	}
+	for _, tc := range cases {
+		resp := postDelegation(app, "Admin", "admin", tc.body)
+		assert.Equal(t, tc.want, resp.StatusCode, tc.name)
+	}
+}
+
+func TestRevokeDelegation_OnlyDelegatorOrAdmin(t *testing.T) {
+	revoked := false
+	mockRepo := &MockDelegationRepo{
+		GetDelegationByIDFunc: func(id string) (*model_postgre.VerificationDelegation, error) {
+			return &model_postgre.VerificationDelegation{ID: id, DelegatorID: "lec-budi", DelegateID: "lec-sari"}, nil
+		},
+		RevokeDelegationFunc: func(id string) (*model_postgre.VerificationDelegation, error) {
+			revoked = true
+			now := time.Now()
+			return &model_postgre.VerificationDelegation{ID: id, RevokedAt: &now}, nil
+		},
+	}
+	app := setupDelegationServiceTestApp(mockRepo, time.Now())
+
+	revoke := func(userID string) int {
+		req := httptest.NewRequest("DELETE", "/delegations/del-1", nil)
+		req.Header.Set("X-Test-Role", "Dosen Wali")
+		req.Header.Set("X-Test-ID", userID)
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+	// Delegate tidak boleh mencabut delegasi yang diterimanya
+	assert.Equal(t, http.StatusForbidden, revoke("sari"))
+	assert.False(t, revoked)
+	assert.Equal(t, http.StatusOK, revoke("budi"))
+	assert.True(t, revoked)
+}
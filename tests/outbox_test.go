@@ -0,0 +1,284 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/safrizal-hk/uas-gofiber/app/job"
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/outbox"
+	"github.com/safrizal-hk/uas-gofiber/app/workflow"
+)
+
+// MockOutboxRepo: Outbox in-memory. Seperti repository asli, hanya event tertua yang belum selesai
+// per dokumen yang bisa diklaim.
+type MockOutboxRepo struct {
+	Events []model_postgre.OutboxEvent
+}
+
+// record mensimulasikan insertOutboxEvent di dalam transaksi referensi
+func (m *MockOutboxRepo) record(event *model_postgre.OutboxEvent) {
+	if m == nil || event == nil { return }
+	event.ID = int64(len(m.Events) + 1)
+	event.NextAttemptAt = time.Now()
+	event.CreatedAt = time.Now()
+	m.Events = append(m.Events, *event)
+}
+
+func (m *MockOutboxRepo) Enqueue(event *model_postgre.OutboxEvent) error {
+	m.record(event)
+	return nil
+}
+func (m *MockOutboxRepo) ClaimDue(aggregateID string, lease time.Duration, limit int) ([]model_postgre.OutboxEvent, error) {
+	claimed := []model_postgre.OutboxEvent{}
+	head := map[string]bool{}
+	for _, e := range m.Events {
+		if e.ProcessedAt != nil || head[e.AggregateID] { continue }
+		head[e.AggregateID] = true
+		if aggregateID != "" && e.AggregateID != aggregateID { continue }
+		if e.NextAttemptAt.After(time.Now()) || len(claimed) == limit { continue }
+		claimed = append(claimed, e)
+	}
+	return claimed, nil
+}
+func (m *MockOutboxRepo) MarkProcessed(id int64) error {
+	now := time.Now()
+	m.Events[id-1].ProcessedAt = &now
+	m.Events[id-1].Attempts++
+	return nil
+}
+func (m *MockOutboxRepo) MarkFailed(id int64, errMsg string, retryIn time.Duration) error {
+	m.Events[id-1].Attempts++
+	m.Events[id-1].LastError = &errMsg
+	m.Events[id-1].NextAttemptAt = time.Now().Add(retryIn)
+	return nil
+}
+func (m *MockOutboxRepo) CountPending(aggregateID string) (int, error) {
+	n := 0
+	for _, e := range m.Events {
+		if e.ProcessedAt == nil && (aggregateID == "" || e.AggregateID == aggregateID) { n++ }
+	}
+	return n, nil
+}
+func (m *MockOutboxRepo) PendingAggregateIDs() ([]string, error) {
+	ids := []string{}
+	seen := map[string]bool{}
+	for _, e := range m.Events {
+		if e.ProcessedAt != nil || seen[e.AggregateID] { continue }
+		seen[e.AggregateID] = true
+		ids = append(ids, e.AggregateID)
+	}
+	return ids, nil
+}
+
+func TestOutboxDispatch_CreateAlreadyAppliedIsIdempotent(t *testing.T) {
+	doc := &model_mongo.AchievementMongo{ID: primitive.NewObjectID(), StudentID: "stu-123", Title: "Juara 1", Points: 50}
+	event, err := outbox.NewCreateEvent(doc)
+	assert.NoError(t, err)
+	repo := &MockOutboxRepo{}
+	repo.record(event)
+
+	var inserted *model_mongo.AchievementMongo
+	mockMongo := &MockAchievementMongoRepo{
+		CreateFunc: func(ctx context.Context, achievement *model_mongo.AchievementMongo) (*model_mongo.AchievementMongo, error) {
+			inserted = achievement
+			// Percobaan sebelumnya sudah menulis dokumen tetapi crash sebelum event ditandai selesai
+			return nil, mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key"}}}
+		},
+	}
+
+	synced, err := outbox.NewDispatcher(repo, mockMongo, nil).Dispatch(context.Background(), doc.ID.Hex())
+	assert.NoError(t, err)
+	assert.True(t, synced)
+	assert.Equal(t, doc.ID, inserted.ID)
+	assert.Equal(t, "Juara 1", inserted.Title)
+	assert.Equal(t, 50, inserted.Points)
+	assert.NotNil(t, repo.Events[0].ProcessedAt)
+}
+
+func TestOutboxWorker_RetriesFailedEventsInOrder(t *testing.T) {
+	mongoID := primitive.NewObjectID()
+	repo := &MockOutboxRepo{}
+	repo.record(&model_postgre.OutboxEvent{AggregateID: mongoID.Hex(), Operation: model_postgre.OutboxSoftDelete})
+	repo.record(&model_postgre.OutboxEvent{AggregateID: mongoID.Hex(), Operation: model_postgre.OutboxRestore})
+
+	mongoDown := true
+	var applied []string
+	mockMongo := &MockAchievementMongoRepo{
+		SoftDeleteFunc: func(ctx context.Context, id primitive.ObjectID) error {
+			if mongoDown { return errors.New("mongo down") }
+			applied = append(applied, "soft_delete")
+			return nil
+		},
+		RestoreFunc: func(ctx context.Context, id primitive.ObjectID) error {
+			applied = append(applied, "restore")
+			return nil
+		},
+	}
+	worker := job.NewOutboxWorker(outbox.NewDispatcher(repo, mockMongo, nil))
+
+	// Event pertama gagal: dijadwalkan ulang dengan backoff, event berikutnya untuk dokumen yang sama menunggu
+	result, err := worker.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, outbox.Result{Processed: 0, Failed: 1}, result)
+	assert.Equal(t, 1, repo.Events[0].Attempts)
+	assert.WithinDuration(t, time.Now().Add(outbox.BaseBackoff), repo.Events[0].NextAttemptAt, time.Second)
+	assert.Empty(t, applied)
+
+	// Sebelum jatuh tempo tidak dicoba ulang
+	result, _ = worker.RunOnce(context.Background())
+	assert.Equal(t, outbox.Result{}, result)
+
+	mongoDown = false
+	repo.Events[0].NextAttemptAt = time.Now().Add(-time.Second)
+	result, err = worker.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, outbox.Result{Processed: 2}, result)
+	assert.Equal(t, []string{"soft_delete", "restore"}, applied)
+	pending, _ := repo.CountPending("")
+	assert.Equal(t, 0, pending)
+}
+
+func TestOutboxBackoff_Capped(t *testing.T) {
+	assert.Equal(t, outbox.BaseBackoff, outbox.Backoff(0))
+	assert.Equal(t, 4*outbox.BaseBackoff, outbox.Backoff(2))
+	assert.Equal(t, outbox.MaxBackoff, outbox.Backoff(50))
+}
+
+func TestSubmitPrestasi_MongoDown_ReferenceCommittedWithPendingOutbox(t *testing.T) {
+	mockPg := &MockAchievementPGRepo{
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		CreateReferenceFunc: func(ref *model_postgre.AchievementReference, actorID string) (*model_postgre.AchievementReference, error) {
+			ref.ID = "ref-new"
+			return ref, nil
+		},
+	}
+	mongoDown := true
+	var saved *model_mongo.AchievementMongo
+	mockMongo := &MockAchievementMongoRepo{
+		CreateFunc: func(ctx context.Context, achievement *model_mongo.AchievementMongo) (*model_mongo.AchievementMongo, error) {
+			if mongoDown { return nil, errors.New("mongo down") }
+			saved = achievement
+			return achievement, nil
+		},
+	}
+	revisions := &MockRevisionRepo{}
+	engine, _ := workflow.NewEngine(workflow.DefaultDefinitions())
+	app := setupAchievementServiceTestAppWithRevisions(t, mockMongo, mockPg, revisions, engine)
+
+	body, _ := json.Marshal(validCompetitionInput("Juara 1 Lomba Coding"))
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	var result struct {
+		MongoID string `json:"mongo_id"`
+		Synced  bool   `json:"synced"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.False(t, result.Synced)
+	assert.Empty(t, revisions.Revisions)
+	if assert.Len(t, mockPg.Outbox.Events, 1) {
+		assert.Equal(t, result.MongoID, mockPg.Outbox.Events[0].AggregateID)
+		assert.Equal(t, model_postgre.OutboxCreateAchievement, mockPg.Outbox.Events[0].Operation)
+	}
+
+	// Worker menyelesaikan penulisan dengan ID yang sama seperti referensi PostgreSQL
+	mongoDown = false
+	mockPg.Outbox.Events[0].NextAttemptAt = time.Now().Add(-time.Second)
+	worker := job.NewOutboxWorker(outbox.NewDispatcher(mockPg.Outbox, mockMongo, nil))
+	outcome, err := worker.RunOnce(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, outcome.Processed)
+	assert.Equal(t, result.MongoID, saved.ID.Hex())
+	assert.Equal(t, "stu-123", saved.StudentID)
+}
+
+func TestUpdatePrestasi_PendingCreate_Conflict(t *testing.T) {
+	mongoDown := true
+	var mongoID string
+	mockPg := &MockAchievementPGRepo{
+		FindStudentIdByUserIDFunc: func(userID string) (string, error) { return "stu-123", nil },
+		CreateReferenceFunc: func(ref *model_postgre.AchievementReference, actorID string) (*model_postgre.AchievementReference, error) {
+			ref.ID = "ref-new"
+			return ref, nil
+		},
+		GetReferenceByIDFunc: func(id string) (*model_postgre.AchievementReference, error) {
+			return &model_postgre.AchievementReference{ID: "ref-new", StudentID: "stu-123", Status: "draft", MongoAchievementID: mongoID}, nil
+		},
+	}
+	var created *model_mongo.AchievementMongo
+	updated, removed := false, false
+	mockMongo := &MockAchievementMongoRepo{
+		CreateFunc: func(ctx context.Context, achievement *model_mongo.AchievementMongo) (*model_mongo.AchievementMongo, error) {
+			if mongoDown { return nil, errors.New("mongo down") }
+			created = achievement
+			return achievement, nil
+		},
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			if created == nil { return nil, mongo.ErrNoDocuments }
+			return created, nil
+		},
+		UpdateFunc: func(ctx context.Context, id primitive.ObjectID, data *model_mongo.AchievementInput) error {
+			updated = true
+			return nil
+		},
+		RemoveAttachmentFunc: func(ctx context.Context, id primitive.ObjectID, attachment model_mongo.Attachment) (bool, error) {
+			removed = true
+			return true, nil
+		},
+	}
+	engine, _ := workflow.NewEngine(workflow.DefaultDefinitions())
+	app := setupAchievementServiceTestAppWithRevisions(t, mockMongo, mockPg, &MockRevisionRepo{}, engine)
+
+	send := func(method, path string, body []byte) *http.Response {
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Test-Role", "Mahasiswa")
+		req.Header.Set("X-Test-ID", "user-mhs")
+		resp, _ := app.Test(req)
+		return resp
+	}
+
+	body, _ := json.Marshal(validCompetitionInput("Juara 1 Lomba Coding"))
+	resp := send("POST", "/achievements", body)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	var result struct {
+		MongoID string `json:"mongo_id"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	mongoID = result.MongoID
+
+	// Event create masih menunggu backoff: penulisan langsung ke Mongo ditolak, bukan 500/hilang
+	edit, _ := json.Marshal(validCompetitionInput("Juara 1 Lomba Coding Nasional"))
+	resp = send("PUT", "/achievements/ref-new", edit)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	var conflict map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&conflict)
+	assert.Equal(t, map[string]interface{}{"message": "Prestasi masih disinkronkan, coba lagi", "code": "409"}, conflict)
+	assert.Equal(t, http.StatusConflict, send("DELETE", "/achievements/ref-new/attachments/att-1", nil).StatusCode)
+	assert.False(t, updated)
+	assert.False(t, removed)
+
+	// Setelah Mongo pulih, event create yang jatuh tempo diterapkan lebih dulu lalu update berjalan
+	mongoDown = false
+	mockPg.Outbox.Events[0].NextAttemptAt = time.Now().Add(-time.Second)
+	resp = send("PUT", "/achievements/ref-new", edit)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotNil(t, created)
+	assert.True(t, updated)
+}
@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/points"
+	"github.com/safrizal-hk/uas-gofiber/app/service"
+)
+
+// MOCK REPOSITORY
+type MockPointRuleRepo struct {
+	Rules          []model_postgre.PointRule
+	CreateRuleFunc func(input *model_postgre.PointRuleInput) (*model_postgre.PointRule, error)
+	DeleteRuleFunc func(id string) error
+}
+
+func (m *MockPointRuleRepo) GetAllRules() ([]model_postgre.PointRule, error) { return m.Rules, nil }
+func (m *MockPointRuleRepo) GetRuleByID(id string) (*model_postgre.PointRule, error) {
+	for i := range m.Rules {
+		if m.Rules[i].ID == id { return &m.Rules[i], nil }
+	}
+	return nil, sql.ErrNoRows
+}
+func (m *MockPointRuleRepo) CreateRule(input *model_postgre.PointRuleInput) (*model_postgre.PointRule, error) {
+	if m.CreateRuleFunc == nil { return &model_postgre.PointRule{ID: "rule-new"}, nil }
+	return m.CreateRuleFunc(input)
+}
+func (m *MockPointRuleRepo) UpdateRule(id string, input *model_postgre.PointRuleInput) (*model_postgre.PointRule, error) {
+	return &model_postgre.PointRule{ID: id}, nil
+}
+func (m *MockPointRuleRepo) DeleteRule(id string) error {
+	if m.DeleteRuleFunc == nil { return nil }
+	return m.DeleteRuleFunc(id)
+}
+
+func strPtr(s string) *string { return &s }
+
+func defaultTestPointRules() []model_postgre.PointRule {
+	return []model_postgre.PointRule{
+		{ID: "r-1", AchievementType: "competition", Level: strPtr("national"), Rank: strPtr("first"), Points: 75, TeamPercent: 60},
+		{ID: "r-2", AchievementType: "competition", Level: strPtr("national"), Points: 30, TeamPercent: 60},
+		{ID: "r-3", AchievementType: "competition", Points: 10, TeamPercent: 100},
+		{ID: "r-4", AchievementType: "publication", Points: 50, TeamPercent: 100},
+	}
+}
+
+// SETUP HELPER
+func setupPointRuleServiceTestApp(mockRules *MockPointRuleRepo, mockPg *MockAchievementPGRepo, mockMongo *MockAchievementMongoRepo) *fiber.App {
+	app := fiber.New()
+	if mockPg.Outbox == nil { mockPg.Outbox = &MockOutboxRepo{} }
+	if mockMongo.Views == nil { mockMongo.Views = &MockAchievementViewRepo{} }
+	svc := service.NewPointRuleService(mockRules, mockPg, mockMongo, mockPg.Outbox, mockMongo.Views)
+
+	app.Get("/point-rules", svc.ListPointRules)
+	app.Post("/point-rules", svc.CreatePointRule)
+	app.Post("/point-rules/recalculate", svc.RecalculatePoints)
+	app.Delete("/point-rules/:id", svc.DeletePointRule)
+
+	return app
+}
+
+// UNIT TESTS
+func TestCalculatePoints_MostSpecificRuleAndTeam(t *testing.T) {
+	rules := defaultTestPointRules()
+
+	result := points.Calculate(rules, "competition", map[string]interface{}{"competitionLevel": "national", "rank": "first"})
+	assert.Equal(t, 75, result.Points)
+
+	result = points.Calculate(rules, "competition", map[string]interface{}{"competitionLevel": "national", "rank": "finalist"})
+	assert.Equal(t, 30, result.Points)
+
+	result = points.Calculate(rules, "competition", map[string]interface{}{"competitionLevel": "local", "rank": "first"})
+	assert.Equal(t, 10, result.Points)
+
+	// Tim 3 orang: 75 * 60%
+	result = points.Calculate(rules, "competition", map[string]interface{}{"competitionLevel": "national", "rank": "first", "teamSize": float64(3)})
+	assert.Equal(t, 45, result.Points)
+
+	result = points.Calculate(rules, "organization", nil)
+	assert.Equal(t, 0, result.Points)
+}
+
+func TestCreatePointRule_InvalidType(t *testing.T) {
+	app := setupPointRuleServiceTestApp(&MockPointRuleRepo{}, &MockAchievementPGRepo{}, &MockAchievementMongoRepo{})
+
+	body, _ := json.Marshal(model_postgre.PointRuleInput{AchievementType: "hackathon", Points: 10})
+	req := httptest.NewRequest("POST", "/point-rules", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestDeletePointRule_NotFound(t *testing.T) {
+	mockRules := &MockPointRuleRepo{DeleteRuleFunc: func(id string) error { return sql.ErrNoRows }}
+	app := setupPointRuleServiceTestApp(mockRules, &MockAchievementPGRepo{}, &MockAchievementMongoRepo{})
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/point-rules/rule-x", nil))
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestRecalculatePoints_ReportsDeltasAndSkipsVerified(t *testing.T) {
+	draftOID, verifiedOID := primitive.NewObjectID(), primitive.NewObjectID()
+	var requested []primitive.ObjectID
+	updated := map[string]int{}
+
+	mockPg := &MockAchievementPGRepo{
+		GetAllAchievementReferencesFunc: func() ([]model_postgre.AchievementReference, error) {
+			return []model_postgre.AchievementReference{
+				{ID: "ref-draft", Status: "draft", MongoAchievementID: draftOID.Hex()},
+				{ID: "ref-verified", Status: "verified", MongoAchievementID: verifiedOID.Hex()},
+			}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailsByIDsFunc: func(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error) {
+			requested = ids
+			return []model_mongo.AchievementMongo{
+				{ID: draftOID, Title: "Juara", AchievementType: "competition", Points: 20,
+					Details: map[string]interface{}{"competitionLevel": "national", "rank": "first"}},
+			}, nil
+		},
+		UpdatePointsFunc: func(ctx context.Context, id primitive.ObjectID, points int) (bool, error) {
+			updated[id.Hex()] = points
+			return true, nil
+		},
+	}
+	app := setupPointRuleServiceTestApp(&MockPointRuleRepo{Rules: defaultTestPointRules()}, mockPg, mockMongo)
+
+	resp, _ := app.Test(httptest.NewRequest("POST", "/point-rules/recalculate", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result struct {
+		Data struct {
+			Deltas []model_postgre.PointDelta `json:"deltas"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+
+	assert.Equal(t, []primitive.ObjectID{draftOID}, requested)
+	assert.Len(t, result.Data.Deltas, 1)
+	assert.Equal(t, 55, result.Data.Deltas[0].Delta)
+	assert.Equal(t, 75, updated[draftOID.Hex()])
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/outbox"
+	"github.com/safrizal-hk/uas-gofiber/app/readmodel"
+)
+
+// MockAchievementViewRepo: Read model in-memory (key = ID referensi). List/Search lewat func field.
+type MockAchievementViewRepo struct {
+	Views      map[string]model_mongo.AchievementView
+	ReplaceErr error
+
+	ListFunc   func(filter model_mongo.AchievementViewFilter) ([]model_mongo.AchievementView, int, error)
+	SearchFunc func(query string, filter model_mongo.AchievementViewFilter, limit int) ([]model_mongo.AchievementViewHit, error)
+}
+
+func (m *MockAchievementViewRepo) ReplaceForAchievement(ctx context.Context, mongoID string, views []model_mongo.AchievementView) error {
+	if m.ReplaceErr != nil { return m.ReplaceErr }
+	if m.Views == nil { m.Views = map[string]model_mongo.AchievementView{} }
+	for id, v := range m.Views {
+		if v.MongoAchievementID == mongoID { delete(m.Views, id) }
+	}
+	for _, v := range views {
+		m.Views[v.ID] = v
+	}
+	return nil
+}
+func (m *MockAchievementViewRepo) ReplaceAll(ctx context.Context, views []model_mongo.AchievementView) error {
+	if m.ReplaceErr != nil { return m.ReplaceErr }
+	m.Views = map[string]model_mongo.AchievementView{}
+	for _, v := range views {
+		m.Views[v.ID] = v
+	}
+	return nil
+}
+func (m *MockAchievementViewRepo) IsEmpty(ctx context.Context) (bool, error) {
+	return len(m.Views) == 0, nil
+}
+func (m *MockAchievementViewRepo) List(ctx context.Context, filter model_mongo.AchievementViewFilter) ([]model_mongo.AchievementView, int, error) {
+	if m.ListFunc == nil { return []model_mongo.AchievementView{}, 0, nil }
+	return m.ListFunc(filter)
+}
+func (m *MockAchievementViewRepo) Search(ctx context.Context, query string, filter model_mongo.AchievementViewFilter, limit int) ([]model_mongo.AchievementViewHit, error) {
+	if m.SearchFunc == nil { return []model_mongo.AchievementViewHit{}, nil }
+	return m.SearchFunc(query, filter, limit)
+}
+
+func TestProjectorRefresh_TeamAchievementProjectsEveryMember(t *testing.T) {
+	oid := primitive.NewObjectID()
+	mockPg := &MockAchievementPGRepo{
+		GetReferencesByMongoIDFunc: func(mongoID string) ([]model_postgre.AchievementReference, error) {
+			return []model_postgre.AchievementReference{
+				{ID: "ref-owner", MongoAchievementID: mongoID, StudentID: "stu-owner", Status: model_postgre.StatusSubmitted},
+				{ID: "ref-member", MongoAchievementID: mongoID, StudentID: "stu-member", Status: model_postgre.StatusSubmitted, CoMember: true},
+			}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			return &model_mongo.AchievementMongo{ID: id, Title: "Juara 1 Gemastik", AchievementType: "competition", Points: 40,
+				Details: map[string]interface{}{"competitionLevel": "national"}}, nil
+		},
+	}
+	views := &MockAchievementViewRepo{Views: map[string]model_mongo.AchievementView{
+		"ref-stale": {ID: "ref-stale", MongoAchievementID: oid.Hex()},
+	}}
+
+	err := readmodel.NewProjector(mockPg, mockMongo, views).Refresh(context.Background(), oid.Hex())
+	assert.NoError(t, err)
+	assert.Len(t, views.Views, 2)
+	member := views.Views["ref-member"]
+	assert.Equal(t, "stu-member", member.StudentID)
+	assert.True(t, member.CoMember)
+	assert.Equal(t, "Juara 1 Gemastik", member.Title)
+	assert.Equal(t, "national", member.CompetitionLevel)
+	assert.Equal(t, 40, member.Points)
+	assert.Equal(t, []string{}, member.Tags)
+	assert.False(t, member.DetailMissing)
+}
+
+func TestProjectorRefresh_MissingDocumentMarksDetailMissing(t *testing.T) {
+	oid := primitive.NewObjectID()
+	mockPg := &MockAchievementPGRepo{
+		GetReferencesByMongoIDFunc: func(mongoID string) ([]model_postgre.AchievementReference, error) {
+			return []model_postgre.AchievementReference{{ID: "ref-1", MongoAchievementID: mongoID, StudentID: "stu-123", Status: model_postgre.StatusDraft}}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailByIDFunc: func(ctx context.Context, id primitive.ObjectID) (*model_mongo.AchievementMongo, error) {
+			return nil, mongo.ErrNoDocuments
+		},
+	}
+	views := &MockAchievementViewRepo{}
+
+	err := readmodel.NewProjector(mockPg, mockMongo, views).Refresh(context.Background(), oid.Hex())
+	assert.NoError(t, err)
+	assert.True(t, views.Views["ref-1"].DetailMissing)
+	assert.Equal(t, "draft", views.Views["ref-1"].Status)
+}
+
+func TestProjectorRefresh_PurgedReferencesRemoveViews(t *testing.T) {
+	oid := primitive.NewObjectID()
+	views := &MockAchievementViewRepo{Views: map[string]model_mongo.AchievementView{
+		"ref-1":     {ID: "ref-1", MongoAchievementID: oid.Hex()},
+		"ref-other": {ID: "ref-other", MongoAchievementID: "other"},
+	}}
+
+	err := readmodel.NewProjector(&MockAchievementPGRepo{}, &MockAchievementMongoRepo{}, views).Refresh(context.Background(), oid.Hex())
+	assert.NoError(t, err)
+	assert.Len(t, views.Views, 1)
+	assert.Contains(t, views.Views, "ref-other")
+}
+
+func TestProjectorRebuild_ReplacesWholeReadModel(t *testing.T) {
+	active, trashed := primitive.NewObjectID(), primitive.NewObjectID()
+	mockPg := &MockAchievementPGRepo{
+		GetAllReferencesWithDeletedFunc: func() ([]model_postgre.AchievementReference, error) {
+			return []model_postgre.AchievementReference{
+				{ID: "ref-active", MongoAchievementID: active.Hex(), Status: model_postgre.StatusVerified},
+				{ID: "ref-trashed", MongoAchievementID: trashed.Hex(), Status: model_postgre.StatusDeleted},
+			}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailsByIDsFunc: func(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error) {
+			return []model_mongo.AchievementMongo{{ID: active, Title: "Aktif"}}, nil
+		},
+		GetDeletedDetailsByIDsFunc: func(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error) {
+			return []model_mongo.AchievementMongo{{ID: trashed, Title: "Di Trash"}}, nil
+		},
+	}
+	views := &MockAchievementViewRepo{Views: map[string]model_mongo.AchievementView{"ref-orphan": {ID: "ref-orphan"}}}
+
+	count, err := readmodel.NewProjector(mockPg, mockMongo, views).Rebuild(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Len(t, views.Views, 2)
+	assert.Equal(t, "Aktif", views.Views["ref-active"].Title)
+	assert.Equal(t, "Di Trash", views.Views["ref-trashed"].Title)
+}
+
+func TestProjectorRebuildIfEmpty_BuildsOnlyEmptyReadModel(t *testing.T) {
+	oid := primitive.NewObjectID()
+	mockPg := &MockAchievementPGRepo{
+		GetAllReferencesWithDeletedFunc: func() ([]model_postgre.AchievementReference, error) {
+			return []model_postgre.AchievementReference{{ID: "ref-lama", MongoAchievementID: oid.Hex(), Status: model_postgre.StatusVerified}}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		GetDetailsByIDsFunc: func(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error) {
+			return []model_mongo.AchievementMongo{{ID: oid, Title: "Prestasi Lama"}}, nil
+		},
+	}
+	views := &MockAchievementViewRepo{}
+	projector := readmodel.NewProjector(mockPg, mockMongo, views)
+
+	count, err := projector.RebuildIfEmpty(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, "Prestasi Lama", views.Views["ref-lama"].Title)
+
+	// Read model yang sudah terisi tidak dibangun ulang
+	views.Views["ref-lama"] = model_mongo.AchievementView{ID: "ref-lama", Title: "Terproyeksi"}
+	count, err = projector.RebuildIfEmpty(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, "Terproyeksi", views.Views["ref-lama"].Title)
+}
+
+func TestOutboxRefresh_ProjectorFailureKeepsEventPending(t *testing.T) {
+	oid := primitive.NewObjectID()
+	mockPg := &MockAchievementPGRepo{
+		GetReferencesByMongoIDFunc: func(mongoID string) ([]model_postgre.AchievementReference, error) {
+			return []model_postgre.AchievementReference{{ID: "ref-1", MongoAchievementID: mongoID, Status: model_postgre.StatusVerified}}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{}
+	views := &MockAchievementViewRepo{ReplaceErr: errors.New("mongo down")}
+	repo := &MockOutboxRepo{}
+	dispatcher := outbox.NewDispatcher(repo, mockMongo, readmodel.NewProjector(mockPg, mockMongo, views))
+
+	synced, err := dispatcher.Refresh(context.Background(), oid.Hex())
+	assert.Error(t, err)
+	assert.False(t, synced)
+	if assert.Len(t, repo.Events, 1) {
+		assert.Equal(t, model_postgre.OutboxRefreshView, repo.Events[0].Operation)
+		assert.Nil(t, repo.Events[0].ProcessedAt)
+	}
+
+	// Worker mengulang event setelah Mongo pulih
+	views.ReplaceErr = nil
+	repo.Events[0].NextAttemptAt = repo.Events[0].CreatedAt
+	result, err := dispatcher.DispatchDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Processed)
+	assert.NotNil(t, repo.Events[0].ProcessedAt)
+	assert.Equal(t, "verified", views.Views["ref-1"].Status)
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/reconcile"
+	"github.com/safrizal-hk/uas-gofiber/app/service"
+)
+
+// driftFixture: Satu contoh untuk setiap jenis ketidaksesuaian plus data sehat dan data yang sedang diproses
+type driftFixture struct {
+	now                                    time.Time
+	healthy, softDeleted, trashed, orphan  primitive.ObjectID
+	missing, pending, freshOrphan          primitive.ObjectID
+	trashedRefs                            []string
+	deletedDocs, softDeletedDocs, restored []primitive.ObjectID
+}
+
+func newDriftFixture() (*driftFixture, *MockAchievementPGRepo, *MockAchievementMongoRepo, *MockOutboxRepo) {
+	f := &driftFixture{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	old := f.now.Add(-time.Hour)
+	for _, id := range []*primitive.ObjectID{&f.healthy, &f.softDeleted, &f.trashed, &f.orphan, &f.missing, &f.pending, &f.freshOrphan} {
+		*id = primitive.NewObjectID()
+	}
+
+	mockPg := &MockAchievementPGRepo{
+		GetAllReferencesWithDeletedFunc: func() ([]model_postgre.AchievementReference, error) {
+			return []model_postgre.AchievementReference{
+				{ID: "ref-healthy", StudentID: "stu-1", MongoAchievementID: f.healthy.Hex(), Status: "verified", UpdatedAt: old},
+				{ID: "ref-member", StudentID: "stu-2", MongoAchievementID: f.healthy.Hex(), Status: "verified", CoMember: true, UpdatedAt: old},
+				{ID: "ref-restored", StudentID: "stu-1", MongoAchievementID: f.softDeleted.Hex(), Status: "draft", UpdatedAt: old},
+				{ID: "ref-trashed", StudentID: "stu-1", MongoAchievementID: f.trashed.Hex(), Status: "deleted", UpdatedAt: old},
+				{ID: "ref-missing", StudentID: "stu-1", MongoAchievementID: f.missing.Hex(), Status: "submitted", UpdatedAt: old},
+				{ID: "ref-invalid", StudentID: "stu-1", MongoAchievementID: "bukan-object-id", Status: "draft", UpdatedAt: old},
+				{ID: "ref-pending", StudentID: "stu-1", MongoAchievementID: f.pending.Hex(), Status: "draft", UpdatedAt: old},
+			}, nil
+		},
+		TrashReferenceFunc: func(id, actorID, note string) (*model_postgre.AchievementReference, error) {
+			f.trashedRefs = append(f.trashedRefs, id)
+			return &model_postgre.AchievementReference{ID: id, Status: "deleted"}, nil
+		},
+	}
+	mockMongo := &MockAchievementMongoRepo{
+		ListSyncStatesFunc: func(ctx context.Context) ([]model_mongo.AchievementSyncState, error) {
+			return []model_mongo.AchievementSyncState{
+				{ID: f.healthy, CreatedAt: old},
+				{ID: f.softDeleted, CreatedAt: old, DeletedAt: &old},
+				{ID: f.trashed, CreatedAt: old},
+				{ID: f.orphan, StudentID: "stu-9", CreatedAt: old},
+				{ID: f.freshOrphan, CreatedAt: f.now.Add(-time.Minute)},
+			}, nil
+		},
+		DeleteByIDFunc: func(ctx context.Context, id primitive.ObjectID) error {
+			f.deletedDocs = append(f.deletedDocs, id)
+			return nil
+		},
+		SoftDeleteFunc: func(ctx context.Context, id primitive.ObjectID) error {
+			f.softDeletedDocs = append(f.softDeletedDocs, id)
+			return nil
+		},
+		RestoreFunc: func(ctx context.Context, id primitive.ObjectID) error {
+			f.restored = append(f.restored, id)
+			return nil
+		},
+	}
+	outboxRepo := &MockOutboxRepo{}
+	outboxRepo.record(&model_postgre.OutboxEvent{AggregateID: f.pending.Hex(), Operation: model_postgre.OutboxCreateAchievement})
+	return f, mockPg, mockMongo, outboxRepo
+}
+
+func TestReconciler_DryRunReportsDriftWithoutRepairing(t *testing.T) {
+	f, mockPg, mockMongo, outboxRepo := newDriftFixture()
+	reconciler := reconcile.New(mockPg, mockMongo, outboxRepo)
+	reconciler.Now = func() time.Time { return f.now }
+
+	report, err := reconciler.Run(context.Background(), true, "")
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 7, report.ScannedReferences)
+	assert.Equal(t, 5, report.ScannedDocuments)
+	assert.Equal(t, 2, report.Skipped) // Event outbox tertunda & dokumen yang baru dibuat
+	assert.Equal(t, map[string]int{
+		model_postgre.DriftInvalidMongoID:  1,
+		model_postgre.DriftMissingDocument: 1,
+		model_postgre.DriftOrphanDocument:  1,
+		model_postgre.DriftDeletedMismatch: 2,
+	}, report.Summary)
+	assert.Equal(t, 0, report.Repaired)
+	for _, issue := range report.Issues {
+		assert.False(t, issue.Repaired)
+	}
+	assert.Empty(t, f.trashedRefs)
+	assert.Empty(t, f.deletedDocs)
+	assert.Empty(t, f.softDeletedDocs)
+	assert.Empty(t, f.restored)
+}
+
+func TestReconciler_ApplyRepairsEachIssue(t *testing.T) {
+	f, mockPg, mockMongo, outboxRepo := newDriftFixture()
+	reconciler := reconcile.New(mockPg, mockMongo, outboxRepo)
+	reconciler.Now = func() time.Time { return f.now }
+
+	report, err := reconciler.Run(context.Background(), false, "user-admin")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, report.Repaired)
+	assert.Equal(t, 0, report.Failed)
+	assert.ElementsMatch(t, []string{"ref-missing", "ref-invalid"}, f.trashedRefs)
+	assert.Equal(t, []primitive.ObjectID{f.orphan}, f.deletedDocs)
+	assert.Equal(t, []primitive.ObjectID{f.trashed}, f.softDeletedDocs)
+	assert.Equal(t, []primitive.ObjectID{f.softDeleted}, f.restored)
+}
+
+func TestReconciler_ApplyTrashesReferenceWithLongInvalidMongoID(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	bogusID := "legacy-import-" + strings.Repeat("x", 40)
+	outboxRepo := &MockOutboxRepo{}
+	var trashed []string
+	mockPg := &MockAchievementPGRepo{
+		GetAllReferencesWithDeletedFunc: func() ([]model_postgre.AchievementReference, error) {
+			return []model_postgre.AchievementReference{
+				{ID: "ref-bogus", StudentID: "stu-1", MongoAchievementID: bogusID, Status: "verified", UpdatedAt: now.Add(-time.Hour)},
+			}, nil
+		},
+		// Seperti runTransition: event refresh read model dicatat dengan ID Mongo referensi apa adanya
+		TrashReferenceFunc: func(id, actorID, note string) (*model_postgre.AchievementReference, error) {
+			trashed = append(trashed, id)
+			outboxRepo.record(&model_postgre.OutboxEvent{AggregateID: bogusID, Operation: model_postgre.OutboxRefreshView})
+			return &model_postgre.AchievementReference{ID: id, MongoAchievementID: bogusID, Status: "deleted"}, nil
+		},
+	}
+	reconciler := reconcile.New(mockPg, &MockAchievementMongoRepo{}, outboxRepo)
+	reconciler.Now = func() time.Time { return now }
+
+	report, err := reconciler.Run(context.Background(), false, "user-admin")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{model_postgre.DriftInvalidMongoID: 1}, report.Summary)
+	assert.Equal(t, 1, report.Repaired)
+	assert.Equal(t, 0, report.Failed)
+	assert.Equal(t, []string{"ref-bogus"}, trashed)
+	if assert.Len(t, outboxRepo.Events, 1) {
+		assert.Equal(t, bogusID, outboxRepo.Events[0].AggregateID)
+	}
+}
+
+func TestReconcileEndpoint_DefaultsToDryRun(t *testing.T) {
+	f, mockPg, mockMongo, outboxRepo := newDriftFixture()
+	svc := service.NewReconcileService(mockPg, mockMongo, outboxRepo)
+	svc.Reconciler.Now = func() time.Time { return f.now }
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userProfile", model_postgre.UserProfile{ID: "user-admin", Role: "Admin"})
+		return c.Next()
+	})
+	app.Post("/reconcile", svc.ReconcileAchievements)
+
+	resp, _ := app.Test(httptest.NewRequest("POST", "/reconcile", nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var result struct {
+		Data model_postgre.ReconcileReport `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.True(t, result.Data.DryRun)
+	assert.Len(t, result.Data.Issues, 5)
+	assert.Empty(t, f.trashedRefs)
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson"
 
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
 	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
 	"github.com/safrizal-hk/uas-gofiber/app/service"
 )
@@ -20,7 +22,6 @@ import (
 type MockReportPGRepo struct {
 	FindStudentIdByUserIDFunc           func(userID string) (string, error)
 	FindStudentProfileFunc              func(studentID string) (*model_postgre.Student, error)
-	GetStudentAchievementReferencesFunc func(studentID string) ([]model_postgre.AchievementReference, error)
 	FindLecturerIdByUserIDFunc          func(userID string) (string, error)
 	GetAdviseeStudentIDsFunc            func(lecturerID string) ([]string, error)
 	
@@ -37,10 +38,6 @@ func (m *MockReportPGRepo) FindStudentProfile(studentID string) (*model_postgre.
 	if m.FindStudentProfileFunc == nil { return nil, nil }
 	return m.FindStudentProfileFunc(studentID)
 }
-func (m *MockReportPGRepo) GetStudentAchievementReferences(studentID string) ([]model_postgre.AchievementReference, error) {
-	if m.GetStudentAchievementReferencesFunc == nil { return nil, nil }
-	return m.GetStudentAchievementReferencesFunc(studentID)
-}
 func (m *MockReportPGRepo) FindLecturerIdByUserID(userID string) (string, error) {
 	if m.FindLecturerIdByUserIDFunc == nil { return "", nil }
 	return m.FindLecturerIdByUserIDFunc(userID)
@@ -56,17 +53,17 @@ func (m *MockReportPGRepo) GetLecturerStatistics(userID string) (map[string]int,
 
 
 type MockReportMongoRepo struct {
-	GetAchievementStatisticsFunc     func(ctx context.Context, studentIDs []string) ([]bson.M, error)
-	GetStudentAchievementDetailsFunc func(ctx context.Context, studentIDHex string) ([]bson.M, error)
+	GetAchievementStatisticsFunc   func(ctx context.Context, studentIDs []string) ([]bson.M, error)
+	GetStudentAchievementViewsFunc func(ctx context.Context, studentID string) ([]model_mongo.AchievementView, error)
 }
 
 func (m *MockReportMongoRepo) GetAchievementStatistics(ctx context.Context, studentIDs []string) ([]bson.M, error) {
 	if m.GetAchievementStatisticsFunc == nil { return nil, nil }
 	return m.GetAchievementStatisticsFunc(ctx, studentIDs)
 }
-func (m *MockReportMongoRepo) GetStudentAchievementDetails(ctx context.Context, studentIDHex string) ([]bson.M, error) {
-	if m.GetStudentAchievementDetailsFunc == nil { return nil, nil }
-	return m.GetStudentAchievementDetailsFunc(ctx, studentIDHex)
+func (m *MockReportMongoRepo) GetStudentAchievementViews(ctx context.Context, studentID string) ([]model_mongo.AchievementView, error) {
+	if m.GetStudentAchievementViewsFunc == nil { return nil, nil }
+	return m.GetStudentAchievementViewsFunc(ctx, studentID)
 }
 
 // SETUP HELPER
@@ -194,15 +191,15 @@ func TestGetStatistics_ForbiddenRole(t *testing.T) {
 
 func TestGetStudentReport_Admin_Success(t *testing.T) {
 	targetStudentID := "stu-123"
-	mockPg := &MockReportPGRepo{
-		GetStudentAchievementReferencesFunc: func(sid string) ([]model_postgre.AchievementReference, error) {
+	mockPg := &MockReportPGRepo{}
+	mockMongo := &MockReportMongoRepo{
+		GetStudentAchievementViewsFunc: func(ctx context.Context, sid string) ([]model_mongo.AchievementView, error) {
 			if sid == targetStudentID {
-				return []model_postgre.AchievementReference{{ID: "ref-1", Status: "verified"}}, nil
+				return []model_mongo.AchievementView{{ID: "ref-1", Status: "verified", Title: "Juara 1", Points: 75}}, nil
 			}
 			return nil, errors.New("not found")
 		},
 	}
-	mockMongo := &MockReportMongoRepo{}
 	app := setupReportServiceTestApp(mockMongo, mockPg)
 
 	req := httptest.NewRequest("GET", "/reports/student/"+targetStudentID, nil)
@@ -210,6 +207,17 @@ func TestGetStudentReport_Admin_Success(t *testing.T) {
 	resp, _ := app.Test(req)
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var result struct {
+		Total int                      `json:"total_prestasi"`
+		Data  []map[string]interface{} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Equal(t, 1, result.Total)
+	if assert.Len(t, result.Data, 1) {
+		// Konten prestasi ikut dari read model, tanpa query tambahan ke dokumen
+		assert.Equal(t, "Juara 1", result.Data[0]["title"])
+		assert.Equal(t, "verified", result.Data[0]["status"])
+	}
 }
 
 func TestGetStudentReport_Mahasiswa_OwnData(t *testing.T) {
@@ -218,11 +226,12 @@ func TestGetStudentReport_Mahasiswa_OwnData(t *testing.T) {
 
 	mockPg := &MockReportPGRepo{
 		FindStudentIdByUserIDFunc: func(uid string) (string, error) { return myStudentID, nil },
-		GetStudentAchievementReferencesFunc: func(sid string) ([]model_postgre.AchievementReference, error) {
-			return []model_postgre.AchievementReference{{ID: "ref-1"}}, nil
+	}
+	mockMongo := &MockReportMongoRepo{
+		GetStudentAchievementViewsFunc: func(ctx context.Context, sid string) ([]model_mongo.AchievementView, error) {
+			return []model_mongo.AchievementView{{ID: "ref-1"}}, nil
 		},
 	}
-	mockMongo := &MockReportMongoRepo{}
 	app := setupReportServiceTestApp(mockMongo, mockPg)
 
 	req := httptest.NewRequest("GET", "/reports/student/"+myStudentID, nil)
@@ -252,4 +261,22 @@ func TestGetStudentReport_Mahasiswa_OtherData_Forbidden(t *testing.T) {
 	resp, _ := app.Test(req)
 
 	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
-}
\ No newline at end of file
+}
+
+func TestGetStudentReport_DosenWali_NotAdvisee_Forbidden(t *testing.T) {
+	mockPg := &MockReportPGRepo{
+		FindLecturerIdByUserIDFunc: func(uid string) (string, error) { return "lec-1", nil },
+		GetAdviseeStudentIDsFunc: func(lecturerID string) ([]string, error) {
+			return []string{"stu-123"}, nil
+		},
+	}
+	mockMongo := &MockReportMongoRepo{}
+	app := setupReportServiceTestApp(mockMongo, mockPg)
+
+	req := httptest.NewRequest("GET", "/reports/student/stu-999", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-dosen")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
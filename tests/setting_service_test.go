@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/service"
+)
+
+// MOCK REPOSITORY
+type MockSettingRepo struct {
+	Values map[string]string
+}
+
+func (m *MockSettingRepo) ListSettings() ([]model_postgre.SystemSetting, error) {
+	list := []model_postgre.SystemSetting{}
+	for k, v := range m.Values {
+		list = append(list, model_postgre.SystemSetting{Key: k, Value: v})
+	}
+	return list, nil
+}
+func (m *MockSettingRepo) GetSetting(key string) (*model_postgre.SystemSetting, error) {
+	v, ok := m.Values[key]
+	if !ok { return nil, sql.ErrNoRows }
+	return &model_postgre.SystemSetting{Key: key, Value: v}, nil
+}
+func (m *MockSettingRepo) UpsertSetting(key string, value string, actorID string) (*model_postgre.SystemSetting, error) {
+	if m.Values == nil { m.Values = map[string]string{} }
+	m.Values[key] = value
+	return &model_postgre.SystemSetting{Key: key, Value: value, UpdatedBy: &actorID, UpdatedAt: time.Now()}, nil
+}
+func (m *MockSettingRepo) GetIntSetting(key string, fallback int) (int, error) {
+	v, ok := m.Values[key]
+	if !ok { return fallback, nil }
+	n, err := strconv.Atoi(v)
+	if err != nil { return fallback, nil }
+	return n, nil
+}
+
+// SETUP HELPER
+func setupSettingServiceTestApp(mockRepo *MockSettingRepo) *fiber.App {
+	app := fiber.New()
+	svc := service.NewSettingService(mockRepo)
+
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userProfile", model_postgre.UserProfile{ID: "user-admin", Role: "Admin"})
+		return c.Next()
+	})
+	app.Get("/settings", svc.ListSettings)
+	app.Get("/settings/:key", svc.GetSetting)
+	app.Put("/settings/:key", svc.UpdateSetting)
+
+	return app
+}
+
+// UNIT TESTS
+func TestUpdateSetting_TrashRetention_Success(t *testing.T) {
+	mockRepo := &MockSettingRepo{Values: map[string]string{model_postgre.SettingTrashRetentionDays: "30"}}
+	app := setupSettingServiceTestApp(mockRepo)
+
+	body, _ := json.Marshal(model_postgre.SettingUpdateRequest{Value: "7"})
+	req := httptest.NewRequest("PUT", "/settings/trash_retention_days", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "7", mockRepo.Values[model_postgre.SettingTrashRetentionDays])
+}
+
+func TestUpdateSetting_InvalidValue(t *testing.T) {
+	app := setupSettingServiceTestApp(&MockSettingRepo{})
+
+	body, _ := json.Marshal(model_postgre.SettingUpdateRequest{Value: "0"})
+	req := httptest.NewRequest("PUT", "/settings/trash_retention_days", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestUpdateSetting_UnknownKey(t *testing.T) {
+	app := setupSettingServiceTestApp(&MockSettingRepo{})
+
+	body, _ := json.Marshal(model_postgre.SettingUpdateRequest{Value: "10"})
+	req := httptest.NewRequest("PUT", "/settings/unknown_key", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestUpdateSetting_FallbackVerifierMustBeUUID(t *testing.T) {
+	mockRepo := &MockSettingRepo{}
+	app := setupSettingServiceTestApp(mockRepo)
+
+	put := func(value string) int {
+		body, _ := json.Marshal(model_postgre.SettingUpdateRequest{Value: value})
+		req := httptest.NewRequest("PUT", "/settings/sla_fallback_verifier_id", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusBadRequest, put("kaprodi"))
+	assert.Equal(t, http.StatusOK, put("3f8a2c1e-9b7d-4e6f-a5c3-1d2e3f4a5b6c"))
+	assert.Equal(t, "3f8a2c1e-9b7d-4e6f-a5c3-1d2e3f4a5b6c", mockRepo.Values[model_postgre.SettingSLAFallbackVerifierID])
+	// Kosong = eskalasi ke semua Admin
+	assert.Equal(t, http.StatusOK, put(""))
+}
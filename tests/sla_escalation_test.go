@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/safrizal-hk/uas-gofiber/app/job"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/service"
+)
+
+// MOCK REPOSITORY
+type MockEscalationRepo struct {
+	GetOverdueReferencesFunc func(submittedBefore time.Time, lecturerID string) ([]model_postgre.OverdueAchievement, error)
+	EscalateFunc             func(refID, fallbackUserID string, slaDays int, submittedBefore time.Time) (*model_postgre.AchievementEscalation, error)
+}
+
+func (m *MockEscalationRepo) GetOverdueReferences(submittedBefore time.Time, lecturerID string) ([]model_postgre.OverdueAchievement, error) {
+	if m.GetOverdueReferencesFunc == nil { return nil, nil }
+	return m.GetOverdueReferencesFunc(submittedBefore, lecturerID)
+}
+func (m *MockEscalationRepo) Escalate(refID, fallbackUserID string, slaDays int, submittedBefore time.Time) (*model_postgre.AchievementEscalation, error) {
+	if m.EscalateFunc == nil { return nil, sql.ErrNoRows }
+	return m.EscalateFunc(refID, fallbackUserID, slaDays, submittedBefore)
+}
+
+func TestSLAEscalator_EscalatesOverdueOncePerStage(t *testing.T) {
+	now := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	submitted := now.AddDate(0, 0, -10)
+	escalatedEarlier := submitted.AddDate(0, 0, -30) // Eskalasi siklus submit sebelumnya
+	escalatedNow := submitted.AddDate(0, 0, 7)
+	// Tahap pertama dieskalasi lalu disetujui; tahap kedua tertahan lagi melewati SLA
+	firstStageSubmitted := now.AddDate(0, 0, -30)
+	firstStageEscalated := now.AddDate(0, 0, -22)
+	secondStageEntered := now.AddDate(0, 0, -8)
+
+	var gotCutoff time.Time
+	mockRepo := &MockEscalationRepo{
+		GetOverdueReferencesFunc: func(submittedBefore time.Time, lecturerID string) ([]model_postgre.OverdueAchievement, error) {
+			gotCutoff = submittedBefore
+			return []model_postgre.OverdueAchievement{
+				{ID: "ref-baru", SubmittedAt: submitted, StageEnteredAt: submitted},
+				{ID: "ref-submit-ulang", SubmittedAt: submitted, StageEnteredAt: submitted, EscalatedAt: &escalatedEarlier},
+				{ID: "ref-sudah", SubmittedAt: submitted, StageEnteredAt: submitted, EscalatedAt: &escalatedNow},
+				{ID: "ref-balapan", SubmittedAt: submitted, StageEnteredAt: submitted},
+				{ID: "ref-tahap-2", SubmittedAt: firstStageSubmitted, StageEnteredAt: secondStageEntered, EscalatedAt: &firstStageEscalated},
+			}, nil
+		},
+	}
+	var escalated []string
+	var gotFallback string
+	mockRepo.EscalateFunc = func(refID, fallbackUserID string, slaDays int, submittedBefore time.Time) (*model_postgre.AchievementEscalation, error) {
+		if refID == "ref-balapan" { return nil, sql.ErrNoRows }
+		escalated = append(escalated, refID)
+		gotFallback = fallbackUserID
+		return &model_postgre.AchievementEscalation{AchievementRefID: refID, SLADays: slaDays}, nil
+	}
+	settings := &MockSettingRepo{Values: map[string]string{
+		model_postgre.SettingVerificationSLADays:   "7",
+		model_postgre.SettingSLAFallbackVerifierID: " user-kaprodi ",
+	}}
+
+	escalator := job.NewSLAEscalator(mockRepo, settings)
+	escalator.Now = func() time.Time { return now }
+	result, err := escalator.RunOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -7), gotCutoff)
+	assert.Equal(t, []string{"ref-baru", "ref-submit-ulang", "ref-tahap-2"}, escalated)
+	assert.Equal(t, "user-kaprodi", gotFallback)
+	assert.Equal(t, job.EscalationResult{SLADays: 7, Overdue: 5, Escalated: 3, Failed: 0}, result)
+}
+
+func setupEscalationServiceTestApp(mockRepo *MockEscalationRepo, mockPg *MockAchievementPGRepo, now time.Time) *fiber.App {
+	app := fiber.New()
+	svc := service.NewEscalationService(mockRepo, mockPg, &MockSettingRepo{Values: map[string]string{model_postgre.SettingVerificationSLADays: "14"}})
+	svc.Now = func() time.Time { return now }
+
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userProfile", model_postgre.UserProfile{ID: c.Get("X-Test-ID"), Role: c.Get("X-Test-Role")})
+		return c.Next()
+	})
+	app.Get("/achievements/overdue", svc.ListOverdue)
+	return app
+}
+
+func TestListOverdue_GroupsByAdvisor(t *testing.T) {
+	now := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	lecA, lecB := "lec-a", "lec-b"
+	var gotLecturer string
+	mockRepo := &MockEscalationRepo{
+		GetOverdueReferencesFunc: func(submittedBefore time.Time, lecturerID string) ([]model_postgre.OverdueAchievement, error) {
+			gotLecturer = lecturerID
+			return []model_postgre.OverdueAchievement{
+				// Keterlambatan dihitung sejak masuk tahap aktif, bukan sejak submit
+				{ID: "ref-1", AdvisorID: &lecA, SubmittedAt: now.AddDate(0, 0, -45), StageEnteredAt: now.AddDate(0, 0, -30)},
+				{ID: "ref-2", AdvisorID: &lecB, SubmittedAt: now.AddDate(0, 0, -20), StageEnteredAt: now.AddDate(0, 0, -20)},
+				{ID: "ref-3", AdvisorID: &lecB, SubmittedAt: now.AddDate(0, 0, -15), StageEnteredAt: now.AddDate(0, 0, -15)},
+			}, nil
+		},
+	}
+	app := setupEscalationServiceTestApp(mockRepo, &MockAchievementPGRepo{}, now)
+
+	req := httptest.NewRequest("GET", "/achievements/overdue?advisor_id=lec-x", nil)
+	req.Header.Set("X-Test-Role", "Admin")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "lec-x", gotLecturer)
+
+	var result struct {
+		Data struct {
+			Total    int                            `json:"total"`
+			Advisors []model_postgre.AdvisorOverdue `json:"advisors"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	assert.Equal(t, 3, result.Data.Total)
+	if assert.Len(t, result.Data.Advisors, 2) {
+		assert.Equal(t, lecB, *result.Data.Advisors[0].AdvisorID)
+		assert.Equal(t, 2, result.Data.Advisors[0].Total)
+		assert.Equal(t, 6, result.Data.Advisors[0].Items[0].OverdueDays)
+		assert.Equal(t, 16, result.Data.Advisors[1].Items[0].OverdueDays)
+	}
+}
+
+func TestListOverdue_ScopedToLecturer(t *testing.T) {
+	var gotLecturer string
+	mockRepo := &MockEscalationRepo{
+		GetOverdueReferencesFunc: func(submittedBefore time.Time, lecturerID string) ([]model_postgre.OverdueAchievement, error) {
+			gotLecturer = lecturerID
+			return nil, nil
+		},
+	}
+	mockPg := &MockAchievementPGRepo{
+		FindLecturerIdByUserIDFunc: func(userID string) (string, error) { return "lec-a", nil },
+		FindStudentIdByUserIDFunc:  func(userID string) (string, error) { return "stu-1", nil },
+	}
+	app := setupEscalationServiceTestApp(mockRepo, mockPg, time.Now())
+
+	// Dosen Wali tidak bisa melihat bimbingan dosen lain lewat advisor_id
+	req := httptest.NewRequest("GET", "/achievements/overdue?advisor_id=lec-b", nil)
+	req.Header.Set("X-Test-Role", "Dosen Wali")
+	req.Header.Set("X-Test-ID", "user-dosen")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "lec-a", gotLecturer)
+
+	req = httptest.NewRequest("GET", "/achievements/overdue", nil)
+	req.Header.Set("X-Test-Role", "Mahasiswa")
+	req.Header.Set("X-Test-ID", "user-mhs")
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -45,17 +46,34 @@ func (m *MockAchievementPGRepository) GetAchievementsByStudentIDs(ids []string)
 	return m.GetAchievementsByStudentIDsFunc(ids)
 }
 
-func (m *MockAchievementPGRepository) CreateReference(ref *model_postgre.AchievementReference) (*model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) CreateReference(ref *model_postgre.AchievementReference, actorID string, event *model_postgre.OutboxEvent) (*model_postgre.AchievementReference, error) { return nil, nil }
 func (m *MockAchievementPGRepository) GetAllAchievementReferences() ([]model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) GetAllReferencesWithDeleted() ([]model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) TrashReference(id, actorID, note string) (*model_postgre.AchievementReference, error) { return nil, nil }
 func (m *MockAchievementPGRepository) GetReferenceByID(id string) (*model_postgre.AchievementReference, error) { return nil, nil }
-func (m *MockAchievementPGRepository) SoftDeleteReference(id, studentID string) (*model_postgre.AchievementReference, error) { return nil, nil }
-func (m *MockAchievementPGRepository) UpdateStatusToSubmitted(id string) (*model_postgre.AchievementReference, error) { return nil, nil }
-func (m *MockAchievementPGRepository) VerifyAchievement(id, lecturerID string) (*model_postgre.AchievementReference, error) { return nil, nil }
-func (m *MockAchievementPGRepository) RejectAchievement(id, lecturerID, note string) (*model_postgre.AchievementReference, error) { return nil, nil }
-func (m *MockAchievementPGRepository) GetMyAchievements(studentID string) ([]model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) GetReferencesByIDs(ids []string) ([]model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) SoftDeleteReference(id, studentID, actorID string) (*model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) UpdateStatusToSubmitted(id, actorID, workflowCode, firstStage string) (*model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) AdvanceStage(id, actorID, onBehalfOf, fromStage, toStage, note string) (*model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) VerifyAchievement(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) RejectAchievement(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) RequestRevision(id, lecturerID, onBehalfOf, stage, note string) (*model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) CreateTeamReferences(owner *model_postgre.AchievementReference, members []model_postgre.AchievementReference, actorID string, event *model_postgre.OutboxEvent) (*model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) GetTeamReferences(mongoID string) ([]model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) ConfirmParticipation(id, studentID string) (*model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) FindExistingStudentIDs(ids []string) ([]string, error) { return ids, nil }
+func (m *MockAchievementPGRepository) WithdrawSubmission(id, studentID, actorID string) (*model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) OverrideStatus(id string, to model_postgre.AchievementStatus, actorID, reason, workflowCode, firstStage string, allowFromVerified bool) (*model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) RestoreReference(id, studentID, actorID string) (*model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) GetDeletedReferences(studentID string) ([]model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) GetPurgeableReferences(deletedBefore time.Time) ([]model_postgre.AchievementReference, error) { return nil, nil }
+func (m *MockAchievementPGRepository) PurgeReferences(mongoID string) (int64, error) { return 0, nil }
+func (m *MockAchievementPGRepository) GetStatusHistory(id string) ([]model_postgre.AchievementStatusHistory, error) { return nil, nil }
+func (m *MockAchievementPGRepository) GetReferencesByMongoID(mongoID string) ([]model_postgre.AchievementReference, error) { return nil, nil }
 func (m *MockAchievementPGRepository) FindStudentIdByUserID(userID string) (string, error) { return "", nil }
 func (m *MockAchievementPGRepository) FindLecturerIdByUserID(userID string) (string, error) { return "", nil }
 func (m *MockAchievementPGRepository) GetAdviseeStudentIDs(lecturerID string) ([]string, error) { return nil, nil }
+func (m *MockAchievementPGRepository) GetDelegatedAdvisees(lecturerID string) ([]model_postgre.DelegatedAdvisee, error) { return nil, nil }
 func (m *MockAchievementPGRepository) GetAchievementsByStudentIDsAndStatus(studentIDs []string, status string) ([]model_postgre.AchievementReference, error) { return nil, nil }
 
 
@@ -65,6 +83,12 @@ func setupStudentServiceTestApp(mockStudentRepo *MockStudentRepository, mockAchR
 
 	svc := service.NewStudentService(mockStudentRepo, mockAchRepo)
 
+	// Endpoint /students dilindungi RBAC user:manage, jadi profil uji adalah Admin
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("userProfile", model_postgre.UserProfile{ID: "user-admin", Role: "Admin"})
+		return c.Next()
+	})
+
 	app.Get("/students", svc.ListStudents)
 	app.Get("/students/:id", svc.GetStudentDetail)
 	app.Put("/students/:id/advisor", svc.SetAdvisor)
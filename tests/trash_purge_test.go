@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/safrizal-hk/uas-gofiber/app/job"
+	model_mongo "github.com/safrizal-hk/uas-gofiber/app/model/mongo"
+	model_postgre "github.com/safrizal-hk/uas-gofiber/app/model/postgre"
+	"github.com/safrizal-hk/uas-gofiber/app/storage"
+)
+
+func TestTrashPurger_RemovesExpiredAchievement(t *testing.T) {
+	uploadDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(uploadDir, "1700000000-sertifikat.pdf"), []byte("pdf"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(uploadDir, "1700000001-foto.jpg"), []byte("jpg"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(uploadDir, "1700000002-milik-orang-lain.pdf"), []byte("pdf"), 0644))
+
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	mongoID := primitive.NewObjectID()
+	var gotCutoff time.Time
+	var purgedMongoID string
+	mockPg := &MockAchievementPGRepo{
+		GetPurgeableReferencesFunc: func(deletedBefore time.Time) ([]model_postgre.AchievementReference, error) {
+			gotCutoff = deletedBefore
+			return []model_postgre.AchievementReference{{ID: "ref-1", Status: "deleted", MongoAchievementID: mongoID.Hex()}}, nil
+		},
+		PurgeReferencesFunc: func(id string) (int64, error) { purgedMongoID = id; return 1, nil },
+	}
+	var deletedDoc primitive.ObjectID
+	mockMongo := &MockAchievementMongoRepo{
+		GetDeletedDetailsByIDsFunc: func(ctx context.Context, ids []primitive.ObjectID) ([]model_mongo.AchievementMongo, error) {
+			return []model_mongo.AchievementMongo{{ID: ids[0], Attachments: []model_mongo.Attachment{
+				{FileUrl: "http://localhost:3000/uploads/1700000000-sertifikat.pdf", StorageKey: "1700000000-sertifikat.pdf"},
+				{FileUrl: "http://localhost:3000/uploads/sudah-tidak-ada.pdf", StorageKey: "sudah-tidak-ada.pdf"},
+				// FileUrl tanpa storage key yang ditulis server tidak pernah dihapus
+				{FileUrl: "http://localhost:3000/uploads/1700000002-milik-orang-lain.pdf"},
+			}}}, nil
+		},
+		DeleteByIDFunc: func(ctx context.Context, id primitive.ObjectID) error { deletedDoc = id; return nil },
+	}
+	revisions := &MockRevisionRepo{Revisions: []model_mongo.AchievementRevision{{AchievementID: mongoID, Version: 1}}}
+	comments := &MockCommentRepo{Comments: []model_mongo.AchievementComment{{AchievementID: mongoID, Attachments: []model_mongo.Attachment{
+		{FileUrl: "http://localhost:3000/uploads/1700000001-foto.jpg", StorageKey: "1700000001-foto.jpg"},
+	}}}}
+	settings := &MockSettingRepo{Values: map[string]string{model_postgre.SettingTrashRetentionDays: "14"}}
+
+	purger := job.NewTrashPurger(mockPg, mockMongo, revisions, comments, settings, storage.NewLocal(uploadDir, "/uploads"))
+	purger.Now = func() time.Time { return now }
+	result, err := purger.RunOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -14), gotCutoff)
+	assert.Equal(t, 1, result.Purged)
+	assert.Equal(t, 2, result.FilesRemoved)
+	assert.Equal(t, mongoID, deletedDoc)
+	assert.Equal(t, mongoID.Hex(), purgedMongoID)
+	assert.Empty(t, revisions.Revisions)
+	assert.Empty(t, comments.Comments)
+	_, statErr := os.Stat(filepath.Join(uploadDir, "1700000000-sertifikat.pdf"))
+	assert.True(t, os.IsNotExist(statErr))
+	_, statErr = os.Stat(filepath.Join(uploadDir, "1700000002-milik-orang-lain.pdf"))
+	assert.NoError(t, statErr)
+}
+
+func TestRunEvery_RepeatsUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	done := make(chan struct{})
+	go func() {
+		job.RunEvery(ctx, time.Millisecond, func(ctx context.Context) error {
+			runs++
+			if runs == 3 { cancel() }
+			// Error hanya dicatat, putaran berikutnya tetap berjalan
+			if runs == 1 { return assert.AnError }
+			return nil
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RunEvery tidak berhenti setelah context dibatalkan")
+	}
+	assert.Equal(t, 3, runs)
+}
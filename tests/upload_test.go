@@ -0,0 +1,36 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/safrizal-hk/uas-gofiber/app/upload"
+)
+
+func TestUploadSafeName(t *testing.T) {
+	cases := map[string]string{
+		"Sertifikat Juara 1.PDF":        "sertifikat-juara-1.pdf",
+		"../../etc/passwd.png":          "passwd.png",
+		`C:\Users\budi\foto lomba.jpeg`: "foto-lomba.jpeg",
+		"...pdf":                        "file.pdf",
+		"piagam\x00\r\n(final)!!.jpg":   "piagam-final.jpg",
+		"Лауреат.pdf":                   "file.pdf",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, upload.SafeName(in), in)
+	}
+	// Nama dasar dipotong, ekstensi dipertahankan
+	assert.Equal(t, strings.Repeat("b", 80)+".pdf", upload.SafeName(strings.Repeat("b", 200)+".pdf"))
+}
+
+func TestUploadDisplayName_StripsPathAndControlChars(t *testing.T) {
+	assert.Equal(t, "Sertifikat Juara.pdf", upload.DisplayName("/tmp/../Sertifikat\x00 Juara.pdf"))
+	assert.Equal(t, "foto.jpg", upload.DisplayName(`C:\fakepath\foto.jpg`))
+}
+
+func TestUploadValidator_MaxSize(t *testing.T) {
+	validator := upload.NewValidator(upload.DefaultRules())
+	assert.Equal(t, int64(10<<20), validator.MaxSize())
+}